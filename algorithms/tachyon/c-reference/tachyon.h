@@ -48,6 +48,10 @@ int tachyon_hash_keyed(const uint8_t *input, size_t len, const uint8_t *key /*[3
 int tachyon_verify_mac(const uint8_t *input, size_t len, const uint8_t *key /*[32]*/, const uint8_t *mac /*[32]*/);
 int tachyon_derive_key(const char *context, size_t context_len,
                        const uint8_t *material /*[32]*/, uint8_t *out /*[32]*/);
+/* Hashes count whole chunks of input into count Merkle leaves, for callers
+ * building the tree themselves (e.g. over chunks hashed on several threads). */
+int tachyon_hash_leaves(const uint8_t *input, size_t count, uint64_t seed,
+                        const uint8_t *key /*[32] or NULL*/, uint8_t *out /*[32 * count]*/);
 /* Returns a static, null-terminated string — do NOT free. */
 const char* tachyon_get_backend_name(void);
 
@@ -62,6 +66,8 @@ tachyon_state_t* tachyon_hasher_new_seeded(uint64_t seed);
 tachyon_state_t* tachyon_hasher_new_full(uint64_t domain, uint64_t seed, const uint8_t *key /*[32] or NULL*/);
 void tachyon_hasher_update(tachyon_state_t *state, const uint8_t *data, size_t len);
 void tachyon_hasher_finalize(tachyon_state_t *state, uint8_t *out /*[32]*/); /* frees state */
+int tachyon_hasher_finalize_reset(tachyon_state_t *state, uint8_t *out /*[32]*/); /* keeps state */
+tachyon_state_t* tachyon_hasher_clone(const tachyon_state_t *state);
 void tachyon_hasher_free(tachyon_state_t *state);                             /* frees without output */
 
 #ifdef __cplusplus
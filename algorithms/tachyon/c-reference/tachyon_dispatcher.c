@@ -16,6 +16,12 @@
 #include <cpuid.h>
 #endif
 #endif
+#if (defined(__aarch64__) || defined(_M_ARM64)) && defined(__linux__) && defined(__has_include)
+#if __has_include(<sys/auxv.h>)
+#include <sys/auxv.h>
+#define TACHYON_HAVE_AUXV 1
+#endif
+#endif
 
 // =============================================================================
 // CONSTANTS
@@ -34,11 +40,26 @@
 #define CPUID_VAES_BIT       9
 #define CPUID_VPCLMUL_BIT   10
 
+// AT_HWCAP bits for the ARMv8 Cryptography Extensions (asm/hwcap.h)
+#ifndef HWCAP_AES
+#define HWCAP_AES   (1 << 3)
+#endif
+#ifndef HWCAP_PMULL
+#define HWCAP_PMULL (1 << 4)
+#endif
+
+// The NEON kernel is built on ARM64, or on x86 with TACHYON_NEON_EMULATE
+#if defined(__aarch64__) || defined(_M_ARM64) || \
+    (defined(TACHYON_NEON_EMULATE) && (defined(__x86_64__) || defined(__i386__)))
+#define TACHYON_HAVE_NEON_KERNEL 1
+#endif
+
 typedef enum {
     CPU_UNKNOWN = 0,
     CPU_PORTABLE = 1,
     CPU_AESNI = 2,
-    CPU_AVX512 = 3
+    CPU_AVX512 = 3,
+    CPU_NEON = 4
 } cpu_feature_t;
 
 // =============================================================================
@@ -52,6 +73,8 @@ static void detect_cpu() {
     g_cpu_feature = CPU_PORTABLE;
 #elif defined(FORCE_AESNI) && (defined(__x86_64__) || defined(__i386__) || defined(_M_X64) || defined(_M_IX86))
     g_cpu_feature = CPU_AESNI;
+#elif defined(FORCE_NEON) && defined(TACHYON_HAVE_NEON_KERNEL)
+    g_cpu_feature = CPU_NEON;
 #elif defined(__x86_64__) || defined(__i386__) || defined(_M_X64) || defined(_M_IX86)
     unsigned int eax = 0, ebx = 0, ecx = 0, edx = 0;
 
@@ -104,6 +127,19 @@ static void detect_cpu() {
             }
         }
     }
+#elif defined(__aarch64__) || defined(_M_ARM64)
+    /* AES and PMULL are optional in ARMv8.0: ask the kernel where we can,
+     * otherwise trust the compile-time target (every Apple Silicon core
+     * has them). */
+#if defined(TACHYON_HAVE_AUXV)
+    unsigned long hwcap = getauxval(AT_HWCAP);
+    int has_crypto = (hwcap & HWCAP_AES) && (hwcap & HWCAP_PMULL);
+#elif defined(__APPLE__) || defined(__ARM_FEATURE_CRYPTO) || defined(__ARM_FEATURE_AES)
+    int has_crypto = 1;
+#else
+    int has_crypto = 0;
+#endif
+    g_cpu_feature = has_crypto ? CPU_NEON : CPU_PORTABLE;
 #else
     g_cpu_feature = CPU_PORTABLE;
 #endif
@@ -115,6 +151,9 @@ const char* tachyon_get_backend_name(void) {
 #if !defined(FORCE_PORTABLE) && (defined(__x86_64__) || defined(__i386__) || defined(_M_X64) || defined(_M_IX86))
         case CPU_AVX512: return "AVX-512 (Truck)";
         case CPU_AESNI:  return "AES-NI (Scooter)";
+#endif
+#if !defined(FORCE_PORTABLE) && defined(TACHYON_HAVE_NEON_KERNEL)
+        case CPU_NEON:   return "NEON (ARMv8 Crypto)";
 #endif
         default:         return "Portable";
     }
@@ -127,6 +166,9 @@ static tachyon_oneshot_fn get_kernel() {
 #if !defined(FORCE_PORTABLE) && (defined(__x86_64__) || defined(__i386__) || defined(_M_X64) || defined(_M_IX86))
     if (g_cpu_feature == CPU_AVX512) return tachyon_avx512_oneshot;
     if (g_cpu_feature == CPU_AESNI)  return tachyon_aesni_oneshot;
+#endif
+#if !defined(FORCE_PORTABLE) && defined(TACHYON_HAVE_NEON_KERNEL)
+    if (g_cpu_feature == CPU_NEON)   return tachyon_neon_oneshot;
 #endif
     return tachyon_portable_oneshot;
 }
@@ -152,27 +194,81 @@ typedef struct {
     uint64_t stack_usage;
 } tachyon_internal_state_t;
 
-/* Merkle tree stack push using bitfield-based sparse representation. */
-static void stack_push(tachyon_internal_state_t *s, const uint8_t *hash) {
+/* Merkle tree stack push using bitfield-based sparse representation: bit i
+ * of usage set means stack[i] holds the root of 2^i chunks. */
+static void tree_push(uint8_t stack[][HASH_SIZE], uint64_t *usage, uint64_t seed,
+                      const uint8_t *key, const uint8_t *hash) {
     uint8_t current_hash[HASH_SIZE];
     memcpy(current_hash, hash, HASH_SIZE);
 
     for (int level = 0; level < MAX_TREE_LEVELS; level++) {
-        if (s->stack_usage & (1ULL << level)) {
+        if (*usage & (1ULL << level)) {
             uint8_t buffer[HASH_SIZE * 2];
-            memcpy(buffer,             &s->stack[level], HASH_SIZE);
-            memcpy(buffer + HASH_SIZE, current_hash,     HASH_SIZE);
-            compute_kernel(buffer, HASH_SIZE * 2, DOMAIN_NODE, s->seed,
-                           s->has_key ? s->key : NULL, current_hash);
-            s->stack_usage &= ~(1ULL << level);
+            memcpy(buffer,             stack[level], HASH_SIZE);
+            memcpy(buffer + HASH_SIZE, current_hash, HASH_SIZE);
+            compute_kernel(buffer, HASH_SIZE * 2, DOMAIN_NODE, seed, key, current_hash);
+            *usage &= ~(1ULL << level);
         } else {
-            memcpy(&s->stack[level], current_hash, HASH_SIZE);
-            s->stack_usage |= (1ULL << level);
+            memcpy(stack[level], current_hash, HASH_SIZE);
+            *usage |= (1ULL << level);
             return;
         }
     }
 }
 
+/* Collapse the stack into the root, then commit domain and length. */
+static void tree_finish(uint8_t stack[][HASH_SIZE], uint64_t usage, uint64_t domain,
+                        uint64_t total_len, uint64_t seed, const uint8_t *key, uint8_t *out) {
+    uint8_t root[HASH_SIZE];
+    int first = 1;
+
+    for (int i = 0; i < MAX_TREE_LEVELS; i++) {
+        if (usage & (1ULL << i)) {
+            if (first) {
+                memcpy(root, stack[i], HASH_SIZE);
+                first = 0;
+            } else {
+                uint8_t buffer[HASH_SIZE * 2];
+                memcpy(buffer,             stack[i], HASH_SIZE);
+                memcpy(buffer + HASH_SIZE, root,     HASH_SIZE);
+                compute_kernel(buffer, HASH_SIZE * 2, DOMAIN_NODE, seed, key, root);
+            }
+        }
+    }
+
+    /* Length commitment: prevents length extension attacks. */
+    uint8_t final_buf[HASH_SIZE + sizeof(uint64_t) * 2];
+    memcpy(final_buf,                 root,       HASH_SIZE);
+    memcpy(final_buf + HASH_SIZE,     &domain,    sizeof(uint64_t));
+    memcpy(final_buf + HASH_SIZE + 8, &total_len, sizeof(uint64_t));
+    compute_kernel(final_buf, sizeof(final_buf), 0, seed, key, out);
+}
+
+/* One-shot tree path: leaves are hashed in place from the input and the
+ * stack lives on the caller's stack, so concurrent calls share no heap
+ * memory and copy nothing. Equivalent to update_state + finalize_state. */
+static void hash_tree(const uint8_t *input, size_t len, uint64_t domain,
+                      uint64_t seed, const uint8_t *key, uint8_t *out) {
+    uint8_t stack[MAX_TREE_LEVELS][HASH_SIZE];
+    uint64_t usage = 0;
+    uint8_t chunk_hash[HASH_SIZE];
+    size_t offset = 0;
+
+    for (; len - offset >= CHUNK_SIZE; offset += CHUNK_SIZE) {
+        compute_kernel(input + offset, CHUNK_SIZE, DOMAIN_LEAF, seed, key, chunk_hash);
+        tree_push(stack, &usage, seed, key, chunk_hash);
+    }
+    if (offset < len) {
+        compute_kernel(input + offset, len - offset, DOMAIN_LEAF, seed, key, chunk_hash);
+        tree_push(stack, &usage, seed, key, chunk_hash);
+    }
+    tree_finish(stack, usage, domain, (uint64_t)len, seed, key, out);
+}
+
+static void stack_push(tachyon_internal_state_t *s, const uint8_t *hash) {
+    tree_push(s->stack, &s->stack_usage, s->seed, s->has_key ? s->key : NULL, hash);
+}
+
 static tachyon_internal_state_t* new_state(uint64_t domain, uint64_t seed, const uint8_t *key) {
     tachyon_internal_state_t *s = (tachyon_internal_state_t*)malloc(sizeof(tachyon_internal_state_t));
     if (!s) return NULL;
@@ -210,12 +306,12 @@ static void update_state(tachyon_internal_state_t *state, const uint8_t *data, s
     }
 }
 
-/* Fast path: inputs < CHUNK_SIZE bypass tree. Tree path: collapse stack and commit length. */
-static void finalize_state(tachyon_internal_state_t *state, uint8_t *out) {
+/* Fast path: inputs < CHUNK_SIZE bypass tree. Tree path: collapse stack and commit length.
+ * Consumes the tree stack in place; the state must be freed or reset afterwards. */
+static void state_root(tachyon_internal_state_t *state, uint8_t *out) {
     if (state->stack_usage == 0 && state->buffer_len < CHUNK_SIZE) {
         compute_kernel(state->buffer, state->buffer_len, state->domain, state->seed,
                        state->has_key ? state->key : NULL, out);
-        free(state);
         return;
     }
 
@@ -226,31 +322,12 @@ static void finalize_state(tachyon_internal_state_t *state, uint8_t *out) {
         stack_push(state, chunk_hash);
     }
 
-    uint8_t root[HASH_SIZE];
-    int first = 1;
-
-    for (int i = 0; i < MAX_TREE_LEVELS; i++) {
-        if (state->stack_usage & (1ULL << i)) {
-            if (first) {
-                memcpy(root, &state->stack[i], HASH_SIZE);
-                first = 0;
-            } else {
-                uint8_t buffer[HASH_SIZE * 2];
-                memcpy(buffer,             &state->stack[i], HASH_SIZE);
-                memcpy(buffer + HASH_SIZE, root,             HASH_SIZE);
-                compute_kernel(buffer, HASH_SIZE * 2, DOMAIN_NODE, state->seed,
-                               state->has_key ? state->key : NULL, root);
-            }
-        }
-    }
+    tree_finish(state->stack, state->stack_usage, state->domain, state->total_len,
+                state->seed, state->has_key ? state->key : NULL, out);
+}
 
-    /* Length commitment: prevents length extension attacks. */
-    uint8_t final_buf[HASH_SIZE + sizeof(uint64_t) * 2];
-    memcpy(final_buf,                   root,              HASH_SIZE);
-    memcpy(final_buf + HASH_SIZE,       &state->domain,    sizeof(uint64_t));
-    memcpy(final_buf + HASH_SIZE + 8,   &state->total_len, sizeof(uint64_t));
-    compute_kernel(final_buf, sizeof(final_buf), 0, state->seed,
-                   state->has_key ? state->key : NULL, out);
+static void finalize_state(tachyon_internal_state_t *state, uint8_t *out) {
+    state_root(state, out);
     free(state);
 }
 
@@ -267,12 +344,7 @@ int tachyon_hash_full(const uint8_t *input, size_t len, uint64_t domain,
     if (len < CHUNK_SIZE) {
         compute_kernel(input, len, domain, seed, key, out);
     } else {
-        tachyon_internal_state_t *s = new_state(domain, seed, key);
-        if (!s) {
-            return TACHYON_ERROR_NULL_PTR;
-        }
-        update_state(s, input, len);
-        finalize_state(s, out);
+        hash_tree(input, len, domain, seed, key, out);
     }
     return 0;
 }
@@ -293,6 +365,18 @@ int tachyon_hash_keyed(const uint8_t *input, size_t len, const uint8_t *key, uin
     return tachyon_hash_full(input, len, TACHYON_DOMAIN_MESSAGE_AUTH, 0, key, out);
 }
 
+int tachyon_hash_leaves(const uint8_t *input, size_t count, uint64_t seed,
+                        const uint8_t *key, uint8_t *out) {
+    if ((!input || !out) && count > 0) {
+        return TACHYON_ERROR_NULL_PTR;
+    }
+    for (size_t i = 0; i < count; i++) {
+        compute_kernel(input + i * CHUNK_SIZE, CHUNK_SIZE, DOMAIN_LEAF, seed, key,
+                       out + i * HASH_SIZE);
+    }
+    return 0;
+}
+
 // =============================================================================
 // STREAMING API
 // =============================================================================
@@ -321,6 +405,27 @@ void tachyon_hasher_finalize(tachyon_state_t *state, uint8_t *out) {
     finalize_state((tachyon_internal_state_t*)state, out);
 }
 
+/* Finalize in place, then reset (keeping domain, seed and key). Allocates nothing. */
+int tachyon_hasher_finalize_reset(tachyon_state_t *state, uint8_t *out) {
+    tachyon_internal_state_t *s = (tachyon_internal_state_t*)state;
+    if (!s || !out) {
+        return TACHYON_ERROR_NULL_PTR;
+    }
+    state_root(s, out);
+    s->buffer_len  = 0;
+    s->total_len   = 0;
+    s->stack_usage = 0;
+    return 0;
+}
+
+tachyon_state_t* tachyon_hasher_clone(const tachyon_state_t *state) {
+    if (!state) return NULL;
+    tachyon_internal_state_t *copy = (tachyon_internal_state_t*)malloc(sizeof(tachyon_internal_state_t));
+    if (!copy) return NULL;
+    memcpy(copy, state, sizeof(*copy));
+    return (tachyon_state_t*)copy;
+}
+
 void tachyon_hasher_free(tachyon_state_t *state) {
     if (state) {
         free(state);
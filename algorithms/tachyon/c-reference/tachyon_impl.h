@@ -217,11 +217,12 @@ static const uint64_t RK_CHAIN[10][2] = {
 // BACKEND KERNEL PROTOTYPES
 // =============================================================================
 //
-// Three hardware-specific implementations selected at runtime by the
-// dispatcher in tachyon_dispatcher.c via CPUID.
+// Hardware-specific implementations selected at runtime by the dispatcher
+// in tachyon_dispatcher.c (CPUID on x86, AT_HWCAP on ARM64).
 
 void tachyon_avx512_oneshot (const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out);
 void tachyon_aesni_oneshot  (const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out);
+void tachyon_neon_oneshot   (const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out);
 void tachyon_portable_oneshot(const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out);
 
 #endif // TACHYON_IMPL_H
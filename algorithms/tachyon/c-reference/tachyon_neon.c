@@ -0,0 +1,665 @@
+// Tachyon
+// Copyright (c) byt3forg3
+// Licensed under the MIT or Apache 2.0 License
+// -------------------------------------------------------------------------
+
+#include <stdint.h>
+#include <string.h>
+#include "tachyon_impl.h"
+
+// =============================================================================
+// ARMv8 CRYPTO (NEON) KERNEL
+// =============================================================================
+//
+// A direct port of the AES-NI kernel to the ARMv8 Cryptography Extensions
+// (AESE/AESMC + PMULL), as found on Apple Silicon, AWS Graviton and most
+// 64-bit ARM server and mobile cores. The kernel body is shared with the
+// AES-NI kernel line for line; only the 128-bit vector primitives below
+// differ.
+//
+// Defining TACHYON_NEON_EMULATE on x86 builds the same kernel on top of
+// SSE/AES-NI primitives, so the port can be checked bit for bit against the
+// other backends on machines without an ARM CPU (use with FORCE_NEON).
+
+#if defined(__aarch64__) || defined(_M_ARM64)
+
+#include <arm_neon.h>
+
+#if defined(__ARM_FEATURE_CRYPTO) || defined(__ARM_FEATURE_AES)
+  #define TARGET_NEON
+#elif defined(__clang__)
+  #define TARGET_NEON __attribute__((target("aes")))
+#elif defined(__GNUC__)
+  #define TARGET_NEON __attribute__((target("+crypto")))
+#else
+  #define TARGET_NEON
+#endif
+
+typedef uint8x16_t v128;
+
+/* AESENC = MixColumns(SubBytes(ShiftRows(a))) ^ k. AESE XORs its key first,
+ * so it is called with a zero key and k is applied afterwards. */
+TARGET_NEON
+static inline v128 v_aesenc(v128 a, v128 k) {
+    return veorq_u8(vaesmcq_u8(vaeseq_u8(a, vdupq_n_u8(0))), k);
+}
+
+static inline v128 v_xor(v128 a, v128 b) { return veorq_u8(a, b); }
+
+static inline v128 v_add64(v128 a, v128 b) {
+    return vreinterpretq_u8_u64(vaddq_u64(vreinterpretq_u64_u8(a), vreinterpretq_u64_u8(b)));
+}
+
+static inline v128 v_load(const void *p) { return vld1q_u8((const uint8_t*)p); }
+static inline void v_store(void *p, v128 v) { vst1q_u8((uint8_t*)p, v); }
+
+static inline v128 v_set64(uint64_t hi, uint64_t lo) {
+    return vreinterpretq_u8_u64(vcombine_u64(vcreate_u64(lo), vcreate_u64(hi)));
+}
+
+static inline v128 v_set1_64(uint64_t x) { return vreinterpretq_u8_u64(vdupq_n_u64(x)); }
+
+/* Same immediate encoding as _mm_clmulepi64_si128: bit 0 selects the
+ * 64-bit half of a, bit 4 the half of b. */
+TARGET_NEON
+static inline v128 v_clmul(v128 a, v128 b, int imm) {
+    uint64x2_t a64 = vreinterpretq_u64_u8(a), b64 = vreinterpretq_u64_u8(b);
+    poly64_t x = (poly64_t)((imm & 0x01) ? vgetq_lane_u64(a64, 1) : vgetq_lane_u64(a64, 0));
+    poly64_t y = (poly64_t)((imm & 0x10) ? vgetq_lane_u64(b64, 1) : vgetq_lane_u64(b64, 0));
+    return vreinterpretq_u8_p128(vmull_p64(x, y));
+}
+
+#define TACHYON_HAVE_NEON_KERNEL 1
+
+#elif defined(TACHYON_NEON_EMULATE) && (defined(__x86_64__) || defined(__i386__))
+
+#include <wmmintrin.h>
+#include <emmintrin.h>
+#include <smmintrin.h>
+
+#define TARGET_NEON __attribute__((target("aes,sse4.1,pclmul")))
+
+typedef __m128i v128;
+
+#define v_aesenc(a, k)     _mm_aesenc_si128(a, k)
+#define v_xor(a, b)        _mm_xor_si128(a, b)
+#define v_add64(a, b)      _mm_add_epi64(a, b)
+#define v_load(p)          _mm_loadu_si128((const __m128i*)(p))
+#define v_store(p, v)      _mm_storeu_si128((__m128i*)(p), v)
+#define v_set64(hi, lo)    _mm_set_epi64x((int64_t)(hi), (int64_t)(lo))
+#define v_set1_64(x)       _mm_set1_epi64x((int64_t)(x))
+#define v_clmul(a, b, imm) _mm_clmulepi64_si128(a, b, imm)
+
+#define TACHYON_HAVE_NEON_KERNEL 1
+
+#endif
+
+#ifdef TACHYON_HAVE_NEON_KERNEL
+
+typedef struct {
+    v128 acc[32];
+    uint64_t block_count;
+} tachyon_neon_state_t;
+
+TARGET_NEON void tachyon_neon_init(tachyon_neon_state_t *state, const uint8_t *key, uint64_t seed);
+
+static inline v128 init_reg(uint64_t base) {
+    return v_set64(base + 1, base);
+}
+
+static inline void rotate_lanes(v128 *acc, int base) {
+    v128 tmp = acc[base];
+    acc[base] = acc[base + 1];
+    acc[base + 1] = acc[base + 2];
+    acc[base + 2] = acc[base + 3];
+    acc[base + 3] = tmp;
+}
+
+// =============================================================================
+// COMPRESSION HELPERS
+// =============================================================================
+
+#define AES_MIX(acc, data, rk, lo, blk) \
+    v_aesenc(acc, v_add64(data, v_add64(rk, v_add64(lo, blk))))
+
+TARGET_NEON
+static void neon_compress_phase1_roundrobin(tachyon_neon_state_t *state, v128 d[NUM_LANES][LANE_STRIDE],
+                                             const v128 rk_base[10], const v128 lo_all[32], v128 blk) {
+    for (int r = 0; r < 5; r++) {
+        v128 rk = rk_base[r];
+        
+        for (int i = 0; i < 32; i++) {
+            state->acc[i] = AES_MIX(state->acc[i], d[i / 4][i % 4], rk, lo_all[i], blk);
+        }
+        
+        for (int i = 0; i < NUM_LANES; i++) {
+            int src = (i + 3) % NUM_LANES;
+            for (int j = 0; j < LANE_STRIDE; j++) {
+                d[i][j] = v_xor(d[i][j], state->acc[ACC_INDEX(src, j)]);
+            }
+        }
+        
+        v128 old[32]; 
+        memcpy(old, state->acc, BLOCK_SIZE);
+        
+        for (int i = 0; i < NUM_LANES; i++) {
+            memcpy(&state->acc[ACC_INDEX(i, 0)], &old[ACC_INDEX((i + 1) % NUM_LANES, 0)], LANE_STRIDE * VEC_SIZE);
+        }
+    }
+}
+
+TARGET_NEON
+static void neon_compress_midblock_mixing(tachyon_neon_state_t *state) {
+    v128 old_m[32]; 
+    memcpy(old_m, state->acc, BLOCK_SIZE);
+    
+    for (int i = 0; i < NUM_LANES; i++) {
+        for (int j = 0; j < 4; j++) {
+            state->acc[ACC_INDEX(i, j)] = old_m[ACC_INDEX(i, (j + 1) % 4)];
+        }
+    }
+
+    /* Cross-Accumulator Diffusion Stage 1 */
+    for (int l = 0; l < 4; l++) {
+        for (int i = 0; i < 4; i++) {
+            v128 t_lo = state->acc[ACC_INDEX(i, l)];
+            v128 t_hi = state->acc[ACC_INDEX(i + 4, l)];
+            
+            state->acc[ACC_INDEX(i, l)]     = v_xor(t_lo, t_hi);
+            state->acc[ACC_INDEX(i + 4, l)] = v_add64(t_hi, t_lo);
+        }
+    }
+
+    /* Cross-Accumulator Diffusion Stage 2 */
+    for (int l = 0; l < 4; l++) {
+        v128 a0 = state->acc[ACC_INDEX(0, l)], a2 = state->acc[ACC_INDEX(2, l)];
+        state->acc[ACC_INDEX(0, l)] = v_xor(a0, a2); 
+        state->acc[ACC_INDEX(2, l)] = v_add64(a2, a0);
+        
+        v128 a1 = state->acc[ACC_INDEX(1, l)], a3 = state->acc[ACC_INDEX(3, l)];
+        state->acc[ACC_INDEX(1, l)] = v_xor(a1, a3); 
+        state->acc[ACC_INDEX(3, l)] = v_add64(a3, a1);
+        
+        v128 a4 = state->acc[ACC_INDEX(4, l)], a6 = state->acc[ACC_INDEX(6, l)];
+        state->acc[ACC_INDEX(4, l)] = v_xor(a4, a6); 
+        state->acc[ACC_INDEX(6, l)] = v_add64(a6, a4);
+        
+        v128 a5 = state->acc[ACC_INDEX(5, l)], a7 = state->acc[ACC_INDEX(7, l)];
+        state->acc[ACC_INDEX(5, l)] = v_xor(a5, a7); 
+        state->acc[ACC_INDEX(7, l)] = v_add64(a7, a5);
+    }
+}
+
+TARGET_NEON
+static void neon_compress_phase2_and_feedforward(tachyon_neon_state_t *state, v128 d[NUM_LANES][LANE_STRIDE],
+                                                  const v128 rk_base[10], const v128 lo_all[32], 
+                                                  v128 blk, const v128 saves[32]) {
+    for (int r = 5; r < 10; r++) {
+        v128 rk = rk_base[r];
+        
+        for (int i = 0; i < 32; i++) {
+            state->acc[i] = AES_MIX(state->acc[i], d[((i / 4) + 4) % 8][i % 4], rk, lo_all[i], blk);
+        }
+        
+        for (int i = 0; i < NUM_LANES; i++) {
+            int src = (i + 3) % NUM_LANES;
+            for (int j = 0; j < LANE_STRIDE; j++) {
+                d[i][j] = v_xor(d[i][j], state->acc[ACC_INDEX(src, j)]);
+            }
+        }
+        
+        v128 old[32]; 
+        memcpy(old, state->acc, BLOCK_SIZE);
+        for (int i = 0; i < NUM_LANES; i++) {
+            memcpy(&state->acc[ACC_INDEX(i, 0)], &old[ACC_INDEX((i + 1) % NUM_LANES, 0)], LANE_STRIDE * VEC_SIZE);
+        }
+    }
+
+    /* Davies-Meyer Feed-Forward */
+    v128 old_f[32]; 
+    memcpy(old_f, state->acc, BLOCK_SIZE);
+    
+    for (int i = 0; i < NUM_LANES; i++) {
+        for (int j = 0; j < LANE_STRIDE; j++) {
+            state->acc[ACC_INDEX(i, j)] = old_f[ACC_INDEX(i, (j + 1) % LANE_STRIDE)];
+        }
+    }
+    
+    for (int i = 0; i < 32; i++) {
+        state->acc[i] = v_xor(state->acc[i], saves[i]);
+    }
+}
+
+// =============================================================================
+// FINALIZATION HELPERS
+// =============================================================================
+
+TARGET_NEON
+static size_t neon_finalize_remainder_chunks(tachyon_neon_state_t *state, const uint8_t *remainder, size_t rem_len,
+                                              v128 wk, const v128 rk_chain[10]) {
+    size_t chunk_idx = 0;
+    while ((chunk_idx + 1) * REMAINDER_CHUNK_SIZE <= rem_len) {
+        const uint8_t *ptr = remainder + chunk_idx * REMAINDER_CHUNK_SIZE;
+        v128 d_rem[LANE_STRIDE];
+        
+        for (int j = 0; j < LANE_STRIDE; j++) {
+            d_rem[j] = v_aesenc(v_load(ptr + j * VEC_SIZE), wk);
+        }
+        
+        int base = chunk_idx * LANE_STRIDE;
+        v128 saves[LANE_STRIDE]; 
+        memcpy(saves, &state->acc[base], REMAINDER_CHUNK_SIZE);
+        
+        for (int r = 0; r < 10; r++) {
+            v128 rk = rk_chain[r];
+            for (int j = 0; j < LANE_STRIDE; j++) {
+                v128 lo = v_set1_64(LANE_OFFSETS[base + j]);
+                state->acc[base + j] = v_aesenc(state->acc[base + j], v_add64(d_rem[j], v_add64(rk, lo)));
+            }
+            
+            v128 t0 = state->acc[base + 0];
+            v128 t1 = state->acc[base + 1];
+            v128 t2 = state->acc[base + 2];
+            v128 t3 = state->acc[base + 3];
+            
+            d_rem[0] = v_xor(d_rem[0], t1);
+            d_rem[1] = v_xor(d_rem[1], t2);
+            d_rem[2] = v_xor(d_rem[2], t3);
+            d_rem[3] = v_xor(d_rem[3], t0);
+            
+            rotate_lanes(state->acc, base);
+        }
+        
+        for (int j = 0; j < LANE_STRIDE; j++) {
+            state->acc[base + j] = v_xor(state->acc[base + j], saves[j]);
+        }
+        
+        chunk_idx++;
+    }
+    
+    return chunk_idx * REMAINDER_CHUNK_SIZE;
+}
+
+TARGET_NEON
+static void neon_finalize_tree_merge(tachyon_neon_state_t *state) {
+    v128 mrk0 = v_set1_64(C5);
+    v128 mrk1 = v_set1_64(C6);
+    v128 mrk2 = v_set1_64(C7);
+    
+    // Level 0: 32 -> 16
+    for (int i = 0; i < 16; i++) {
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i + 16], mrk0));
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i], mrk0));
+    }
+    
+    // Level 1: 16 -> 8
+    for (int i = 0; i < 8; i++) {
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i + 8], mrk1));
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i], mrk1));
+    }
+    
+    // Level 2: 8 -> 4
+    for (int i = 0; i < LANE_STRIDE; i++) {
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i + LANE_STRIDE], mrk2));
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(state->acc[i], mrk2));
+    }
+}
+
+TARGET_NEON
+static void neon_finalize_clmul_hardening(tachyon_neon_state_t *state) {
+    v128 clmul_k = v_set64(CLMUL_CONSTANT2, CLMUL_CONSTANT);
+    
+    for (int i = 0; i < LANE_STRIDE; i++) {
+        v128 cl_lo = v_clmul(state->acc[i], clmul_k, 0x00);
+        v128 cl_hi = v_clmul(state->acc[i], clmul_k, 0x11);
+        v128 cl1   = v_xor(cl_lo, cl_hi);
+        v128 mid   = v_aesenc(state->acc[i], cl1);
+        v128 cl2   = v_clmul(mid, mid, 0x01);
+        
+        state->acc[i] = v_aesenc(state->acc[i], v_xor(cl1, cl2));
+    }
+}
+
+TARGET_NEON
+static void neon_finalize_block_process(tachyon_neon_state_t *state, v128 d_pad[LANE_STRIDE],
+                                         uint64_t total_len, uint64_t domain, const v128 rk_chain[10]) {
+    v128 saves_final[LANE_STRIDE]; 
+    memcpy(saves_final, state->acc, REMAINDER_CHUNK_SIZE);
+    
+    v128 meta[LANE_STRIDE] = {
+        v_set64(CHAOS_BASE, domain ^ total_len),
+        v_set64(domain,     total_len),
+        v_set64(total_len,  CHAOS_BASE),
+        v_set64(CHAOS_BASE, domain)
+    };
+    
+    for (int i = 0; i < LANE_STRIDE; i++) {
+        state->acc[i] = v_xor(state->acc[i], v_xor(d_pad[i], meta[i]));
+    }
+    
+    for (int r = 0; r < 10; r++) {
+        v128 rk = rk_chain[r];
+        
+        for (int i = 0; i < LANE_STRIDE; i++) {
+            state->acc[i] = v_aesenc(state->acc[i], v_add64(d_pad[i], rk));
+        }
+        
+        if (r % 2 == 1) {
+            v128 t0 = state->acc[0];
+            v128 t1 = state->acc[1];
+            v128 t2 = state->acc[2];
+            v128 t3 = state->acc[3];
+            
+            d_pad[0] = v_xor(d_pad[0], t1);
+            d_pad[1] = v_xor(d_pad[1], t2);
+            d_pad[2] = v_xor(d_pad[2], t3);
+            d_pad[3] = v_xor(d_pad[3], t0);
+        }
+        
+        rotate_lanes(state->acc, 0);
+    }
+    
+    for (int i = 0; i < LANE_STRIDE; i++) {
+        state->acc[i] = v_xor(state->acc[i], saves_final[i]);
+    }
+}
+
+TARGET_NEON
+static void neon_finalize_key_reabsorption(tachyon_neon_state_t *state, const uint8_t *key) {
+    if (!key) return;
+    
+    v128 k0 = v_load(key);
+    v128 k1 = v_load(key + VEC_SIZE);
+    
+    // Round 1
+    state->acc[0] = v_aesenc(state->acc[0], k0); 
+    state->acc[1] = v_aesenc(state->acc[1], k1);
+    state->acc[2] = v_aesenc(state->acc[2], k1); 
+    state->acc[3] = v_aesenc(state->acc[3], k0);
+    
+    // Round 2
+    state->acc[0] = v_aesenc(state->acc[0], k1); 
+    state->acc[1] = v_aesenc(state->acc[1], k0);
+    state->acc[2] = v_aesenc(state->acc[2], k0); 
+    state->acc[3] = v_aesenc(state->acc[3], k1);
+    
+    // Round 3
+    state->acc[0] = v_aesenc(state->acc[0], k0); 
+    state->acc[1] = v_aesenc(state->acc[1], k1);
+    state->acc[2] = v_aesenc(state->acc[2], k0); 
+    state->acc[3] = v_aesenc(state->acc[3], k1);
+    
+    // Round 4
+    state->acc[0] = v_aesenc(state->acc[0], k0); 
+    state->acc[1] = v_aesenc(state->acc[1], k0);
+    state->acc[2] = v_aesenc(state->acc[2], k1); 
+    state->acc[3] = v_aesenc(state->acc[3], k1);
+}
+
+TARGET_NEON
+static void neon_lane_reduction_4to256(v128 acc[LANE_STRIDE], uint8_t *out) {
+    v128 mrk0 = v_set1_64(C5);
+    v128 mrk1 = v_set1_64(C6);
+    v128 mrk2 = v_set1_64(C7);
+    
+    v128 a[LANE_STRIDE]; 
+    for (int i = 0; i < LANE_STRIDE; i++) {
+        a[i] = v_aesenc(acc[i], acc[i]);
+    }
+    
+    v128 b0 = v_aesenc(a[0], a[2]);
+    v128 b1 = v_aesenc(a[1], a[3]);
+    v128 b2 = v_aesenc(a[2], a[0]);
+    v128 b3 = v_aesenc(a[3], a[1]);
+    
+    v128 c0 = v_aesenc(b0, b1);
+    v128 c1 = v_aesenc(b1, v_xor(b0, mrk2));
+    v128 c2 = v_aesenc(b2, v_xor(b3, mrk1));
+    v128 c3 = v_aesenc(b3, v_xor(b2, mrk0));
+    
+    v128 out_l = v_aesenc(c0, c2);
+    v128 out_h = v_aesenc(c1, c3);
+    
+    v_store(out, v_aesenc(out_l, out_h));
+    v_store(out + VEC_SIZE, v_aesenc(out_h, v_xor(out_l, mrk2)));
+}
+
+TARGET_NEON
+void tachyon_neon_finalize(tachyon_neon_state_t *state, const uint8_t *remainder, size_t rem_len, 
+                            uint64_t total_len, uint64_t domain, const uint8_t *key, uint8_t *out) {
+    v128 rk_chain[10];
+    for (int r = 0; r < 10; r++) {
+        rk_chain[r] = v_set64(RK_CHAIN[r][1], RK_CHAIN[r][0]);
+    }
+    
+    v128 wk = v_set64((int64_t)WHITENING1, (int64_t)WHITENING0);
+    
+    /* 1. Remainder Chunks */
+    size_t processed = neon_finalize_remainder_chunks(state, remainder, rem_len, wk, rk_chain);
+
+    /* 2. Final Padding Block */
+    uint8_t block[REMAINDER_CHUNK_SIZE] = {0};
+    size_t left = rem_len - processed;
+    
+    if (left > 0) {
+        memcpy(block, remainder + processed, left);
+    }
+    block[left] = 0x80;
+    
+    v128 d_pad[LANE_STRIDE];
+    for (int j = 0; j < LANE_STRIDE; j++) {
+        d_pad[j] = v_aesenc(v_load(block + j * VEC_SIZE), wk);
+    }
+    
+    /* 3. Tree Merge (32 -> 16 -> 8 -> 4) */
+    neon_finalize_tree_merge(state);
+
+    /* 4. Quadratic CLMUL Hardening */
+    neon_finalize_clmul_hardening(state);
+    
+    /* 5. Final Block Processing */
+    neon_finalize_block_process(state, d_pad, total_len, domain, rk_chain);
+    
+    /* 6. Key Re-absorption */
+    neon_finalize_key_reabsorption(state, key);
+    
+    /* 7. Final Lane Reduction */
+    neon_lane_reduction_4to256(state->acc, out);
+}
+
+// =============================================================================
+// SHORT PATH (0...63 bytes)
+// =============================================================================
+
+TARGET_NEON
+static void neon_short_initialize_state(v128 acc[LANE_STRIDE]) {
+    acc[0] = v_set64(SHORT_INIT[0][1], SHORT_INIT[0][0]);
+    acc[1] = v_set64(SHORT_INIT[1][1], SHORT_INIT[1][0]);
+    acc[2] = v_set64(SHORT_INIT[2][1], SHORT_INIT[2][0]);
+    acc[3] = v_set64(SHORT_INIT[3][1], SHORT_INIT[3][0]);
+}
+
+TARGET_NEON
+static void neon_short_process_block(v128 acc[LANE_STRIDE], const uint8_t *input, size_t len, uint64_t domain) {
+    v128 rk_chain[10];
+    for (int r = 0; r < 10; r++) {
+        rk_chain[r] = v_set64(RK_CHAIN[r][1], RK_CHAIN[r][0]);
+    }
+    
+    v128 wk = v_set64((int64_t)WHITENING1, (int64_t)WHITENING0);
+    
+    uint8_t block[REMAINDER_CHUNK_SIZE] = {0};
+    if (len > 0) {
+        memcpy(block, input, len);
+    }
+    block[len] = 0x80;
+    
+    v128 d0 = v_aesenc(v_load(block), wk);
+    v128 d1 = v_aesenc(v_load(block + VEC_SIZE), wk);
+    v128 d2 = v_aesenc(v_load(block + 32), wk);
+    v128 d3 = v_aesenc(v_load(block + 48), wk);
+    
+    v128 saves[4] = {acc[0], acc[1], acc[2], acc[3]};
+
+    v128 meta[LANE_STRIDE] = {
+        v_set64(CHAOS_BASE, domain ^ (uint64_t)len), 
+        v_set64(domain, (uint64_t)len),
+        v_set64((uint64_t)len, CHAOS_BASE), 
+        v_set64(CHAOS_BASE, domain)
+    };
+    
+    acc[0] = v_xor(acc[0], v_xor(d0, meta[0])); 
+    acc[1] = v_xor(acc[1], v_xor(d1, meta[1]));
+    acc[2] = v_xor(acc[2], v_xor(d2, meta[2])); 
+    acc[3] = v_xor(acc[3], v_xor(d3, meta[3]));
+    
+    for (int r = 0; r < 10; r++) {
+        v128 rk = rk_chain[r];
+        
+        acc[0] = v_aesenc(acc[0], v_add64(d0, v_add64(rk, v_set1_64(LANE_OFFSETS[0]))));
+        acc[1] = v_aesenc(acc[1], v_add64(d1, v_add64(rk, v_set1_64(LANE_OFFSETS[1]))));
+        acc[2] = v_aesenc(acc[2], v_add64(d2, v_add64(rk, v_set1_64(LANE_OFFSETS[2]))));
+        acc[3] = v_aesenc(acc[3], v_add64(d3, v_add64(rk, v_set1_64(LANE_OFFSETS[3]))));
+        
+        if (r % 2 == 1) {
+            v128 t0 = acc[0];
+            v128 t1 = acc[1];
+            v128 t2 = acc[2];
+            v128 t3 = acc[3];
+            
+            d0 = v_xor(d0, t1); 
+            d1 = v_xor(d1, t2);
+            d2 = v_xor(d2, t3); 
+            d3 = v_xor(d3, t0);
+        }
+        rotate_lanes(acc, 0);
+    }
+    
+    for (int i = 0; i < 4; i++) {
+        acc[i] = v_xor(acc[i], saves[i]);
+    }
+}
+
+TARGET_NEON
+void tachyon_neon_oneshot_short(const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out) {
+    if (seed == 0 && !key) {
+        v128 acc[4];
+        neon_short_initialize_state(acc);
+        neon_short_process_block(acc, input, len, domain);
+        neon_lane_reduction_4to256(acc, out);
+    } else {
+        tachyon_neon_state_t state;
+        tachyon_neon_init(&state, key, seed);
+        tachyon_neon_finalize(&state, input, len, len, domain, key, out);
+    }
+}
+
+// =============================================================================
+// INITIALIZATION
+// =============================================================================
+
+TARGET_NEON
+void tachyon_neon_init(tachyon_neon_state_t *state, const uint8_t *key, uint64_t seed) {
+    uint64_t C_VALS[NUM_LANES] = {C0, C1, C2, C3, C4, C5, C6, C7};
+    for (int i = 0; i < NUM_LANES; i++) {
+        uint64_t base = C_VALS[i];
+        state->acc[ACC_INDEX(i, 0)] = init_reg(base);
+        state->acc[ACC_INDEX(i, 1)] = init_reg(base + 2);
+        state->acc[ACC_INDEX(i, 2)] = init_reg(base + 4);
+        state->acc[ACC_INDEX(i, 3)] = init_reg(base + 6);
+    }
+    
+    v128 s_vec = (seed != 0) ? v_set1_64(seed) : v_set1_64(C5);
+    for (int i = 0; i < 32; i++) {
+        state->acc[i] = v_aesenc(state->acc[i], s_vec);
+    }
+    
+    if (key) {
+        v128 k0 = v_load(key);
+        v128 k1 = v_load(key + VEC_SIZE);
+        v128 gr = v_set1_64(GOLDEN_RATIO);
+        v128 k2 = v_xor(k0, gr);
+        v128 k3 = v_xor(k1, gr);
+
+        for (int i = 0; i < NUM_LANES; i++) {
+            v128 lo = v_set1_64(LANE_OFFSETS[i]);
+            for (int j = 0; j < LANE_STRIDE; j++) {
+                v128 k = (j == 0) ? k0 : (j == 1) ? k1 : (j == 2) ? k2 : k3;
+                state->acc[ACC_INDEX(i, j)] = v_aesenc(state->acc[ACC_INDEX(i, j)], v_add64(k, lo));
+                state->acc[ACC_INDEX(i, j)] = v_aesenc(state->acc[ACC_INDEX(i, j)], k);
+            }
+        }
+    }
+    state->block_count = 0;
+}
+
+// =============================================================================
+// COMPRESSION
+// =============================================================================
+
+TARGET_NEON
+void tachyon_neon_update(tachyon_neon_state_t *state, const uint8_t *input, size_t len) {
+    v128 rk_base[10];
+    for (int r = 0; r < 10; r++) {
+        rk_base[r] = v_set64(RK_CHAIN[r][1], RK_CHAIN[r][0]);
+    }
+    
+    v128 wk = v_set64((int64_t)WHITENING1, (int64_t)WHITENING0);
+    
+    v128 lo_all[32];
+    for (int i = 0; i < 32; i++) {
+        lo_all[i] = v_set1_64(LANE_OFFSETS[i]);
+    }
+
+    size_t processed = 0;
+    while (processed + BLOCK_SIZE <= len) {
+        v128 saves[32];
+        memcpy(saves, state->acc, BLOCK_SIZE);
+        
+        const uint8_t *b_ptr = input + processed;
+        v128 blk = v_set1_64(state->block_count);
+
+        v128 d[NUM_LANES][LANE_STRIDE];
+        for (int i = 0; i < NUM_LANES; i++) {
+            for (int j = 0; j < LANE_STRIDE; j++) {
+                d[i][j] = v_aesenc(v_load(b_ptr + i * (LANE_STRIDE * VEC_SIZE) + j * VEC_SIZE), wk);
+            }
+        }
+
+        /* Phase 1: Round-Robin Mix (Direct Mapping) */
+        neon_compress_phase1_roundrobin(state, d, rk_base, lo_all, blk);
+
+        /* Mid-block mixing: Intra-register lane rotation */
+        neon_compress_midblock_mixing(state);
+
+        /* Phase 2: Completion (Offset Mapping) */
+        neon_compress_phase2_and_feedforward(state, d, rk_base, lo_all, blk, saves);
+
+        state->block_count++;
+        processed += BLOCK_SIZE;
+    }
+}
+
+// =============================================================================
+// PUBLIC API
+// =============================================================================
+
+TARGET_NEON
+void tachyon_neon_oneshot(const uint8_t *input, size_t len, uint64_t domain, uint64_t seed, const uint8_t *key, uint8_t *out) {
+    if (len < REMAINDER_CHUNK_SIZE) {
+        tachyon_neon_oneshot_short(input, len, domain, seed, key, out);
+        return;
+    }
+    
+    tachyon_neon_state_t state;
+    tachyon_neon_init(&state, key, seed);
+    
+    size_t chunk_len = (len / BLOCK_SIZE) * BLOCK_SIZE;
+    if (chunk_len > 0) {
+        tachyon_neon_update(&state, input, chunk_len);
+    }
+    
+    tachyon_neon_finalize(&state, input + chunk_len, len - chunk_len, len, domain, key, out);
+}
+
+#endif // TACHYON_HAVE_NEON_KERNEL
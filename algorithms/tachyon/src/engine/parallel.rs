@@ -85,7 +85,9 @@ impl MerkleTree {
     }
 
     /// Finalize tree, processing remainder and returning root hash.
-    pub fn finalize(mut self, remainder: &[u8], total_len: u64) -> [u8; 32] {
+    ///
+    /// The stack is drained in place, so the tree must be reset before reuse.
+    pub fn finalize(&mut self, remainder: &[u8], total_len: u64) -> [u8; 32] {
         // Optimization: Small input (single chunk) -> direct hash
         if self.stack.is_empty() {
             return (self.kernel)(remainder, self.domain, self.seed, self.key.as_ref());
@@ -99,7 +101,7 @@ impl MerkleTree {
 
         // Collapse stack to root
         let mut result: Option<[u8; 32]> = None;
-        for node in self.stack.into_iter().flatten() {
+        for node in self.stack.drain(..).flatten() {
             result = Some(match result {
                 None => node,
                 Some(right) => {
@@ -318,6 +318,65 @@ pub unsafe extern "C" fn tachyon_derive_key(
     }
 }
 
+/// Hash whole chunks into Merkle tree leaves.
+///
+/// Leaf `i` is the kernel hash of chunk `i` under the internal leaf domain,
+/// for callers building the tree themselves (e.g. over chunks hashed on
+/// several threads).
+///
+/// # Safety
+/// - `input_ptr` must be valid for `count * CHUNK_SIZE` bytes
+/// - `key_ptr`, if non-null, must point to exactly 32 bytes
+/// - `output_ptr` must be valid for `32 * count` writable bytes
+///
+/// # Returns
+/// - `0`: Success
+/// - `-1`: Null pointer
+/// - `-2`: Panic
+#[no_mangle]
+pub unsafe extern "C" fn tachyon_hash_leaves(
+    input_ptr: *const u8,
+    count: usize,
+    seed: u64,
+    key_ptr: *const u8, // NULL for unkeyed
+    output_ptr: *mut u8,
+) -> i32 {
+    use crate::engine::dispatcher::{get_best_kernel, CHUNK_SIZE};
+    use crate::kernels::constants::{DOMAIN_LEAF, HASH_SIZE};
+
+    if count == 0 {
+        return 0;
+    }
+    if input_ptr.is_null() || output_ptr.is_null() {
+        return -1;
+    }
+
+    let result = std::panic::catch_unwind(|| {
+        let input = slice::from_raw_parts(input_ptr, count * CHUNK_SIZE);
+        let output = slice::from_raw_parts_mut(output_ptr, count * HASH_SIZE);
+        let key = if key_ptr.is_null() {
+            None
+        } else {
+            let mut k = [0u8; HASH_SIZE];
+            k.copy_from_slice(slice::from_raw_parts(key_ptr, 32));
+            Some(k)
+        };
+
+        let kernel = get_best_kernel();
+        for (chunk, out) in input
+            .chunks_exact(CHUNK_SIZE)
+            .zip(output.chunks_exact_mut(HASH_SIZE))
+        {
+            out.copy_from_slice(&kernel(chunk, DOMAIN_LEAF, seed, key.as_ref()));
+        }
+    });
+
+    match result {
+        Ok(()) => 0,
+        Err(_) => -2,
+    }
+}
+
 // =============================================================================
 // STREAMING API
 // =============================================================================
@@ -355,6 +414,28 @@ pub unsafe extern "C" fn tachyon_hasher_new_seeded(seed: u64) -> *mut TachyonHas
     Box::into_raw(Box::new(TachyonHasherPtr(hasher)))
 }
 
+/// Create new hasher with domain, seed and optional key. Returns NULL if CPU
+/// unsupported. Caller must free with `tachyon_hasher_free`.
+///
+/// # Safety
+/// - `key_ptr`, if non-null, must point to exactly 32 bytes
+#[no_mangle]
+pub unsafe extern "C" fn tachyon_hasher_new_full(
+    domain: u64,
+    seed: u64,
+    key_ptr: *const u8, // NULL for unkeyed
+) -> *mut TachyonHasherPtr {
+    let Ok(mut hasher) = crate::streaming::TachyonHasher::new_full(domain, seed) else {
+        return std::ptr::null_mut();
+    };
+    if !key_ptr.is_null() {
+        let mut k = [0u8; crate::kernels::constants::HASH_SIZE];
+        k.copy_from_slice(slice::from_raw_parts(key_ptr, 32));
+        hasher.set_key(&k);
+    }
+    Box::into_raw(Box::new(TachyonHasherPtr(hasher)))
+}
+
 /// Feed data into the hasher.
 ///
 /// # Safety
@@ -392,6 +473,50 @@ pub unsafe extern "C" fn tachyon_hasher_finalize(
     ptr::copy_nonoverlapping(hash.as_ptr(), out_ptr, 32);
 }
 
+/// Finalize and write hash, then reset the hasher for reuse.
+///
+/// Unlike `tachyon_hasher_finalize`, the hasher is NOT freed: it keeps its
+/// domain, seed and key and can absorb the next input immediately. The
+/// hasher is finalized in place, without copying its buffer.
+///
+/// # Safety
+/// - `state_ptr` must be a valid pointer obtained from `tachyon_hasher_new*`
+/// - `out_ptr` must be valid for 32 writable bytes
+///
+/// # Returns
+/// - `0`: Success
+/// - `-1`: Null pointer
+#[no_mangle]
+pub unsafe extern "C" fn tachyon_hasher_finalize_reset(
+    state_ptr: *mut TachyonHasherPtr,
+    out_ptr: *mut u8,
+) -> i32 {
+    if state_ptr.is_null() || out_ptr.is_null() {
+        return -1;
+    }
+    let hasher = &mut (*state_ptr).0;
+    let hash = hasher.finalize_reset();
+    ptr::copy_nonoverlapping(hash.as_ptr(), out_ptr, 32);
+    0
+}
+
+/// Duplicate a hasher, including everything absorbed so far.
+///
+/// The copy is independent: updating or finalizing one does not affect the
+/// other. Free it with `tachyon_hasher_finalize` or `tachyon_hasher_free`.
+///
+/// # Safety
+/// - `state_ptr` must be a valid pointer obtained from `tachyon_hasher_new*`
+#[no_mangle]
+pub unsafe extern "C" fn tachyon_hasher_clone(
+    state_ptr: *const TachyonHasherPtr,
+) -> *mut TachyonHasherPtr {
+    if state_ptr.is_null() {
+        return std::ptr::null_mut();
+    }
+    Box::into_raw(Box::new(TachyonHasherPtr((*state_ptr).0.clone())))
+}
+
 /// Free hasher without finalizing.
 ///
 /// # Safety
@@ -130,15 +130,30 @@ impl TachyonHasher {
     /// Finalize and return hash.
     #[must_use]
     pub fn finalize(mut self) -> [u8; crate::kernels::constants::HASH_SIZE] {
+        self.finalize_in_place()
+    }
+
+    /// Finalize and reset the hasher for reuse.
+    ///
+    /// Domain, seed and key are kept, so the hasher can absorb the next
+    /// input straight away. Nothing is cloned.
+    pub fn finalize_reset(&mut self) -> [u8; crate::kernels::constants::HASH_SIZE] {
+        let hash = self.finalize_in_place();
+        self.reset();
+        hash
+    }
+
+    /// Finalize without consuming the hasher. The tree is left drained, so
+    /// the hasher must be reset or dropped afterwards.
+    fn finalize_in_place(&mut self) -> [u8; crate::kernels::constants::HASH_SIZE] {
         // Process any remaining complete chunks
         if self.buffer.len() >= CHUNK_SIZE {
             let complete_bytes = (self.buffer.len() / CHUNK_SIZE) * CHUNK_SIZE;
-            let to_process: Vec<u8> = self.buffer.drain(..complete_bytes).collect();
-            self.tree.process_slice(&to_process);
+            self.tree.process_slice(&self.buffer[..complete_bytes]);
+            self.buffer.drain(..complete_bytes);
         }
 
-        let len = self.total_len;
-        self.tree.finalize(&self.buffer, len)
+        self.tree.finalize(&self.buffer, self.total_len)
     }
 
     /// Reset hasher for reuse.
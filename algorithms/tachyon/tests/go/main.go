@@ -2,21 +2,12 @@ package main
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"os"
 	"tachyon" // Use the binding!
+	"tachyon/vectors"
 )
 
-// TestVectors represents the JSON structure
-type TestVectors struct {
-	Vectors []struct {
-		Name  string `json:"name"`
-		Input string `json:"input"`
-		Hash  string `json:"hash"`
-	} `json:"vectors"`
-}
-
 func main() {
 	// Load test vectors
 	data, err := os.ReadFile("../../tests/test_vectors.json")
@@ -25,51 +16,19 @@ func main() {
 		os.Exit(1)
 	}
 
-	var vectors TestVectors
-	if err := json.Unmarshal(data, &vectors); err != nil {
+	// Placeholders like LARGE_1KB are expanded to real data
+	legacy, err := vectors.ParseLegacy(data)
+	if err != nil {
 		fmt.Printf("❌ Failed to parse test vectors: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Println("Testing Tachyon Go Binding...\n")
 
-	for _, vec := range vectors.Vectors {
+	for _, vec := range legacy {
 		fmt.Printf("\n[Test Case: %s]\n", vec.Name)
-
-		// Expand placeholders to actual data
-		var input []byte
-		switch vec.Input {
-		case "LARGE_1KB":
-			input = make([]byte, 1024)
-			for i := range input {
-				input[i] = 0x41 // 'A'
-			}
-		case "MEDIUM_256_A":
-			input = make([]byte, 256)
-			for i := range input {
-				input[i] = 0x41 // 'A'
-			}
-		case "HUGE_1MB":
-			input = make([]byte, 1024*1024)
-			for i := range input {
-				input[i] = 0x41 // 'A'
-			}
-		case "EXACT_64_ZERO":
-			input = make([]byte, 64) // zero-filled by default
-		case "EXACT_512_ONE":
-			input = make([]byte, 512)
-			for i := range input {
-				input[i] = 0x01
-			}
-		case "UNALIGNED_63_TWO":
-			input = make([]byte, 63)
-			for i := range input {
-				input[i] = 0x02
-			}
-		default:
-			input = []byte(vec.Input)
-		}
-		expected := vec.Hash
+		input := vec.Input
+		expected := hex.EncodeToString(vec.Hash)
 
 		// 1. Hash
 		hash, err := tachyon.Hash(input)
@@ -114,5 +73,19 @@ func main() {
 		fmt.Println("  ✓ Bad hash rejected")
 	}
 
+	// Keyed, seeded, domain, output-size and streaming-split vectors
+	extended, err := vectors.Embedded()
+	if err != nil {
+		fmt.Printf("❌ Failed to load embedded vectors: %v\n", err)
+		os.Exit(1)
+	}
+	if failures := vectors.Verify(extended, vectors.Tachyon); len(failures) != 0 {
+		for _, f := range failures {
+			fmt.Printf("❌ Vector '%s': got %x, want %x (%v)\n", f.Vector.Name, f.Got, f.Vector.Hash, f.Err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("\n✓ %d extended vectors passed\n", len(extended))
+
 	fmt.Println("\n✅ Go Binding OK (All vectors passed)")
 }
@@ -65,6 +65,21 @@ int32_t tachyon_hash(const uint8_t *input_ptr, size_t input_len, uint8_t *output
  */
 int32_t tachyon_hash_seeded(const uint8_t *input_ptr, size_t input_len, uint64_t seed, uint8_t *output_ptr);
 
+/**
+ * @brief Hash with domain, seed and optional key in one call.
+ *
+ * @param input_ptr  Pointer to input data.
+ * @param input_len  Length of input in bytes.
+ * @param domain     Domain ID (use TACHYON_DOMAIN_* constants).
+ * @param seed       64-bit seed value.
+ * @param key_ptr    Pointer to 32-byte key, or NULL for unkeyed.
+ * @param output_ptr Pointer to 32-byte output buffer.
+ *
+ * @return 0 on success, -1 on null pointer, -2 on internal error.
+ */
+int32_t tachyon_hash_full(const uint8_t *input_ptr, size_t input_len, uint64_t domain, uint64_t seed,
+                          const uint8_t *key_ptr, uint8_t *output_ptr);
+
 /**
  * @brief Verify hash in constant time (timing-attack resistant).
  *
@@ -126,10 +141,28 @@ int32_t tachyon_verify_mac(const uint8_t *input_ptr, size_t input_len, const uin
  */
 int32_t tachyon_derive_key(const uint8_t *context_ptr, size_t context_len, const uint8_t *key_material_ptr, uint8_t *output_ptr);
 
+/**
+ * @brief Hash whole 256 KB chunks into Merkle tree leaves.
+ *
+ * For callers building the tree themselves, e.g. from chunks hashed on
+ * several threads: leaf i is the hash of bytes [i * 256 KB, (i + 1) * 256 KB)
+ * under the internal leaf domain.
+ *
+ * @param input_ptr  Pointer to count * 256 KB bytes of input.
+ * @param count      Number of chunks.
+ * @param seed       64-bit seed value.
+ * @param key_ptr    Pointer to 32-byte key, or NULL for unkeyed.
+ * @param output_ptr Pointer to a 32 * count byte output buffer.
+ *
+ * @return 0 on success, -1 on null pointer, -2 on internal error.
+ */
+int32_t tachyon_hash_leaves(const uint8_t *input_ptr, size_t count, uint64_t seed,
+                            const uint8_t *key_ptr, uint8_t *output_ptr);
+
 /**
  * @brief Get the name of the hardware backend currently in use.
  *
- * @return String name of the backend (AVX-512, AES-NI, or Portable).
+ * @return String name of the backend (AVX-512, AES-NI, NEON, or Portable).
  */
 const char* tachyon_get_backend_name(void);
 
@@ -163,6 +196,19 @@ void* tachyon_hasher_new_with_domain(uint64_t domain);
  */
 void* tachyon_hasher_new_seeded(uint64_t seed);
 
+/**
+ * @brief Create a new streaming hasher with domain, seed and optional key.
+ *
+ * Produces the same result as tachyon_hash_full() over the whole input.
+ *
+ * @param domain  Domain ID (use TACHYON_DOMAIN_* constants).
+ * @param seed    64-bit seed value.
+ * @param key_ptr Pointer to 32-byte key, or NULL for unkeyed.
+ *
+ * @return Opaque pointer to hasher state, or NULL on error.
+ */
+void* tachyon_hasher_new_full(uint64_t domain, uint64_t seed, const uint8_t* key_ptr);
+
 /**
  * @brief Add data to the hasher.
  *
@@ -180,6 +226,31 @@ void tachyon_hasher_update(void* state, const uint8_t* data, size_t len);
  */
 void tachyon_hasher_finalize(void* state, uint8_t* out_ptr);
 
+/**
+ * @brief Finalize and get hash, then reset the hasher for reuse.
+ *
+ * Unlike tachyon_hasher_finalize(), the state is NOT freed. Domain, seed and
+ * key are kept, so the next input can be absorbed immediately. The state is
+ * finalized in place; nothing is allocated.
+ *
+ * @param state   Hasher state from tachyon_hasher_new().
+ * @param out_ptr Pointer to 32-byte output buffer.
+ *
+ * @return 0 on success, -1 on null pointer, -2 on internal error.
+ */
+int32_t tachyon_hasher_finalize_reset(void* state, uint8_t* out_ptr);
+
+/**
+ * @brief Duplicate a hasher, including all data absorbed so far.
+ *
+ * The copy is independent of the original and must be freed with
+ * tachyon_hasher_finalize() or tachyon_hasher_free().
+ *
+ * @param state Hasher state from tachyon_hasher_new().
+ * @return Opaque pointer to the copy, or NULL on error.
+ */
+void* tachyon_hasher_clone(const void* state);
+
 /**
  * @brief Free hasher without finalizing (if needed).
  *
@@ -0,0 +1,115 @@
+package tachyon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ============================================================================
+// BACKEND REPORTING
+// ============================================================================
+
+// Backend names reported by BackendName.
+const (
+	BackendAVX512   = "AVX-512 (Truck)"
+	BackendAESNI    = "AES-NI (Scooter)"
+	BackendNEON     = "NEON (ARMv8 Crypto)"
+	BackendPortable = "Portable"
+	BackendGo       = "Go"
+)
+
+// BackendName returns the name of the native kernel selected for this CPU,
+// one of the Backend constants.
+//
+// The choice is made once, on first use, unless overridden with
+// SetImplementation. The Rust library (tachyon_rustlib
+// tag) has no NEON kernel and reports BackendPortable on ARM64. Builds
+// without cgo (js/wasm, wasip1) report BackendGo.
+func BackendName() string {
+	return backendName()
+}
+
+// Accelerated reports whether a SIMD kernel is in use rather than a portable
+// fallback.
+func Accelerated() bool {
+	name := BackendName()
+	return name != BackendPortable && name != BackendGo
+}
+
+// ============================================================================
+// IMPLEMENTATION SELECTION
+// ============================================================================
+
+// Implementation identifies a code path selectable with SetImplementation.
+type Implementation int
+
+const (
+	ImplAuto     Implementation = iota // The kernel selected for this CPU
+	ImplAVX512                         // AVX-512 + VAES (x86-64)
+	ImplAESNI                          // AES-NI, the 128-bit x86 kernel
+	ImplNEON                           // ARMv8 crypto extensions
+	ImplPortable                       // The native scalar kernel
+	ImplPureGo                         // The pure Go port of the portable kernel
+)
+
+// String returns the backend name BackendName reports while impl is selected,
+// or "Auto".
+func (impl Implementation) String() string {
+	switch impl {
+	case ImplAuto:
+		return "Auto"
+	case ImplAVX512:
+		return BackendAVX512
+	case ImplAESNI:
+		return BackendAESNI
+	case ImplNEON:
+		return BackendNEON
+	case ImplPortable:
+		return BackendPortable
+	case ImplPureGo:
+		return BackendGo
+	}
+	return fmt.Sprintf("Implementation(%d)", int(impl))
+}
+
+// ErrImplementationUnavailable is returned by SetImplementation when the
+// requested code path is not available on this CPU or in this build.
+var ErrImplementationUnavailable = errors.New("tachyon: implementation not available")
+
+func errUnavailable(impl Implementation) error {
+	return fmt.Errorf("%w: %s", ErrImplementationUnavailable, impl)
+}
+
+var implMu sync.Mutex
+
+// SetImplementation pins the process to one code path, e.g. to compare
+// kernels in benchmarks or to run the same path across a heterogeneous
+// fleet. ImplAuto restores the per-CPU choice. All paths produce identical
+// output; only speed differs.
+//
+// There is no AVX2 kernel: the x86 tiers are ImplAVX512 and ImplAESNI. On a
+// failed call the previous selection stays in effect. The Rust library
+// (tachyon_rustlib tag) cannot be pinned and accepts only its own kernel or
+// ImplPureGo; builds without cgo accept only ImplAuto and ImplPureGo.
+//
+// Call it before hashing starts: the switch is process-wide and not
+// synchronized with hashes in flight. Streaming hashers keep the engine (Go
+// or native) they were created with.
+func SetImplementation(impl Implementation) error {
+	implMu.Lock()
+	defer implMu.Unlock()
+
+	switch impl {
+	case ImplPureGo:
+		setPureGo(true)
+		return nil
+	case ImplAuto, ImplAVX512, ImplAESNI, ImplNEON, ImplPortable:
+		if err := selectNative(impl); err != nil {
+			return err
+		}
+		setPureGo(false)
+		return nil
+	}
+	return fmt.Errorf("tachyon: unknown implementation %d", int(impl))
+}
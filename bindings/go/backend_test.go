@@ -0,0 +1,97 @@
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestBackendName(t *testing.T) {
+	name := BackendName()
+	switch name {
+	case BackendAVX512, BackendAESNI:
+		if runtime.GOARCH != "amd64" && runtime.GOARCH != "386" {
+			t.Errorf("BackendName() = %q on %s", name, runtime.GOARCH)
+		}
+	case BackendNEON:
+		if runtime.GOARCH != "arm64" {
+			t.Errorf("BackendName() = %q on %s", name, runtime.GOARCH)
+		}
+	case BackendPortable, BackendGo:
+	default:
+		t.Fatalf("BackendName() = %q, want one of the Backend constants", name)
+	}
+	if Accelerated() != (name != BackendPortable && name != BackendGo) {
+		t.Error("Accelerated should match BackendName")
+	}
+	t.Logf("backend: %s", name)
+}
+
+func TestSetImplementation(t *testing.T) {
+	defer SetImplementation(ImplAuto)
+
+	inputs := [][]byte{nil, []byte("abc"), bytes.Repeat([]byte("A"), 1024), bytes.Repeat([]byte{0x5a}, 600*1024)}
+	want := make([][]byte, len(inputs))
+	for i, in := range inputs {
+		want[i], _ = Hash(in, WithSeed(7))
+	}
+
+	impls := []Implementation{ImplAVX512, ImplAESNI, ImplNEON, ImplPortable, ImplPureGo, ImplAuto}
+	for _, impl := range impls {
+		err := SetImplementation(impl)
+		if errors.Is(err, ErrImplementationUnavailable) {
+			t.Logf("%s: unavailable", impl)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("SetImplementation(%s) failed: %v", impl, err)
+		}
+		if impl != ImplAuto && BackendName() != impl.String() {
+			t.Errorf("BackendName() = %q after SetImplementation(%s)", BackendName(), impl)
+		}
+		for i, in := range inputs {
+			got, err := Hash(in, WithSeed(7))
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+			if !bytes.Equal(got, want[i]) {
+				t.Errorf("%s: Hash(%d bytes) differs from the default kernel", impl, len(in))
+			}
+
+			h := NewHasherSeeded(7)
+			h.Update(in)
+			sum, _ := h.Finalize()
+			if !bytes.Equal(sum, want[i]) {
+				t.Errorf("%s: streaming hash of %d bytes differs from the default kernel", impl, len(in))
+			}
+		}
+	}
+
+	if err := SetImplementation(ImplPureGo); err != nil {
+		t.Fatalf("SetImplementation(ImplPureGo) failed: %v", err)
+	}
+	if err := SetImplementation(Implementation(99)); err == nil {
+		t.Error("Unknown implementation should return error")
+	}
+	if BackendName() != BackendGo {
+		t.Error("Failed SetImplementation should keep the previous selection")
+	}
+}
+
+func BenchmarkImplementations(b *testing.B) {
+	defer SetImplementation(ImplAuto)
+
+	data := make([]byte, 64*1024)
+	for _, impl := range []Implementation{ImplAVX512, ImplAESNI, ImplNEON, ImplPortable, ImplPureGo} {
+		if SetImplementation(impl) != nil {
+			continue
+		}
+		b.Run(impl.String(), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				Hash(data)
+			}
+		})
+	}
+}
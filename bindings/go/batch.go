@@ -0,0 +1,319 @@
+package tachyon
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ============================================================================
+// SEEDED BATCH API
+// ============================================================================
+
+// HashSeededMulti computes HashSeeded(data, seed) for every seed at once.
+//
+// All hashes are computed in a single native call, which makes this the
+// cheap way to obtain several independent hashes of one key (e.g. for Bloom
+// filters). Result i equals HashSeeded(data, seeds[i]).
+func HashSeededMulti(data []byte, seeds []uint64) ([]Digest, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	out := make([]Digest, len(seeds))
+
+	if err := hashSeededMulti(data, seeds, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HashK returns k pairwise-different 64-bit hashes of data.
+//
+// data is hashed once with HashSeeded(data, baseSeed) and the digest is
+// expanded into k words (block i = HashKeyed(LE64(i), digest), read as
+// little-endian uint64s), all in a single native call. This gives sketches
+// (Bloom filters, count-min, HyperLogLog variants) their k hash functions
+// for the cost of one. Result i does not depend on k, so HashK(data, k, s)
+// is a prefix of HashK(data, k+1, s).
+//
+// A word equal to an earlier one (probability ~k²/2^65) is replaced by
+// v*0x9E3779B97F4A7C15+1 until unique.
+func HashK(data []byte, k int, baseSeed uint64) ([]uint64, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	raw := make([]byte, 8*k)
+	if err := hashExpand(data, baseSeed, raw); err != nil {
+		return nil, err
+	}
+
+	out := make([]uint64, k)
+	seen := make(map[uint64]struct{}, k)
+	for i := range out {
+		v := binary.LittleEndian.Uint64(raw[8*i:])
+		for {
+			if _, dup := seen[v]; !dup {
+				break
+			}
+			v = v*0x9E3779B97F4A7C15 + 1
+		}
+		seen[v] = struct{}{}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// ============================================================================
+// MAC BATCH API
+// ============================================================================
+
+// MACItem is one message and its claimed MAC for VerifyMACBatch.
+type MACItem struct {
+	Data []byte
+	MAC  []byte
+}
+
+// VerifyMACBatch verifies many (data, MAC) pairs under one 32-byte key.
+//
+// All MACs are computed in a single native call and each is compared in
+// constant time; result i equals VerifyMAC(items[i].Data, key,
+// items[i].MAC). An item with empty data or a MAC that is not 32 bytes does
+// not verify, rather than failing the whole batch.
+func VerifyMACBatch(key []byte, items []MACItem) ([]bool, error) {
+	if len(key) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
+	}
+	ok := make([]bool, len(items))
+
+	inputs := make([][]byte, 0, len(items))
+	index := make([]int, 0, len(items))
+	for i, item := range items {
+		if len(item.Data) > 0 && len(item.MAC) == 32 {
+			inputs = append(inputs, item.Data)
+			index = append(index, i)
+		}
+	}
+	if len(inputs) == 0 {
+		return ok, nil
+	}
+
+	macs := make([]Digest, len(inputs))
+	if err := hashKeyedMulti(inputs, key, macs); err != nil {
+		return nil, err
+	}
+	for j, i := range index {
+		ok[i] = subtle.ConstantTimeCompare(macs[j][:], items[i].MAC) == 1
+	}
+	return ok, nil
+}
+
+// ============================================================================
+// BATCH HASHER
+// ============================================================================
+
+// nativeStateFootprint is a conservative estimate of the native memory held
+// by one streaming state: the 256 KB remainder buffer plus its copy during
+// finalization.
+const nativeStateFootprint = 2 * nativeChunkSize
+
+// BatchConfig configures a BatchHasher.
+type BatchConfig struct {
+	// Workers is the number of worker goroutines, each owning one reusable
+	// native state. Defaults to GOMAXPROCS.
+	Workers int
+
+	// MaxNativeMemory caps the native memory held by worker states. Workers
+	// is reduced to fit; zero means no cap.
+	MaxNativeMemory int64
+
+	// ReadBufferSize is the per-worker read buffer used by HashReaders.
+	// Defaults to 256 KB.
+	ReadBufferSize int
+}
+
+// BatchHasher hashes large batches of inputs with zero steady-state allocations.
+//
+// All memory is allocated up front: a fixed set of worker goroutines, one
+// reusable native state and read buffer per worker, and an output arena that
+// only grows when a batch is larger than any before it. Long-running rehash
+// jobs therefore create no garbage per input.
+//
+// Calls to HashAll and HashReaders are serialized; a BatchHasher must be
+// closed to release its native states.
+type BatchHasher struct {
+	mu      sync.Mutex
+	jobs    chan int
+	wg      sync.WaitGroup
+	workers []*batchWorker
+	arena   []Digest
+	inputs  [][]byte
+	readers []io.Reader
+	closed  bool
+}
+
+// batchWorker owns one native state and read buffer.
+type batchWorker struct {
+	state stateHandle
+	buf   []byte
+	err   error
+}
+
+// NewBatchHasher starts a batch hasher with the given configuration.
+func NewBatchHasher(cfg BatchConfig) (*BatchHasher, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if cfg.MaxNativeMemory > 0 {
+		limit := cfg.MaxNativeMemory / nativeStateFootprint
+		if limit < 1 {
+			return nil, errors.New("tachyon: MaxNativeMemory too small for one native state")
+		}
+		workers = int(min(int64(workers), limit))
+	}
+	bufSize := cfg.ReadBufferSize
+	if bufSize <= 0 {
+		bufSize = nativeChunkSize
+	}
+
+	b := &BatchHasher{jobs: make(chan int)}
+	for i := 0; i < workers; i++ {
+		state := newState(0, 0, nil)
+		if state == nil {
+			b.freeStates()
+			return nil, errors.New("tachyon: could not create hasher")
+		}
+		b.workers = append(b.workers, &batchWorker{state: state, buf: make([]byte, bufSize)})
+	}
+	for _, w := range b.workers {
+		go b.run(w)
+	}
+	return b, nil
+}
+
+// Workers returns the number of workers (and native states) in use.
+func (b *BatchHasher) Workers() int {
+	return len(b.workers)
+}
+
+// HashAll computes Hash of every input.
+//
+// The returned slice aliases the internal arena and is only valid until the
+// next call; copy digests that must be retained.
+func (b *BatchHasher) HashAll(inputs [][]byte) ([]Digest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inputs = inputs
+	defer func() { b.inputs = nil }()
+	return b.dispatch(len(inputs))
+}
+
+// HashReaders computes the streaming hash of every reader's content.
+//
+// Readers are consumed concurrently, one per worker. The returned slice
+// aliases the internal arena and is only valid until the next call.
+func (b *BatchHasher) HashReaders(readers []io.Reader) ([]Digest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.readers = readers
+	defer func() { b.readers = nil }()
+	return b.dispatch(len(readers))
+}
+
+// Close stops the workers and frees their native states.
+func (b *BatchHasher) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.jobs)
+	b.freeStates()
+}
+
+// dispatch fans n jobs out to the workers and waits for them.
+// Caller must hold b.mu.
+func (b *BatchHasher) dispatch(n int) ([]Digest, error) {
+	if b.closed {
+		return nil, errors.New("tachyon: batch hasher closed")
+	}
+	if cap(b.arena) < n {
+		b.arena = make([]Digest, n)
+	}
+	b.arena = b.arena[:n]
+
+	b.wg.Add(n)
+	for i := 0; i < n; i++ {
+		b.jobs <- i
+	}
+	b.wg.Wait()
+
+	for _, w := range b.workers {
+		if w.err != nil {
+			err := w.err
+			for _, w := range b.workers {
+				w.err = nil
+			}
+			return nil, err
+		}
+	}
+	return b.arena, nil
+}
+
+// run processes jobs until the hasher is closed.
+func (b *BatchHasher) run(w *batchWorker) {
+	for i := range b.jobs {
+		chunkBoundary("batch")
+		var err error
+		if b.readers != nil {
+			err = w.hashReader(b.readers[i], &b.arena[i])
+		} else {
+			err = hashInto(b.inputs[i], &b.arena[i])
+		}
+		if err != nil && w.err == nil {
+			w.err = err
+		}
+		b.wg.Done()
+	}
+}
+
+// hashReader streams r through the worker's native state into out.
+func (w *batchWorker) hashReader(r io.Reader, out *Digest) error {
+	for {
+		n, err := r.Read(w.buf)
+		chunkBoundary("batch-read")
+		stateUpdate(w.state, w.buf[:n])
+		if err == io.EOF {
+			return stateFinalizeReset(w.state, out)
+		}
+		if err != nil {
+			// Discard the partial state so the worker stays reusable
+			stateFinalizeReset(w.state, out)
+			*out = Digest{}
+			return err
+		}
+	}
+}
+
+// hashInto computes Hash(data) directly into out without allocating.
+func hashInto(data []byte, out *Digest) error {
+	return hashFull(data, 0, 0, nil, out)
+}
+
+// freeStates releases all native worker states.
+func (b *BatchHasher) freeStates() {
+	for _, w := range b.workers {
+		if w.state != nil {
+			stateFree(w.state)
+			w.state = nil
+		}
+	}
+}
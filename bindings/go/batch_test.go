@@ -0,0 +1,226 @@
+package tachyon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestHashSeededMulti(t *testing.T) {
+	data := []byte("multi-seed key")
+	seeds := []uint64{0, 1, 2, 12345, ^uint64(0)}
+
+	digests, err := HashSeededMulti(data, seeds)
+	if err != nil {
+		t.Fatalf("HashSeededMulti failed: %v", err)
+	}
+	if len(digests) != len(seeds) {
+		t.Fatalf("Got %d digests, want %d", len(digests), len(seeds))
+	}
+
+	for i, seed := range seeds {
+		want, _ := HashSeeded(data, seed)
+		if !bytes.Equal(digests[i][:], want) {
+			t.Errorf("seed %d: batch result differs from HashSeeded", seed)
+		}
+	}
+
+	// Empty input works like HashSeeded
+	empty, err := HashSeededMulti(nil, seeds[:1])
+	if err != nil {
+		t.Fatalf("HashSeededMulti(empty) failed: %v", err)
+	}
+	want, _ := HashSeeded(nil, seeds[0])
+	if !bytes.Equal(empty[0][:], want) {
+		t.Error("Empty input should match HashSeeded")
+	}
+
+	if none, err := HashSeededMulti(data, nil); err != nil || none != nil {
+		t.Error("No seeds should return nil, nil")
+	}
+}
+
+func TestHashK(t *testing.T) {
+	data := []byte("sketch key")
+	hashes, err := HashK(data, 10, 7)
+	if err != nil {
+		t.Fatalf("HashK failed: %v", err)
+	}
+	if len(hashes) != 10 {
+		t.Fatalf("HashK returned %d hashes, want 10", len(hashes))
+	}
+
+	seen := map[uint64]bool{}
+	for _, h := range hashes {
+		if seen[h] {
+			t.Error("HashK values should be pairwise different")
+		}
+		seen[h] = true
+	}
+
+	// Prefix-stable in k, and matches the documented expansion
+	short, _ := HashK(data, 3, 7)
+	for i := range short {
+		if short[i] != hashes[i] {
+			t.Error("HashK(k) should be a prefix of HashK(k+n)")
+		}
+	}
+	root, _ := HashSeeded(data, 7)
+	block, _ := HashKeyed(make([]byte, 8), root)
+	if hashes[0] != binary.LittleEndian.Uint64(block) {
+		t.Error("HashK word 0 should be LE64(HashKeyed(LE64(0), HashSeeded(data, seed)))")
+	}
+
+	other, _ := HashK(data, 1, 8)
+	if other[0] == hashes[0] {
+		t.Error("Different base seeds should give different hashes")
+	}
+	if none, err := HashK(data, 0, 7); err != nil || none != nil {
+		t.Error("k = 0 should return nil, nil")
+	}
+}
+
+func TestBatchHasher(t *testing.T) {
+	b, err := NewBatchHasher(BatchConfig{Workers: 4})
+	if err != nil {
+		t.Fatalf("NewBatchHasher failed: %v", err)
+	}
+	defer b.Close()
+
+	inputs := [][]byte{nil, []byte("a"), bytes.Repeat([]byte("b"), 1000), bytes.Repeat([]byte("c"), 600*1024)}
+	digests, err := b.HashAll(inputs)
+	if err != nil {
+		t.Fatalf("HashAll failed: %v", err)
+	}
+	for i, in := range inputs {
+		want, _ := Hash(in)
+		if !bytes.Equal(digests[i][:], want) {
+			t.Errorf("input %d: HashAll differs from Hash", i)
+		}
+	}
+
+	// Native states are reused across readers and batches
+	for round := 0; round < 2; round++ {
+		readers := make([]io.Reader, len(inputs))
+		for i, in := range inputs {
+			readers[i] = iotest.HalfReader(bytes.NewReader(in))
+		}
+		digests, err = b.HashReaders(readers)
+		if err != nil {
+			t.Fatalf("HashReaders failed: %v", err)
+		}
+		for i, in := range inputs {
+			want, _ := Hash(in)
+			if !bytes.Equal(digests[i][:], want) {
+				t.Errorf("round %d, reader %d: HashReaders differs from Hash", round, i)
+			}
+		}
+	}
+}
+
+func TestBatchHasherZeroAlloc(t *testing.T) {
+	b, err := NewBatchHasher(BatchConfig{Workers: 2})
+	if err != nil {
+		t.Fatalf("NewBatchHasher failed: %v", err)
+	}
+	defer b.Close()
+
+	inputs := make([][]byte, 64)
+	for i := range inputs {
+		inputs[i] = bytes.Repeat([]byte{byte(i)}, 100+i)
+	}
+	b.HashAll(inputs) // Size the arena
+
+	allocs := testing.AllocsPerRun(50, func() {
+		if _, err := b.HashAll(inputs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("HashAll allocated %.1f times per batch, want 0", allocs)
+	}
+}
+
+func TestBatchHasherLimits(t *testing.T) {
+	b, err := NewBatchHasher(BatchConfig{Workers: 8, MaxNativeMemory: 3 * nativeStateFootprint})
+	if err != nil {
+		t.Fatalf("NewBatchHasher failed: %v", err)
+	}
+	if b.Workers() != 3 {
+		t.Errorf("Workers() = %d, want 3 under the memory cap", b.Workers())
+	}
+
+	// A failing reader reports its error and leaves the hasher usable
+	failing := []io.Reader{iotest.ErrReader(errors.New("disk gone"))}
+	if _, err := b.HashReaders(failing); err == nil {
+		t.Error("Reader error should be returned")
+	}
+	if _, err := b.HashReaders([]io.Reader{bytes.NewReader([]byte("ok"))}); err != nil {
+		t.Errorf("HashReaders after error failed: %v", err)
+	}
+
+	b.Close()
+	if _, err := b.HashAll([][]byte{[]byte("x")}); err == nil {
+		t.Error("HashAll after Close should return error")
+	}
+
+	if _, err := NewBatchHasher(BatchConfig{MaxNativeMemory: 1}); err == nil {
+		t.Error("Tiny memory cap should return error")
+	}
+}
+
+func TestVerifyMACBatch(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	var items []MACItem
+	for i := 0; i < 50; i++ {
+		data := bytes.Repeat([]byte{byte(i)}, 10+i*100)
+		mac, _ := HashKeyed(data, key)
+		if i%7 == 3 {
+			mac[0] ^= 1
+		}
+		items = append(items, MACItem{Data: data, MAC: mac})
+	}
+	items = append(items, MACItem{Data: nil, MAC: make([]byte, 32)}, MACItem{Data: []byte("x"), MAC: []byte("short")})
+
+	ok, err := VerifyMACBatch(key, items)
+	if err != nil {
+		t.Fatalf("VerifyMACBatch failed: %v", err)
+	}
+	for i, item := range items {
+		want, _ := VerifyMAC(item.Data, key, item.MAC)
+		if ok[i] != want {
+			t.Errorf("item %d: got %v, want %v", i, ok[i], want)
+		}
+	}
+
+	if _, err := VerifyMACBatch([]byte("short"), items); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+	if ok, err := VerifyMACBatch(key, nil); err != nil || len(ok) != 0 {
+		t.Error("Empty batch should verify nothing")
+	}
+}
+
+func BenchmarkVerifyMACBatch(b *testing.B) {
+	key := bytes.Repeat([]byte("k"), 32)
+	items := make([]MACItem, 1000)
+	for i := range items {
+		items[i].Data = bytes.Repeat([]byte{byte(i)}, 256)
+		items[i].MAC, _ = HashKeyed(items[i].Data, key)
+	}
+	b.Run("Batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VerifyMACBatch(key, items)
+		}
+	})
+	b.Run("Loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				VerifyMAC(item.Data, key, item.MAC)
+			}
+		}
+	})
+}
@@ -0,0 +1,214 @@
+// Package bloom provides Bloom filters backed by seeded Tachyon hashes.
+//
+// The k bit positions of a key are taken from HashSeeded(key, seed+i) for
+// i in [0, k). All k hashes are computed in one native call via
+// tachyon.HashSeededMulti, so an insert or lookup costs a single cgo
+// transition regardless of k.
+//
+// Example:
+//
+//	f := bloom.NewWithEstimates(1_000_000, 0.01)
+//	f.Add([]byte("alice"))
+//	ok, _ := f.Test([]byte("alice")) // true
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"tachyon"
+)
+
+// ============================================================================
+// SIZING
+// ============================================================================
+
+// EstimateParameters returns the bit count m and hash count k for a filter
+// holding n items with false-positive rate fp.
+func EstimateParameters(n uint64, fp float64) (m uint64, k int) {
+	if n == 0 {
+		n = 1
+	}
+	if fp <= 0 || fp >= 1 {
+		fp = 0.01
+	}
+	mf := math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2))
+	kf := math.Round(mf / float64(n) * math.Ln2)
+	return uint64(mf), max(int(kf), 1)
+}
+
+// ============================================================================
+// POSITIONS
+// ============================================================================
+
+// hasher derives k filter positions from seeded Tachyon hashes.
+type hasher struct {
+	m     uint64
+	seeds []uint64
+}
+
+func newHasher(m uint64, k int, seed uint64) hasher {
+	seeds := make([]uint64, k)
+	for i := range seeds {
+		seeds[i] = seed + uint64(i)
+	}
+	return hasher{m: m, seeds: seeds}
+}
+
+// positions returns the k positions of key in [0, m).
+func (h hasher) positions(key []byte) ([]uint64, error) {
+	digests, err := tachyon.HashSeededMulti(key, h.seeds)
+	if err != nil {
+		return nil, err
+	}
+	pos := make([]uint64, len(digests))
+	for i := range digests {
+		pos[i] = binary.LittleEndian.Uint64(digests[i][:8]) % h.m
+	}
+	return pos, nil
+}
+
+func validate(m uint64, k int) error {
+	if m == 0 {
+		return errors.New("bloom: filter must have at least one slot")
+	}
+	if k < 1 {
+		return errors.New("bloom: k must be positive")
+	}
+	return nil
+}
+
+// ============================================================================
+// FILTER
+// ============================================================================
+
+// Filter is a classic Bloom filter.
+//
+// A Filter is not safe for concurrent use.
+type Filter struct {
+	h    hasher
+	bits []uint64
+}
+
+// New creates a filter with m bits, k hash functions and the given seed.
+//
+// Filters only agree on membership if they share m, k and seed.
+func New(m uint64, k int, seed uint64) (*Filter, error) {
+	if err := validate(m, k); err != nil {
+		return nil, err
+	}
+	return &Filter{h: newHasher(m, k, seed), bits: make([]uint64, (m+63)/64)}, nil
+}
+
+// NewWithEstimates creates a filter sized for n items at false-positive rate fp.
+func NewWithEstimates(n uint64, fp float64) *Filter {
+	m, k := EstimateParameters(n, fp)
+	f, _ := New(m, k, 0) // Parameters are always valid
+	return f
+}
+
+// Add inserts key into the filter.
+func (f *Filter) Add(key []byte) error {
+	pos, err := f.h.positions(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range pos {
+		f.bits[p/64] |= 1 << (p % 64)
+	}
+	return nil
+}
+
+// Test reports whether key may be in the filter.
+//
+// False positives are possible; false negatives are not.
+func (f *Filter) Test(key []byte) (bool, error) {
+	pos, err := f.h.positions(key)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range pos {
+		if f.bits[p/64]&(1<<(p%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Cap returns the number of bits in the filter.
+func (f *Filter) Cap() uint64 {
+	return f.h.m
+}
+
+// K returns the number of hash functions.
+func (f *Filter) K() int {
+	return len(f.h.seeds)
+}
+
+// ============================================================================
+// COUNTING FILTER
+// ============================================================================
+
+// CountingFilter is a Bloom filter with 8-bit counters that supports Remove.
+//
+// Counters saturate at 255 and are never decremented once saturated, so a
+// saturated slot can no longer produce a false negative. A CountingFilter is
+// not safe for concurrent use.
+type CountingFilter struct {
+	h        hasher
+	counters []uint8
+}
+
+// NewCounting creates a counting filter with m counters, k hash functions
+// and the given seed.
+func NewCounting(m uint64, k int, seed uint64) (*CountingFilter, error) {
+	if err := validate(m, k); err != nil {
+		return nil, err
+	}
+	return &CountingFilter{h: newHasher(m, k, seed), counters: make([]uint8, m)}, nil
+}
+
+// Add inserts key into the filter.
+func (f *CountingFilter) Add(key []byte) error {
+	pos, err := f.h.positions(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range pos {
+		if f.counters[p] < math.MaxUint8 {
+			f.counters[p]++
+		}
+	}
+	return nil
+}
+
+// Remove deletes one previous insertion of key.
+//
+// Removing a key that was never added corrupts the filter.
+func (f *CountingFilter) Remove(key []byte) error {
+	pos, err := f.h.positions(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range pos {
+		if f.counters[p] > 0 && f.counters[p] < math.MaxUint8 {
+			f.counters[p]--
+		}
+	}
+	return nil
+}
+
+// Test reports whether key may be in the filter.
+func (f *CountingFilter) Test(key []byte) (bool, error) {
+	pos, err := f.h.positions(key)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range pos {
+		if f.counters[p] == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
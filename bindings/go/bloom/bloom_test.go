@@ -0,0 +1,99 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilterNoFalseNegatives(t *testing.T) {
+	f := NewWithEstimates(1000, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		if err := f.Add([]byte(fmt.Sprintf("key-%d", i))); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		ok, err := f.Test([]byte(fmt.Sprintf("key-%d", i)))
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+		if !ok {
+			t.Fatalf("key-%d: inserted key not found", i)
+		}
+	}
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	f := NewWithEstimates(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(fmt.Sprintf("member-%d", i)))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if ok, _ := f.Test([]byte(fmt.Sprintf("absent-%d", i))); ok {
+			falsePositives++
+		}
+	}
+
+	// 1% target; allow generous slack for a small sample
+	if rate := float64(falsePositives) / 10000; rate > 0.03 {
+		t.Errorf("False-positive rate %.3f exceeds 0.03", rate)
+	}
+}
+
+func TestSeedIndependence(t *testing.T) {
+	a, _ := New(1024, 3, 1)
+	b, _ := New(1024, 3, 2)
+
+	pa, _ := a.h.positions([]byte("key"))
+	pb, _ := b.h.positions([]byte("key"))
+	same := true
+	for i := range pa {
+		same = same && pa[i] == pb[i]
+	}
+	if same {
+		t.Error("Different seeds should produce different positions")
+	}
+}
+
+func TestCountingFilter(t *testing.T) {
+	f, err := NewCounting(4096, 4, 0)
+	if err != nil {
+		t.Fatalf("NewCounting failed: %v", err)
+	}
+
+	f.Add([]byte("a"))
+	f.Add([]byte("b"))
+	f.Add([]byte("b"))
+
+	if ok, _ := f.Test([]byte("a")); !ok {
+		t.Error("Inserted key should be found")
+	}
+
+	f.Remove([]byte("a"))
+	if ok, _ := f.Test([]byte("a")); ok {
+		t.Error("Removed key should not be found")
+	}
+
+	// "b" was inserted twice, so one removal keeps it
+	f.Remove([]byte("b"))
+	if ok, _ := f.Test([]byte("b")); !ok {
+		t.Error("Key inserted twice should survive one removal")
+	}
+}
+
+func TestParameters(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	if m < 9000 || m > 10000 || k != 7 {
+		t.Errorf("EstimateParameters(1000, 0.01) = %d, %d; want ~9586, 7", m, k)
+	}
+
+	if _, err := New(0, 3, 0); err == nil {
+		t.Error("Zero size should return error")
+	}
+	if _, err := NewCounting(10, 0, 0); err == nil {
+		t.Error("Zero k should return error")
+	}
+}
@@ -0,0 +1,292 @@
+// Package cas provides a filesystem-backed content-addressed blob store.
+//
+// Blobs are identified by their Tachyon digest under DomainContentAddressed
+// and are verified again every time they are read back. Stores opened with
+// OpenKeyed identify blobs by a tenant-keyed digest instead, so identifiers
+// cannot be computed (or probed for) without the tenant key.
+//
+// Example:
+//
+//	store, err := cas.Open("/var/lib/blobs")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	d, err := store.Put(strings.NewReader("Hello, World!"))
+//	rc, err := store.Get(d)
+//	defer rc.Close()
+package cas
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrNotFound is returned when a blob is not present in the store.
+	ErrNotFound = errors.New("cas: blob not found")
+
+	// ErrCorrupt is returned when a blob's content no longer matches its digest.
+	ErrCorrupt = errors.New("cas: blob content does not match digest")
+
+	errHasher = errors.New("cas: could not create hasher")
+)
+
+// tenantContext is the DeriveKey context used to derive identifier keys
+// from tenant keys. Changing it changes every keyed identifier.
+const tenantContext = "tachyon-cas tenant identifier v1"
+
+// ============================================================================
+// STORE
+// ============================================================================
+
+// bufferSize is the read granularity used when streaming blobs.
+const bufferSize = 64 * 1024
+
+// Store is a content-addressed blob store rooted at a directory.
+//
+// Objects are laid out as <root>/<2 hex chars>/<62 hex chars>. Writes go
+// through <root>/tmp and are renamed into place, so readers never observe
+// partially written blobs. A Store is safe for concurrent use.
+type Store struct {
+	root  string
+	idKey []byte // nil for unkeyed stores
+}
+
+// Open opens (and creates if necessary) a store rooted at dir.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "tmp"), 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{root: dir}, nil
+}
+
+// OpenKeyed opens a store whose blob identifiers are keyed by tenantKey.
+//
+// Identifiers are computed as HashKeyed(contentDigest, k), where k is derived
+// from the 32-byte tenantKey via DeriveKey. Identical content deduplicates
+// within a tenant, but another tenant cannot compute the identifier of a
+// known blob and therefore cannot test whether it is stored.
+func OpenKeyed(dir string, tenantKey []byte) (*Store, error) {
+	if len(tenantKey) != 32 {
+		return nil, errors.New("cas: tenant key must be 32 bytes")
+	}
+	idKey, err := tachyon.DeriveKey(tenantContext, tenantKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.idKey = idKey
+	return s, nil
+}
+
+// Keyed reports whether the store uses tenant-keyed identifiers.
+func (s *Store) Keyed() bool {
+	return s.idKey != nil
+}
+
+// Root returns the directory the store is rooted at.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Put stores the content of r and returns its digest.
+//
+// Storing content that is already present is a no-op apart from reading r.
+func (s *Store) Put(r io.Reader) (tachyon.Digest, error) {
+	var d tachyon.Digest
+
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "put-*")
+	if err != nil {
+		return d, err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op once renamed into place
+
+	hasher := tachyon.NewHasherWithDomain(tachyon.DomainContentAddressed)
+	if hasher == nil {
+		tmp.Close()
+		return d, errHasher
+	}
+	defer hasher.Close()
+
+	buf := make([]byte, bufferSize)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if err := hasher.Update(buf[:n]); err != nil {
+				tmp.Close()
+				return d, err
+			}
+			if _, err := tmp.Write(buf[:n]); err != nil {
+				tmp.Close()
+				return d, err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			tmp.Close()
+			return d, rerr
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return d, err
+	}
+	if err := tmp.Close(); err != nil {
+		return d, err
+	}
+
+	sum, err := hasher.Finalize()
+	if err != nil {
+		return d, err
+	}
+	if d, err = s.identify(sum); err != nil {
+		return d, err
+	}
+
+	path := s.path(d)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return d, err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return d, err
+	}
+	return d, nil
+}
+
+// Get opens the blob identified by d.
+//
+// The returned reader verifies the content while it is consumed: the final
+// Read returns ErrCorrupt instead of io.EOF if the data on disk no longer
+// hashes to d. Callers must Close the reader.
+func (s *Store) Get(d tachyon.Digest) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := tachyon.NewHasherWithDomain(tachyon.DomainContentAddressed)
+	if hasher == nil {
+		f.Close()
+		return nil, errHasher
+	}
+	return &verifyingReader{f: f, hasher: hasher, store: s, want: d}, nil
+}
+
+// Has reports whether the blob identified by d is present.
+//
+// Has does not verify the blob's content; use Get for that.
+func (s *Store) Has(d tachyon.Digest) (bool, error) {
+	_, err := os.Stat(s.path(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete removes the blob identified by d.
+//
+// Returns ErrNotFound if the blob is not present.
+func (s *Store) Delete(d tachyon.Digest) error {
+	err := os.Remove(s.path(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// identify converts a content digest into the blob identifier.
+func (s *Store) identify(sum []byte) (tachyon.Digest, error) {
+	var d tachyon.Digest
+	if s.idKey == nil {
+		copy(d[:], sum)
+		return d, nil
+	}
+	id, err := tachyon.HashKeyed(sum, s.idKey)
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], id)
+	return d, nil
+}
+
+// path returns the on-disk location of the blob identified by d.
+func (s *Store) path(d tachyon.Digest) string {
+	name := d.String()
+	return filepath.Join(s.root, name[:2], name[2:])
+}
+
+// ============================================================================
+// VERIFYING READER
+// ============================================================================
+
+// verifyingReader hashes blob content as it is read and checks the digest at EOF.
+type verifyingReader struct {
+	f      *os.File
+	hasher *tachyon.Hasher
+	store  *Store
+	want   tachyon.Digest
+	err    error
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+
+	n, err := v.f.Read(p)
+	if n > 0 {
+		if uerr := v.hasher.Update(p[:n]); uerr != nil {
+			v.err = uerr
+			return n, uerr
+		}
+	}
+	if err == io.EOF {
+		sum, ferr := v.hasher.Finalize()
+		var got tachyon.Digest
+		if ferr == nil {
+			got, ferr = v.store.identify(sum)
+		}
+		switch {
+		case ferr != nil:
+			v.err = ferr
+		case subtle.ConstantTimeCompare(got[:], v.want[:]) != 1:
+			v.err = ErrCorrupt
+		default:
+			v.err = io.EOF
+		}
+		return n, v.err
+	}
+	if err != nil {
+		v.err = err
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	v.hasher.Close()
+	return v.f.Close()
+}
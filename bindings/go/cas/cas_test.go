@@ -0,0 +1,164 @@
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"tachyon"
+)
+
+func TestPutGet(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("blob"), 50000) // Spans several read buffers
+	d, err := store.Put(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Digest is the content-addressed domain hash
+	want, _ := tachyon.HashWithDomain(data, tachyon.DomainContentAddressed)
+	if !bytes.Equal(d[:], want) {
+		t.Error("Put should return the DomainContentAddressed digest")
+	}
+
+	rc, err := store.Get(d)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("Reading blob failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("Get should return the stored content")
+	}
+
+	// Storing the same content again yields the same digest
+	again, err := store.Put(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Second Put failed: %v", err)
+	}
+	if again != d {
+		t.Error("Same content should produce same digest")
+	}
+}
+
+func TestHasDelete(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	d, err := store.Put(bytes.NewReader([]byte("short-lived")))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	ok, err := store.Has(d)
+	if err != nil || !ok {
+		t.Fatalf("Has = %v, %v; want true, nil", ok, err)
+	}
+
+	if err := store.Delete(d); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	ok, _ = store.Has(d)
+	if ok {
+		t.Error("Deleted blob should not be present")
+	}
+	if err := store.Delete(d); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Second Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(d); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCorruptionDetected(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	d, err := store.Put(bytes.NewReader([]byte("original content")))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Tamper with the blob on disk
+	if err := os.WriteFile(store.path(d), []byte("tampered content"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	rc, err := store.Get(d)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer rc.Close()
+
+	if _, err := io.ReadAll(rc); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Reading tampered blob = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestKeyedStore(t *testing.T) {
+	dir := t.TempDir()
+	keyA := bytes.Repeat([]byte("a"), 32)
+	keyB := bytes.Repeat([]byte("b"), 32)
+
+	tenantA, err := OpenKeyed(dir, keyA)
+	if err != nil {
+		t.Fatalf("OpenKeyed failed: %v", err)
+	}
+	tenantB, err := OpenKeyed(dir, keyB)
+	if err != nil {
+		t.Fatalf("OpenKeyed failed: %v", err)
+	}
+	plain, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	data := []byte("tenant chunk")
+	idA, err := tenantA.Put(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Dedup works within the tenant
+	idA2, _ := tenantA.Put(bytes.NewReader(data))
+	if idA != idA2 {
+		t.Error("Same tenant and content should produce same identifier")
+	}
+
+	// Identifiers differ across tenants and from the unkeyed digest
+	idB, _ := tenantB.Put(bytes.NewReader(data))
+	idPlain, _ := plain.Put(bytes.NewReader(data))
+	if idA == idB || idA == idPlain {
+		t.Error("Keyed identifiers should differ across tenants and from plain digests")
+	}
+
+	// Keyed blobs verify on read
+	rc, err := tenantA.Get(idA)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Keyed Get = %q, %v; want %q, nil", got, err, data)
+	}
+
+	if _, err := OpenKeyed(dir, []byte("short")); err == nil {
+		t.Error("Wrong tenant key size should return error")
+	}
+}
@@ -0,0 +1,232 @@
+// Package chunker implements content-defined chunking for deduplication.
+//
+// Chunk boundaries are found with FastCDC-style normalized chunking over a
+// Gear rolling hash whose table is derived from Tachyon with a caller-chosen
+// seed. Every emitted chunk carries its Tachyon digest (DomainContentAddressed),
+// so chunk IDs match the identifiers used by the cas package.
+//
+// Example:
+//
+//	c, err := chunker.New(f, chunker.Options{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for {
+//	    chunk, err := c.Next()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    fmt.Printf("%d +%d %s\n", chunk.Offset, len(chunk.Data), chunk.Digest)
+//	}
+package chunker
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+
+	"tachyon"
+)
+
+// ============================================================================
+// OPTIONS
+// ============================================================================
+
+// Default chunk size bounds.
+const (
+	DefaultMinSize = 2 * 1024
+	DefaultAvgSize = 8 * 1024
+	DefaultMaxSize = 64 * 1024
+)
+
+// Options configures chunk size bounds and the Gear table seed.
+//
+// Zero sizes are replaced by the defaults. Boundaries are only stable for a
+// fixed set of options: changing any of them (including Seed) reshuffles the
+// chunking of every input.
+type Options struct {
+	MinSize int    // Smallest chunk emitted (except the final one)
+	AvgSize int    // Target average chunk size
+	MaxSize int    // Largest chunk emitted
+	Seed    uint64 // Seed for the Tachyon-derived Gear table
+}
+
+func (o Options) withDefaults() Options {
+	if o.MinSize == 0 {
+		o.MinSize = DefaultMinSize
+	}
+	if o.AvgSize == 0 {
+		o.AvgSize = DefaultAvgSize
+	}
+	if o.MaxSize == 0 {
+		o.MaxSize = DefaultMaxSize
+	}
+	return o
+}
+
+func (o Options) validate() error {
+	if o.MinSize < 64 || o.MinSize >= o.AvgSize || o.AvgSize >= o.MaxSize {
+		return errors.New("chunker: sizes must satisfy 64 <= MinSize < AvgSize < MaxSize")
+	}
+	return nil
+}
+
+// ============================================================================
+// CHUNKER
+// ============================================================================
+
+// Chunk is a content-defined slice of the input stream.
+type Chunk struct {
+	Offset int64          // Position of the chunk in the input stream
+	Data   []byte         // Chunk content; only valid until the next call to Next
+	Digest tachyon.Digest // Tachyon digest of Data (DomainContentAddressed)
+}
+
+// Chunker splits a stream into content-defined chunks.
+type Chunker struct {
+	r      io.Reader
+	opts   Options
+	gear   [256]uint64
+	maskS  uint64 // Stricter mask used before AvgSize
+	maskL  uint64 // Looser mask used after AvgSize
+	buf    []byte
+	start  int
+	end    int
+	offset int64
+	eof    bool
+}
+
+// New returns a Chunker reading from r.
+func New(r io.Reader, opts Options) (*Chunker, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	c := &Chunker{
+		r:    r,
+		opts: opts,
+		buf:  make([]byte, 2*opts.MaxSize),
+	}
+	if err := fillGear(&c.gear, opts.Seed); err != nil {
+		return nil, err
+	}
+
+	// Normalized chunking: one extra mask bit below the average size, one
+	// fewer above it. High bits are used because they depend on the widest
+	// window of recent bytes.
+	avgBits := bits.Len(uint(opts.AvgSize)) - 1
+	c.maskS = highBits(avgBits + 1)
+	c.maskL = highBits(avgBits - 1)
+	return c, nil
+}
+
+// Next returns the next chunk, or io.EOF once the input is exhausted.
+//
+// Chunk.Data aliases the chunker's internal buffer; copy it if it must
+// outlive the next call.
+func (c *Chunker) Next() (Chunk, error) {
+	if err := c.fill(); err != nil {
+		return Chunk{}, err
+	}
+	if c.start == c.end {
+		return Chunk{}, io.EOF
+	}
+
+	n := c.cut(c.buf[c.start:c.end])
+	data := c.buf[c.start : c.start+n]
+	sum, err := tachyon.HashWithDomain(data, tachyon.DomainContentAddressed)
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	chunk := Chunk{Offset: c.offset, Data: data}
+	copy(chunk.Digest[:], sum)
+	c.start += n
+	c.offset += int64(n)
+	return chunk, nil
+}
+
+// fill tops up the buffer so at least MaxSize bytes are available (or the
+// rest of the input, whichever is smaller).
+func (c *Chunker) fill() error {
+	if c.eof || c.end-c.start >= c.opts.MaxSize {
+		return nil
+	}
+
+	// Slide pending bytes to the front of the buffer
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut returns the length of the next chunk at the front of data.
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.opts.MinSize {
+		return n
+	}
+	if n > c.opts.MaxSize {
+		n = c.opts.MaxSize
+	}
+	normal := c.opts.AvgSize
+	if normal > n {
+		normal = n
+	}
+
+	var fp uint64
+	i := c.opts.MinSize
+	for ; i < normal; i++ {
+		fp = (fp << 1) + c.gear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + c.gear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// ============================================================================
+// GEAR TABLE
+// ============================================================================
+
+// fillGear derives the 256-entry Gear table from seeded Tachyon hashes.
+//
+// Each 32-byte hash of the block index yields four table entries.
+func fillGear(gear *[256]uint64, seed uint64) error {
+	var idx [8]byte
+	for block := 0; block < len(gear)/4; block++ {
+		binary.LittleEndian.PutUint64(idx[:], uint64(block))
+		sum, err := tachyon.HashSeeded(idx[:], seed)
+		if err != nil {
+			return err
+		}
+		for j := 0; j < 4; j++ {
+			gear[block*4+j] = binary.LittleEndian.Uint64(sum[j*8:])
+		}
+	}
+	return nil
+}
+
+// highBits returns a mask with the top n bits set.
+func highBits(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
@@ -0,0 +1,140 @@
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"tachyon"
+)
+
+func testData(size int, seed int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func split(t *testing.T, data []byte, opts Options) []Chunk {
+	t.Helper()
+	c, err := New(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var chunks []Chunk
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		chunk.Data = append([]byte(nil), chunk.Data...)
+		chunks = append(chunks, chunk)
+	}
+}
+
+func TestChunksReassemble(t *testing.T) {
+	data := testData(1<<20, 1)
+	chunks := split(t, data, Options{})
+
+	var joined []byte
+	var offset int64
+	for i, chunk := range chunks {
+		if chunk.Offset != offset {
+			t.Fatalf("chunk %d: Offset = %d, want %d", i, chunk.Offset, offset)
+		}
+		if len(chunk.Data) > DefaultMaxSize {
+			t.Errorf("chunk %d: size %d exceeds MaxSize", i, len(chunk.Data))
+		}
+		if i < len(chunks)-1 && len(chunk.Data) < DefaultMinSize {
+			t.Errorf("chunk %d: size %d below MinSize", i, len(chunk.Data))
+		}
+
+		want, _ := tachyon.HashWithDomain(chunk.Data, tachyon.DomainContentAddressed)
+		if !bytes.Equal(chunk.Digest[:], want) {
+			t.Errorf("chunk %d: digest mismatch", i)
+		}
+
+		joined = append(joined, chunk.Data...)
+		offset += int64(len(chunk.Data))
+	}
+
+	if !bytes.Equal(joined, data) {
+		t.Error("Chunks should reassemble to the input")
+	}
+	if len(chunks) < 32 {
+		t.Errorf("Got %d chunks for 1 MiB, expected roughly 1 MiB / AvgSize", len(chunks))
+	}
+}
+
+func TestBoundariesSurviveInsertion(t *testing.T) {
+	data := testData(512*1024, 2)
+	shifted := append([]byte("a few inserted bytes"), data...)
+
+	before := make(map[tachyon.Digest]bool)
+	for _, chunk := range split(t, data, Options{}) {
+		before[chunk.Digest] = true
+	}
+
+	after := split(t, shifted, Options{})
+	shared := 0
+	for _, chunk := range after {
+		if before[chunk.Digest] {
+			shared++
+		}
+	}
+
+	// Only the chunks around the insertion point should change
+	if shared < len(after)-2 {
+		t.Errorf("Only %d of %d chunks survived a prefix insertion", shared, len(after))
+	}
+}
+
+func TestSeedChangesBoundaries(t *testing.T) {
+	data := testData(256*1024, 3)
+
+	a := split(t, data, Options{Seed: 1})
+	b := split(t, data, Options{Seed: 2})
+	again := split(t, data, Options{Seed: 1})
+
+	if len(a) != len(again) {
+		t.Fatal("Same seed should produce same chunking")
+	}
+	for i := range a {
+		if a[i].Digest != again[i].Digest {
+			t.Fatal("Same seed should produce same chunking")
+		}
+	}
+
+	same := len(a) == len(b)
+	for i := 0; same && i < len(a); i++ {
+		same = a[i].Digest == b[i].Digest
+	}
+	if same {
+		t.Error("Different seeds should produce different chunking")
+	}
+}
+
+func TestOptionsValidation(t *testing.T) {
+	bad := []Options{
+		{MinSize: 16, AvgSize: 1024, MaxSize: 4096},
+		{MinSize: 4096, AvgSize: 1024, MaxSize: 8192},
+		{MinSize: 1024, AvgSize: 8192, MaxSize: 8192},
+	}
+	for _, opts := range bad {
+		if _, err := New(bytes.NewReader(nil), opts); err == nil {
+			t.Errorf("New(%+v) should return error", opts)
+		}
+	}
+
+	c, err := New(bytes.NewReader(nil), Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := c.Next(); err != io.EOF {
+		t.Errorf("Next on empty input = %v, want io.EOF", err)
+	}
+}
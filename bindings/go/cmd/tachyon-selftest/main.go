@@ -0,0 +1,234 @@
+// Command tachyon-selftest validates the Tachyon hashing stack on a host.
+//
+// It runs the embedded test vectors (package tachyon/vectors, a superset of
+// the official suite) through the one-shot and streaming APIs, reports which
+// native backend was selected, runs a short throughput benchmark, and prints
+// a JSON report. The exit status is 0 when every check passed and 1
+// otherwise, so it can gate a rollout after kernel or microcode updates:
+//
+//	tachyon-selftest                  # JSON report on stdout
+//	tachyon-selftest -text            # human-readable summary
+//	tachyon-selftest -bench 0         # skip the benchmark
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"tachyon"
+	"tachyon/vectors"
+)
+
+// reportVersion is bumped when the report format changes incompatibly.
+const reportVersion = 1
+
+// ============================================================================
+// REPORT
+// ============================================================================
+
+// Report is the machine-readable result of a self-test run.
+type Report struct {
+	Version     int            `json:"version"`
+	Time        time.Time      `json:"time"`
+	GoVersion   string         `json:"go_version"`
+	OS          string         `json:"os"`
+	Arch        string         `json:"arch"`
+	NumCPU      int            `json:"num_cpu"`
+	Backend     string         `json:"backend"`
+	Accelerated bool           `json:"accelerated"`
+	Vectors     []VectorResult `json:"vectors"`
+	Benchmark   *BenchResult   `json:"benchmark,omitempty"`
+	Passed      bool           `json:"passed"`
+}
+
+// VectorResult is the outcome of one test vector.
+type VectorResult struct {
+	Name      string `json:"name"`
+	Length    int    `json:"length"`
+	Want      string `json:"want"`
+	Got       string `json:"got"`
+	Streaming string `json:"streaming"`
+	Passed    bool   `json:"passed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BenchResult is the outcome of the throughput benchmark.
+type BenchResult struct {
+	BufferBytes int     `json:"buffer_bytes"`
+	Iterations  int     `json:"iterations"`
+	Seconds     float64 `json:"seconds"`
+	MiBPerSec   float64 `json:"mib_per_sec"`
+}
+
+// ============================================================================
+// CHECKS
+// ============================================================================
+
+// checkVectors runs every embedded vector through Hash and a streaming
+// Hasher fed in uneven pieces (or the vector's own split points).
+func checkVectors() ([]VectorResult, error) {
+	vs, err := vectors.Embedded()
+	if err != nil {
+		return nil, fmt.Errorf("tachyon-selftest: loading embedded vectors: %w", err)
+	}
+
+	results := make([]VectorResult, 0, len(vs))
+	for _, v := range vs {
+		r := VectorResult{Name: v.Name, Length: len(v.Input), Want: hex.EncodeToString(v.Hash)}
+
+		oneShot := v
+		oneShot.Splits = nil
+		hash, err := vectors.Tachyon(oneShot)
+		if err != nil {
+			r.Error = err.Error()
+			results = append(results, r)
+			continue
+		}
+		r.Got = hex.EncodeToString(hash)
+
+		streaming := v
+		if streaming.Splits == nil {
+			streaming.Splits = unevenSplits(len(v.Input))
+		}
+		streamed, err := vectors.Tachyon(streaming)
+		if err != nil {
+			r.Error = err.Error()
+			results = append(results, r)
+			continue
+		}
+		r.Streaming = hex.EncodeToString(streamed)
+
+		r.Passed = r.Got == r.Want && r.Streaming == r.Want
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// unevenSplits cuts n bytes into pieces of 1, 4, 13, 40, ... bytes.
+func unevenSplits(n int) []int {
+	splits := []int{}
+	for step := 1; n > 0; step = step*3 + 1 {
+		splits = append(splits, min(step, n))
+		n -= splits[len(splits)-1]
+	}
+	return splits
+}
+
+// benchBufferSize is large enough to exercise the parallel Merkle path.
+const benchBufferSize = 4 << 20
+
+// bench hashes a buffer repeatedly for about d.
+func bench(d time.Duration) (*BenchResult, error) {
+	buf := make([]byte, benchBufferSize)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+
+	var iterations int
+	start := time.Now()
+	for iterations == 0 || time.Since(start) < d {
+		if _, err := tachyon.Hash(buf); err != nil {
+			return nil, err
+		}
+		iterations++
+	}
+	elapsed := time.Since(start).Seconds()
+
+	return &BenchResult{
+		BufferBytes: benchBufferSize,
+		Iterations:  iterations,
+		Seconds:     elapsed,
+		MiBPerSec:   float64(iterations) * benchBufferSize / (1 << 20) / elapsed,
+	}, nil
+}
+
+// run performs the self-test. A zero benchFor skips the benchmark.
+func run(benchFor time.Duration) (*Report, error) {
+	report := &Report{
+		Version:     reportVersion,
+		Time:        time.Now().UTC(),
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		NumCPU:      runtime.NumCPU(),
+		Backend:     tachyon.BackendName(),
+		Accelerated: tachyon.Accelerated(),
+	}
+
+	vectors, err := checkVectors()
+	if err != nil {
+		return nil, err
+	}
+	report.Vectors = vectors
+	report.Passed = len(vectors) > 0
+	for _, v := range vectors {
+		report.Passed = report.Passed && v.Passed
+	}
+
+	if benchFor > 0 {
+		report.Benchmark, err = bench(benchFor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// ============================================================================
+// OUTPUT
+// ============================================================================
+
+func writeText(w io.Writer, r *Report) {
+	fmt.Fprintf(w, "host:     %s/%s, %d CPUs, %s\n", r.OS, r.Arch, r.NumCPU, r.GoVersion)
+	fmt.Fprintf(w, "backend:  %s (accelerated: %v)\n", r.Backend, r.Accelerated)
+	for _, v := range r.Vectors {
+		status := "ok"
+		if !v.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "vector:   %-24s %8d bytes  %s\n", v.Name, v.Length, status)
+		if !v.Passed {
+			fmt.Fprintf(w, "          want %s\n          got  %s\n          streaming %s\n", v.Want, v.Got, v.Streaming)
+			if v.Error != "" {
+				fmt.Fprintf(w, "          error: %s\n", v.Error)
+			}
+		}
+	}
+	if b := r.Benchmark; b != nil {
+		fmt.Fprintf(w, "bench:    %.0f MiB/s (%d x %d bytes in %.2fs)\n", b.MiBPerSec, b.Iterations, b.BufferBytes, b.Seconds)
+	}
+	if r.Passed {
+		fmt.Fprintln(w, "result:   PASS")
+	} else {
+		fmt.Fprintln(w, "result:   FAIL")
+	}
+}
+
+func main() {
+	text := flag.Bool("text", false, "print a human-readable summary instead of JSON")
+	benchFor := flag.Duration("bench", 500*time.Millisecond, "benchmark duration (0 to skip)")
+	flag.Parse()
+
+	report, err := run(*benchFor)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *text {
+		writeText(os.Stdout, report)
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	}
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
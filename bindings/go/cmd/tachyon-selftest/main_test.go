@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"tachyon/vectors"
+)
+
+func TestRun(t *testing.T) {
+	report, err := run(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !report.Passed {
+		for _, v := range report.Vectors {
+			if !v.Passed {
+				t.Errorf("Vector %s: got %s, streaming %s, want %s", v.Name, v.Got, v.Streaming, v.Want)
+			}
+		}
+		t.Fatal("Self-test should pass")
+	}
+	if all, _ := vectors.Embedded(); len(report.Vectors) != len(all) || len(all) < 9 {
+		t.Errorf("Ran %d vectors, want all %d", len(report.Vectors), len(all))
+	}
+	if report.Backend == "" {
+		t.Error("Report should name the backend")
+	}
+	if report.Benchmark == nil || report.Benchmark.Iterations == 0 || report.Benchmark.MiBPerSec <= 0 {
+		t.Errorf("Benchmark = %+v", report.Benchmark)
+	}
+
+	// The JSON report round-trips
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Passed || decoded.Version != reportVersion {
+		t.Errorf("Report did not round-trip: %v", err)
+	}
+
+	var text bytes.Buffer
+	writeText(&text, report)
+	if !strings.Contains(text.String(), "result:   PASS") {
+		t.Errorf("Text report missing result:\n%s", text.String())
+	}
+}
+
+func TestRunSkipsBenchmark(t *testing.T) {
+	report, err := run(0)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if report.Benchmark != nil {
+		t.Error("Zero duration should skip the benchmark")
+	}
+}
@@ -0,0 +1,55 @@
+package tachyon
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// ============================================================================
+// COMMITTING MAC
+// ============================================================================
+
+// committingMACLabel separates committing tags from all other hashes.
+const committingMACLabel = "tachyon committing mac v1\x00"
+
+// CommittedMAC computes a key-committing MAC of data: a 32-byte tag that
+// verifies under exactly one key.
+//
+// A plain MAC only guarantees that nobody without the key can forge a tag.
+// In multi-recipient protocols, a sender knowing several keys could craft one
+// tag valid under all of them; a committing tag rules this out, since two
+// keys validating the same tag would give a Tachyon collision:
+//
+//	tag = Hash("tachyon committing mac v1" || 0x00 || key || HashKeyed(data, key))
+//
+// key must be 32 bytes and data must not be empty, as for HashKeyed.
+func CommittedMAC(data, key []byte) ([]byte, error) {
+	mac, err := HashKeyed(data, key)
+	if err != nil {
+		return nil, err
+	}
+
+	input := make([]byte, 0, len(committingMACLabel)+len(key)+len(mac))
+	input = append(input, committingMACLabel...)
+	input = append(input, key...)
+	input = append(input, mac...)
+	defer clear(input)
+
+	var tag Digest
+	if err := hashFull(input, 0, 0, nil, &tag); err != nil {
+		return nil, err
+	}
+	return tag[:], nil
+}
+
+// VerifyCommitted verifies a tag produced by CommittedMAC in constant time.
+func VerifyCommitted(data, key, tag []byte) (bool, error) {
+	if len(tag) != 32 {
+		return false, errors.New("tachyon: expected tag must be 32 bytes")
+	}
+	want, err := CommittedMAC(data, key)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(want, tag) == 1, nil
+}
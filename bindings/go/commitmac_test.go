@@ -0,0 +1,42 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommittedMAC(t *testing.T) {
+	data := []byte("multi-recipient message")
+	key := bytes.Repeat([]byte("a"), 32)
+	other := bytes.Repeat([]byte("b"), 32)
+
+	tag, err := CommittedMAC(data, key)
+	if err != nil {
+		t.Fatalf("CommittedMAC failed: %v", err)
+	}
+	if len(tag) != 32 {
+		t.Fatalf("tag length = %d, want 32", len(tag))
+	}
+	mac, _ := HashKeyed(data, key)
+	if bytes.Equal(tag, mac) {
+		t.Error("Committed tag should differ from the plain MAC")
+	}
+
+	ok, err := VerifyCommitted(data, key, tag)
+	if err != nil || !ok {
+		t.Errorf("VerifyCommitted = %v, %v; want true", ok, err)
+	}
+	if ok, _ := VerifyCommitted(data, other, tag); ok {
+		t.Error("Tag should not verify under another key")
+	}
+	if ok, _ := VerifyCommitted([]byte("other message"), key, tag); ok {
+		t.Error("Tag should not verify for other data")
+	}
+
+	if _, err := VerifyCommitted(data, key, tag[:16]); err == nil {
+		t.Error("Short tag should return error")
+	}
+	if _, err := CommittedMAC(data, []byte("short")); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+}
@@ -0,0 +1,326 @@
+// Package dedup provides a compact, concurrency-safe set of Tachyon digests
+// for deduplicating ingested content.
+//
+// By default an Index keys on the first 16 bytes of each digest, halving
+// memory against full digests. Accidental collisions of a 128-bit prefix are
+// negligible (about n²/2^129 for n entries), but a determined attacker can
+// find one with roughly 2^64 work; services deduplicating untrusted content
+// should set Options.Confirm so membership is confirmed on the full digest.
+//
+// Example:
+//
+//	idx := dedup.New(dedup.Options{Confirm: true})
+//	if idx.Add(digest) {
+//	    store(blob) // First time we see this content
+//	}
+//	idx.Save("seen.tdx")
+package dedup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"tachyon"
+)
+
+// ============================================================================
+// INDEX
+// ============================================================================
+
+// half is one 16-byte half of a digest.
+type half [tachyon.DigestSize / 2]byte
+
+// shardCount spreads entries over independently locked maps.
+const shardCount = 64
+
+// Options configures an Index.
+type Options struct {
+	// Confirm stores the full digest and confirms membership on all 32
+	// bytes, at twice the memory per entry.
+	Confirm bool
+}
+
+// Index is a set of digests. An Index is safe for concurrent use.
+type Index struct {
+	confirm bool
+	shards  [shardCount]shard
+}
+
+type shard struct {
+	mu sync.RWMutex
+
+	// keys holds prefixes in truncated mode. tails maps prefix → suffix in
+	// Confirm mode, with digests sharing a stored prefix kept in overflow.
+	keys     map[half]struct{}
+	tails    map[half]half
+	overflow map[tachyon.Digest]struct{}
+}
+
+// New creates an empty index.
+func New(opts Options) *Index {
+	x := &Index{confirm: opts.Confirm}
+	for i := range x.shards {
+		if x.confirm {
+			x.shards[i].tails = make(map[half]half)
+		} else {
+			x.shards[i].keys = make(map[half]struct{})
+		}
+	}
+	return x
+}
+
+// split returns the shard, prefix and suffix of d.
+func (x *Index) split(d *tachyon.Digest) (*shard, half, half) {
+	var prefix, suffix half
+	copy(prefix[:], d[:len(prefix)])
+	copy(suffix[:], d[len(prefix):])
+	return &x.shards[d[0]%shardCount], prefix, suffix
+}
+
+// Add inserts d and reports whether it was not already present.
+func (x *Index) Add(d tachyon.Digest) bool {
+	s, prefix, suffix := x.split(&d)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !x.confirm {
+		if _, ok := s.keys[prefix]; ok {
+			return false
+		}
+		s.keys[prefix] = struct{}{}
+		return true
+	}
+
+	tail, ok := s.tails[prefix]
+	switch {
+	case !ok:
+		s.tails[prefix] = suffix
+		return true
+	case tail == suffix:
+		return false
+	}
+	if _, ok := s.overflow[d]; ok {
+		return false
+	}
+	if s.overflow == nil {
+		s.overflow = make(map[tachyon.Digest]struct{})
+	}
+	s.overflow[d] = struct{}{}
+	return true
+}
+
+// Contains reports whether d is in the index.
+//
+// Without Options.Confirm, any digest sharing the first 16 bytes of a stored
+// digest is reported as present.
+func (x *Index) Contains(d tachyon.Digest) bool {
+	s, prefix, suffix := x.split(&d)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !x.confirm {
+		_, ok := s.keys[prefix]
+		return ok
+	}
+	if tail, ok := s.tails[prefix]; ok && tail == suffix {
+		return true
+	}
+	_, ok := s.overflow[d]
+	return ok
+}
+
+// Len returns the number of entries.
+func (x *Index) Len() int {
+	n := 0
+	for i := range x.shards {
+		s := &x.shards[i]
+		s.mu.RLock()
+		n += len(s.keys) + len(s.tails) + len(s.overflow)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Confirm reports whether the index confirms full digests.
+func (x *Index) Confirm() bool {
+	return x.confirm
+}
+
+// entries returns all entries, 16 or 32 bytes each, in sorted order.
+func (x *Index) entries() [][]byte {
+	var out [][]byte
+	for i := range x.shards {
+		s := &x.shards[i]
+		s.mu.RLock()
+		for k := range s.keys {
+			out = append(out, append([]byte(nil), k[:]...))
+		}
+		for k, v := range s.tails {
+			out = append(out, append(append([]byte(nil), k[:]...), v[:]...))
+		}
+		for d := range s.overflow {
+			out = append(out, append([]byte(nil), d[:]...))
+		}
+		s.mu.RUnlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i], out[j]) < 0 })
+	return out
+}
+
+// ============================================================================
+// PERSISTENCE
+// ============================================================================
+
+// magic starts every index file.
+const magic = "TDDX1"
+
+// flagConfirm marks an index of full digests.
+const flagConfirm = 1
+
+// ErrCorrupt is returned when an index file fails its checksum.
+var ErrCorrupt = errors.New("dedup: index checksum mismatch")
+
+// Index file layout (little-endian):
+//
+//	magic "TDDX1" | flags u8 | count u64 | entries | checksum [32]
+//
+// Entries are 16-byte prefixes, or full digests with flagConfirm, in
+// ascending order, so equal sets serialize identically. The checksum is
+// HashWithDomain of everything before it under DomainFileChecksum.
+
+// WriteTo writes the index to w. Entries added concurrently may or may not
+// be included.
+func (x *Index) WriteTo(w io.Writer) (int64, error) {
+	entries := x.entries()
+	var flags byte
+	if x.confirm {
+		flags |= flagConfirm
+	}
+
+	h := tachyon.NewHasherWithDomain(tachyon.DomainFileChecksum)
+	defer h.Close()
+	bw := bufio.NewWriter(io.MultiWriter(w, hashWriter{h}))
+
+	header := append([]byte(magic), flags)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(entries)))
+	bw.Write(header)
+	for _, e := range entries {
+		bw.Write(e)
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	sum, err := h.Finalize()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(sum); err != nil {
+		return 0, err
+	}
+
+	width := len(half{})
+	if x.confirm {
+		width = tachyon.DigestSize
+	}
+	return int64(len(header) + len(entries)*width + len(sum)), nil
+}
+
+// Read reads an index written by WriteTo.
+func Read(r io.Reader) (*Index, error) {
+	h := tachyon.NewHasherWithDomain(tachyon.DomainFileChecksum)
+	defer h.Close()
+	br := bufio.NewReader(r)
+	tr := io.TeeReader(br, hashWriter{h})
+
+	header := make([]byte, len(magic)+9)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return nil, fmt.Errorf("dedup: reading header: %w", err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("dedup: not an index file")
+	}
+	flags := header[len(magic)]
+	if flags&^flagConfirm != 0 {
+		return nil, fmt.Errorf("dedup: unknown flags %#x", flags)
+	}
+	count := binary.LittleEndian.Uint64(header[len(magic)+1:])
+
+	x := New(Options{Confirm: flags&flagConfirm != 0})
+	var d tachyon.Digest
+	entry := d[:len(half{})]
+	if x.confirm {
+		entry = d[:]
+	}
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(tr, entry); err != nil {
+			return nil, fmt.Errorf("dedup: reading entry %d: %w", i, err)
+		}
+		x.Add(d)
+	}
+
+	sum, err := h.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	var stored tachyon.Digest
+	if _, err := io.ReadFull(br, stored[:]); err != nil {
+		return nil, fmt.Errorf("dedup: reading checksum: %w", err)
+	}
+	if !bytes.Equal(sum, stored[:]) || uint64(x.Len()) != count {
+		return nil, ErrCorrupt
+	}
+	return x, nil
+}
+
+// Save writes the index to path.
+//
+// The file is written to a temporary file, synced and renamed into place,
+// so a crash leaves either the old or the new index intact.
+func (x *Index) Save(path string) error {
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := x.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Load reads an index saved with Save.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// hashWriter feeds writes into a Hasher.
+type hashWriter struct{ h *tachyon.Hasher }
+
+func (w hashWriter) Write(p []byte) (int, error) {
+	if err := w.h.Update(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,163 @@
+package dedup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"tachyon"
+)
+
+func digestOf(t testing.TB, s string) tachyon.Digest {
+	t.Helper()
+	d, err := tachyon.Sum([]byte(s))
+	if err != nil {
+		t.Fatalf("Sum failed: %v", err)
+	}
+	return d
+}
+
+func TestAddContains(t *testing.T) {
+	for _, confirm := range []bool{false, true} {
+		idx := New(Options{Confirm: confirm})
+		a, b := digestOf(t, "a"), digestOf(t, "b")
+
+		if !idx.Add(a) {
+			t.Error("First Add should report a new entry")
+		}
+		if idx.Add(a) {
+			t.Error("Second Add should report a duplicate")
+		}
+		if !idx.Contains(a) || idx.Contains(b) {
+			t.Error("Contains should report only added digests")
+		}
+		if idx.Len() != 1 {
+			t.Errorf("Len() = %d, want 1", idx.Len())
+		}
+	}
+}
+
+func TestPrefixCollision(t *testing.T) {
+	a := digestOf(t, "a")
+	b := a
+	b[31] ^= 1 // Same 16-byte prefix, different digest
+
+	truncated := New(Options{})
+	truncated.Add(a)
+	if !truncated.Contains(b) {
+		t.Error("Truncated index should match on the prefix")
+	}
+
+	confirmed := New(Options{Confirm: true})
+	confirmed.Add(a)
+	if confirmed.Contains(b) {
+		t.Error("Confirming index should reject a prefix collision")
+	}
+	if !confirmed.Add(b) || confirmed.Add(b) || !confirmed.Contains(b) || !confirmed.Contains(a) {
+		t.Error("Colliding digests should both be stored")
+	}
+	if confirmed.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", confirmed.Len())
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	idx := New(Options{Confirm: true})
+	var wg sync.WaitGroup
+	var added [8]int
+	for w := range added {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if idx.Add(digestOf(t, fmt.Sprint(i))) {
+					added[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range added {
+		total += n
+	}
+	if total != 500 || idx.Len() != 500 {
+		t.Errorf("added %d, Len() = %d, want 500 each", total, idx.Len())
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	for _, confirm := range []bool{false, true} {
+		idx := New(Options{Confirm: confirm})
+		for i := 0; i < 1000; i++ {
+			idx.Add(digestOf(t, fmt.Sprint(i)))
+		}
+
+		path := filepath.Join(t.TempDir(), "seen.tdx")
+		if err := idx.Save(path); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if loaded.Len() != 1000 || loaded.Confirm() != confirm {
+			t.Fatalf("loaded Len() = %d, Confirm() = %v", loaded.Len(), loaded.Confirm())
+		}
+		for i := 0; i < 1000; i++ {
+			if !loaded.Contains(digestOf(t, fmt.Sprint(i))) {
+				t.Fatalf("entry %d missing after Load", i)
+			}
+		}
+
+		var a, b bytes.Buffer
+		idx.WriteTo(&a)
+		loaded.WriteTo(&b)
+		if !bytes.Equal(a.Bytes(), b.Bytes()) {
+			t.Error("Equal sets should serialize identically")
+		}
+	}
+}
+
+func TestReadCorrupt(t *testing.T) {
+	idx := New(Options{})
+	idx.Add(digestOf(t, "a"))
+	idx.Add(digestOf(t, "b"))
+	var buf bytes.Buffer
+	n, err := idx.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+	}
+	wire := buf.Bytes()
+
+	bad := append([]byte(nil), wire...)
+	bad[20] ^= 1
+	if _, err := Read(bytes.NewReader(bad)); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("err = %v, want ErrCorrupt", err)
+	}
+	for i := range wire {
+		if _, err := Read(bytes.NewReader(wire[:i])); err == nil {
+			t.Fatalf("Read of %d-byte prefix should fail", i)
+		}
+	}
+	if _, err := Read(bytes.NewReader([]byte("NOTIDX"))); err == nil {
+		t.Error("Bad magic should return error")
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	idx := New(Options{})
+	var d tachyon.Digest
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d[0], d[1], d[2], d[3] = byte(i), byte(i>>8), byte(i>>16), byte(i>>24)
+		idx.Add(d)
+	}
+}
@@ -0,0 +1,465 @@
+// Package delta implements rsync-style delta transfer with Tachyon as the
+// strong hash.
+//
+// The receiver of an update signs its current copy of a file (a weak rolling
+// checksum plus a 128-bit Tachyon digest per block), the sender diffs the new
+// version against that signature, and the receiver applies the resulting
+// delta to its old copy. Only the changed regions travel as literal data.
+//
+// Example:
+//
+//	sig, _ := delta.Sign(oldFile, delta.DefaultBlockSize) // On the receiver
+//	d, _ := delta.Diff(sig, newFile)                       // On the sender
+//	err := delta.Apply(oldFile, d, out)                    // On the receiver
+package delta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrMismatch is returned by Apply when the reconstructed file does not
+	// match the target digest, e.g. because the base changed since it was
+	// signed.
+	ErrMismatch = errors.New("delta: reconstructed file does not match target digest")
+
+	errMalformed = errors.New("delta: malformed encoding")
+)
+
+// ============================================================================
+// SIGNATURES
+// ============================================================================
+
+// DefaultBlockSize is a good block size for files of a few MB to a few GB.
+const DefaultBlockSize = 2048
+
+// maxBlockSize bounds the block size so a corrupt signature cannot trigger a
+// huge allocation.
+const maxBlockSize = 1 << 24
+
+// StrongSize is the size of the per-block strong digest.
+const StrongSize = tachyon.Size128
+
+// BlockSig is the signature of one block.
+type BlockSig struct {
+	Weak   uint32           // rsync rolling checksum
+	Strong [StrongSize]byte // Hash(block, WithDomain(DomainFileChecksum), WithOutputSize(Size128))
+}
+
+// Signature describes a base file block by block. The last block may be
+// shorter than BlockSize.
+type Signature struct {
+	BlockSize int
+	Size      int64 // Base file size
+	Blocks    []BlockSig
+}
+
+// Sign computes the signature of everything read from r.
+func Sign(r io.Reader, blockSize int) (*Signature, error) {
+	if blockSize < 1 || blockSize > maxBlockSize {
+		return nil, fmt.Errorf("delta: block size must be in [1, %d]", maxBlockSize)
+	}
+	sig := &Signature{BlockSize: blockSize}
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			strong, serr := strongSum(buf[:n])
+			if serr != nil {
+				return nil, serr
+			}
+			sig.Blocks = append(sig.Blocks, BlockSig{Weak: weakSum(buf[:n]), Strong: strong})
+			sig.Size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return sig, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// blockLen returns the length of block i.
+func (s *Signature) blockLen(i int) int {
+	return int(min(int64(s.BlockSize), s.Size-int64(i)*int64(s.BlockSize)))
+}
+
+// MarshalBinary encodes the signature.
+//
+// Layout (little-endian): "TDSG1" | blockSize u32 | size u64 | count u32 |
+// count × (weak u32 | strong [16]).
+func (s *Signature) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(sigMagic)+16+len(s.Blocks)*(4+StrongSize))
+	out = append(out, sigMagic...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(s.BlockSize))
+	out = binary.LittleEndian.AppendUint64(out, uint64(s.Size))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(s.Blocks)))
+	for _, b := range s.Blocks {
+		out = binary.LittleEndian.AppendUint32(out, b.Weak)
+		out = append(out, b.Strong[:]...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes a signature produced by MarshalBinary.
+func (s *Signature) UnmarshalBinary(data []byte) error {
+	if len(data) < len(sigMagic)+16 || string(data[:len(sigMagic)]) != sigMagic {
+		return errMalformed
+	}
+	data = data[len(sigMagic):]
+	blockSize := binary.LittleEndian.Uint32(data)
+	size := binary.LittleEndian.Uint64(data[4:])
+	count := binary.LittleEndian.Uint32(data[12:])
+	data = data[16:]
+	if blockSize < 1 || blockSize > maxBlockSize || uint64(len(data)) != uint64(count)*(4+StrongSize) ||
+		size > uint64(count)*uint64(blockSize) || (count > 0 && size <= uint64(count-1)*uint64(blockSize)) {
+		return errMalformed
+	}
+
+	s.BlockSize = int(blockSize)
+	s.Size = int64(size)
+	s.Blocks = make([]BlockSig, count)
+	for i := range s.Blocks {
+		s.Blocks[i].Weak = binary.LittleEndian.Uint32(data)
+		copy(s.Blocks[i].Strong[:], data[4:4+StrongSize])
+		data = data[4+StrongSize:]
+	}
+	return nil
+}
+
+const (
+	sigMagic   = "TDSG1"
+	deltaMagic = "TDDL1"
+)
+
+// weakSum is the rsync rolling checksum of block.
+func weakSum(block []byte) uint32 {
+	var a, b uint32
+	n := uint32(len(block))
+	for i, c := range block {
+		a += uint32(c)
+		b += (n - uint32(i)) * uint32(c)
+	}
+	return a&0xffff | b<<16
+}
+
+// roll slides a weak checksum over a window of n bytes by one byte.
+func roll(weak uint32, out, in byte, n int) uint32 {
+	a := weak & 0xffff
+	b := weak >> 16
+	a = (a - uint32(out) + uint32(in)) & 0xffff
+	b = (b - uint32(n)*uint32(out) + a) & 0xffff
+	return a | b<<16
+}
+
+func strongSum(block []byte) ([StrongSize]byte, error) {
+	var strong [StrongSize]byte
+	sum, err := tachyon.Hash(block, tachyon.WithDomain(tachyon.DomainFileChecksum), tachyon.WithOutputSize(StrongSize))
+	if err != nil {
+		return strong, err
+	}
+	copy(strong[:], sum)
+	return strong, nil
+}
+
+// ============================================================================
+// DELTAS
+// ============================================================================
+
+// Op is one instruction of a delta: either copy Count blocks of the base
+// starting at Block, or insert Data.
+type Op struct {
+	Block int64
+	Count int64
+	Data  []byte // Literal data; nil for a copy
+}
+
+// Delta rebuilds a target file from a base file.
+type Delta struct {
+	BlockSize  int
+	BaseSize   int64
+	TargetSize int64
+	Target     tachyon.Digest // Hash(target, WithDomain(DomainFileChecksum))
+	Ops        []Op
+}
+
+// Diff computes the delta from the file described by sig to everything read
+// from r. The new file is held in memory.
+func Diff(sig *Signature, r io.Reader) (*Delta, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	target, err := tachyon.Hash(data, tachyon.WithDomain(tachyon.DomainFileChecksum))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Delta{BlockSize: sig.BlockSize, BaseSize: sig.Size, TargetSize: int64(len(data)), Target: tachyon.Digest(target)}
+	bs := sig.BlockSize
+
+	// Index the full-size blocks by weak checksum
+	index := make(map[uint32][]int)
+	full := len(sig.Blocks)
+	if full > 0 && sig.blockLen(full-1) < bs {
+		full--
+	}
+	for i := 0; i < full; i++ {
+		index[sig.Blocks[i].Weak] = append(index[sig.Blocks[i].Weak], i)
+	}
+
+	match := func(window []byte, weak uint32, cands []int) (int, error) {
+		if len(cands) == 0 {
+			return -1, nil
+		}
+		strong, err := strongSum(window)
+		if err != nil {
+			return -1, err
+		}
+		for _, c := range cands {
+			if sig.Blocks[c].Weak == weak && sig.Blocks[c].Strong == strong {
+				return c, nil
+			}
+		}
+		return -1, nil
+	}
+
+	literal := 0
+	i := 0
+	var weak uint32
+	if len(data) >= bs {
+		weak = weakSum(data[:bs])
+	}
+	for i+bs <= len(data) {
+		block, err := match(data[i:i+bs], weak, index[weak])
+		if err != nil {
+			return nil, err
+		}
+		if block >= 0 {
+			d.addLiteral(data[literal:i])
+			d.addCopy(int64(block))
+			i += bs
+			literal = i
+			if i+bs <= len(data) {
+				weak = weakSum(data[i : i+bs])
+			}
+			continue
+		}
+		if i+bs < len(data) {
+			weak = roll(weak, data[i], data[i+bs], bs)
+		}
+		i++
+	}
+
+	// A short last block can only match at the very end of the new file
+	if full < len(sig.Blocks) {
+		last := len(sig.Blocks) - 1
+		n := sig.blockLen(last)
+		if start := len(data) - n; start >= literal {
+			tail := data[start:]
+			block, err := match(tail, weakSum(tail), []int{last})
+			if err != nil {
+				return nil, err
+			}
+			if block >= 0 {
+				d.addLiteral(data[literal:start])
+				d.addCopy(int64(block))
+				literal = len(data)
+			}
+		}
+	}
+	d.addLiteral(data[literal:])
+	return d, nil
+}
+
+func (d *Delta) addLiteral(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	if n := len(d.Ops); n > 0 && d.Ops[n-1].Data != nil {
+		d.Ops[n-1].Data = append(d.Ops[n-1].Data, data...)
+		return
+	}
+	d.Ops = append(d.Ops, Op{Data: append([]byte(nil), data...)})
+}
+
+func (d *Delta) addCopy(block int64) {
+	if n := len(d.Ops); n > 0 && d.Ops[n-1].Data == nil && d.Ops[n-1].Block+d.Ops[n-1].Count == block {
+		d.Ops[n-1].Count++
+		return
+	}
+	d.Ops = append(d.Ops, Op{Block: block, Count: 1})
+}
+
+// LiteralBytes returns the number of bytes the delta carries as literal data.
+func (d *Delta) LiteralBytes() int64 {
+	var n int64
+	for _, op := range d.Ops {
+		n += int64(len(op.Data))
+	}
+	return n
+}
+
+// Apply writes the target file to w, copying unchanged blocks from base.
+//
+// The output is verified against the target digest as it is written; on
+// ErrMismatch w has received the full (wrong) output, so write to a
+// temporary file and rename it into place only on success.
+func Apply(base io.ReaderAt, d *Delta, w io.Writer) error {
+	if d.BlockSize < 1 || d.BlockSize > maxBlockSize {
+		return errMalformed
+	}
+	h, err := tachyon.New(tachyon.WithDomain(tachyon.DomainFileChecksum))
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	out := io.MultiWriter(w, hashWriter{h})
+	var written int64
+	var buf []byte
+	for _, op := range d.Ops {
+		if op.Data != nil {
+			if _, err := out.Write(op.Data); err != nil {
+				return err
+			}
+			written += int64(len(op.Data))
+			continue
+		}
+
+		off := op.Block * int64(d.BlockSize)
+		if op.Block < 0 || op.Count < 1 || off >= d.BaseSize || op.Count > (d.BaseSize-off+int64(d.BlockSize)-1)/int64(d.BlockSize) {
+			return fmt.Errorf("delta: copy of blocks [%d, %d) outside the base", op.Block, op.Block+op.Count)
+		}
+		end := min(off+op.Count*int64(d.BlockSize), d.BaseSize)
+		for ; off < end; off += int64(len(buf)) {
+			buf = growBuf(buf, int(min(end-off, 32*int64(d.BlockSize))))
+			if n, err := base.ReadAt(buf, off); n < len(buf) {
+				return fmt.Errorf("delta: reading base at %d: %w", off, err)
+			}
+			if _, err := out.Write(buf); err != nil {
+				return err
+			}
+			written += int64(len(buf))
+		}
+	}
+
+	sum, err := h.Finalize()
+	if err != nil {
+		return err
+	}
+	if written != d.TargetSize || !bytes.Equal(sum, d.Target[:]) {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func growBuf(buf []byte, n int) []byte {
+	if cap(buf) < n {
+		return make([]byte, n)
+	}
+	return buf[:n]
+}
+
+// hashWriter adapts a Hasher to io.Writer.
+type hashWriter struct{ h *tachyon.Hasher }
+
+func (w hashWriter) Write(p []byte) (int, error) {
+	if err := w.h.Update(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MarshalBinary encodes the delta.
+//
+// Layout (little-endian): "TDDL1" | blockSize u32 | baseSize u64 |
+// targetSize u64 | target [32] | count u32 | count × op, where an op is
+// 0x01 | block u64 | count u64 (copy) or 0x02 | len u32 | data (literal).
+func (d *Delta) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(deltaMagic)+56+len(d.Ops)*17+int(d.LiteralBytes()))
+	out = append(out, deltaMagic...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(d.BlockSize))
+	out = binary.LittleEndian.AppendUint64(out, uint64(d.BaseSize))
+	out = binary.LittleEndian.AppendUint64(out, uint64(d.TargetSize))
+	out = append(out, d.Target[:]...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(d.Ops)))
+	for _, op := range d.Ops {
+		if op.Data != nil {
+			out = append(out, opLiteral)
+			out = binary.LittleEndian.AppendUint32(out, uint32(len(op.Data)))
+			out = append(out, op.Data...)
+			continue
+		}
+		out = append(out, opCopy)
+		out = binary.LittleEndian.AppendUint64(out, uint64(op.Block))
+		out = binary.LittleEndian.AppendUint64(out, uint64(op.Count))
+	}
+	return out, nil
+}
+
+const (
+	opCopy    = 0x01
+	opLiteral = 0x02
+)
+
+// UnmarshalBinary decodes a delta produced by MarshalBinary. Apply checks
+// the result, so decoding verifies only the structure.
+func (d *Delta) UnmarshalBinary(data []byte) error {
+	const header = 4 + 8 + 8 + 32 + 4
+	if len(data) < len(deltaMagic)+header || string(data[:len(deltaMagic)]) != deltaMagic {
+		return errMalformed
+	}
+	data = data[len(deltaMagic):]
+	d.BlockSize = int(binary.LittleEndian.Uint32(data))
+	d.BaseSize = int64(binary.LittleEndian.Uint64(data[4:]))
+	d.TargetSize = int64(binary.LittleEndian.Uint64(data[12:]))
+	copy(d.Target[:], data[20:52])
+	count := binary.LittleEndian.Uint32(data[52:])
+	data = data[header:]
+
+	d.Ops = make([]Op, 0, min(int(count), len(data)/5))
+	for i := uint32(0); i < count; i++ {
+		if len(data) < 1 {
+			return errMalformed
+		}
+		switch data[0] {
+		case opCopy:
+			if len(data) < 17 {
+				return errMalformed
+			}
+			d.Ops = append(d.Ops, Op{
+				Block: int64(binary.LittleEndian.Uint64(data[1:])),
+				Count: int64(binary.LittleEndian.Uint64(data[9:])),
+			})
+			data = data[17:]
+		case opLiteral:
+			if len(data) < 5 {
+				return errMalformed
+			}
+			n := binary.LittleEndian.Uint32(data[1:])
+			if uint64(len(data)-5) < uint64(n) || n == 0 {
+				return errMalformed
+			}
+			d.Ops = append(d.Ops, Op{Data: append([]byte(nil), data[5:5+n]...)})
+			data = data[5+n:]
+		default:
+			return errMalformed
+		}
+	}
+	if len(data) != 0 || d.BaseSize < 0 || d.TargetSize < 0 {
+		return errMalformed
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package delta
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+// roundTrip diffs target against base and applies the delta.
+func roundTrip(t *testing.T, base, target []byte, blockSize int) *Delta {
+	t.Helper()
+	sig, err := Sign(bytes.NewReader(base), blockSize)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	d, err := Diff(sig, bytes.NewReader(target))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Apply(bytes.NewReader(base), d, &out); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), target) {
+		t.Fatal("Apply should reconstruct the target")
+	}
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	base := randomBytes(1, 100*1024+77)
+
+	// Insert, delete and modify in the middle
+	target := append([]byte(nil), base[:10000]...)
+	target = append(target, []byte("inserted bytes")...)
+	target = append(target, base[10000:50000]...)
+	target = append(target, base[52000:]...)
+	target[70000] ^= 0xff
+
+	d := roundTrip(t, base, target, 1024)
+	if lit := d.LiteralBytes(); lit > 4*1024 {
+		t.Errorf("delta carries %d literal bytes, want only the changed blocks", lit)
+	}
+
+	roundTrip(t, base, base, 1024)
+	roundTrip(t, base, nil, 1024)
+	roundTrip(t, nil, base, 1024)
+	roundTrip(t, base, randomBytes(2, 5000), 1024)
+	roundTrip(t, []byte("short"), []byte("short"), 1024)
+}
+
+func TestShortLastBlock(t *testing.T) {
+	base := randomBytes(3, 10*512+100)
+	target := append(randomBytes(4, 700), base...)
+
+	d := roundTrip(t, base, target, 512)
+	if d.LiteralBytes() != 700 {
+		t.Errorf("LiteralBytes() = %d, want 700", d.LiteralBytes())
+	}
+	if len(d.Ops) != 2 || d.Ops[1].Count != 11 {
+		t.Errorf("Ops = %d, want a literal and one copy of all 11 blocks", len(d.Ops))
+	}
+}
+
+func TestRoll(t *testing.T) {
+	data := randomBytes(5, 300)
+	const n = 64
+	weak := weakSum(data[:n])
+	for i := 0; i+n < len(data); i++ {
+		weak = roll(weak, data[i], data[i+n], n)
+		if weak != weakSum(data[i+1:i+1+n]) {
+			t.Fatalf("rolled checksum at %d differs from recomputed", i+1)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	base := randomBytes(6, 20000)
+	target := append(append([]byte("head"), base[:9000]...), base[12000:]...)
+
+	sig, _ := Sign(bytes.NewReader(base), 700)
+	wire, _ := sig.MarshalBinary()
+	var sig2 Signature
+	if err := sig2.UnmarshalBinary(wire); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	d, _ := Diff(&sig2, bytes.NewReader(target))
+	wire, _ = d.MarshalBinary()
+	var d2 Delta
+	if err := d2.UnmarshalBinary(wire); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Apply(bytes.NewReader(base), &d2, &out); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), target) {
+		t.Error("Decoded delta should reconstruct the target")
+	}
+
+	for i := range wire {
+		var bad Delta
+		if bad.UnmarshalBinary(wire[:i]) == nil {
+			t.Fatalf("UnmarshalBinary of %d-byte prefix should fail", i)
+		}
+	}
+	if new(Signature).UnmarshalBinary([]byte("TDSG1")) == nil {
+		t.Error("Truncated signature should fail")
+	}
+}
+
+func TestApplyDetectsChangedBase(t *testing.T) {
+	base := randomBytes(7, 8192)
+	sig, _ := Sign(bytes.NewReader(base), 1024)
+	d, _ := Diff(sig, bytes.NewReader(base))
+
+	changed := append([]byte(nil), base...)
+	changed[5000] ^= 1
+	if err := Apply(bytes.NewReader(changed), d, new(bytes.Buffer)); !errors.Is(err, ErrMismatch) {
+		t.Errorf("err = %v, want ErrMismatch", err)
+	}
+
+	d.Ops = []Op{{Block: 8, Count: 1}}
+	if err := Apply(bytes.NewReader(base), d, new(bytes.Buffer)); err == nil {
+		t.Error("Copy outside the base should return error")
+	}
+
+	if _, err := Sign(bytes.NewReader(base), 0); err == nil {
+		t.Error("Zero block size should return error")
+	}
+}
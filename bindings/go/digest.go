@@ -0,0 +1,123 @@
+package tachyon
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ============================================================================
+// DIGEST TYPE
+// ============================================================================
+
+// DigestSize is the size of a Tachyon digest in bytes.
+const DigestSize = 32
+
+// Digest is a fixed-size Tachyon hash value.
+//
+// Unlike the []byte results of Hash and friends, a Digest is comparable and
+// can be used directly as a map key.
+type Digest [DigestSize]byte
+
+// DigestFromBytes converts a 32-byte hash into a Digest.
+func DigestFromBytes(b []byte) (Digest, error) {
+	var d Digest
+	if len(b) != DigestSize {
+		return d, errors.New("tachyon: digest must be 32 bytes")
+	}
+	copy(d[:], b)
+	return d, nil
+}
+
+// ParseDigest parses a 64-character hex string into a Digest.
+func ParseDigest(s string) (Digest, error) {
+	var d Digest
+	if len(s) != 2*DigestSize {
+		return d, errors.New("tachyon: digest must be 64 hex characters")
+	}
+	if _, err := hex.Decode(d[:], []byte(s)); err != nil {
+		return d, errors.New("tachyon: invalid hex digest")
+	}
+	return d, nil
+}
+
+// String returns the lowercase hex encoding of the digest.
+func (d Digest) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// IsZero reports whether the digest is all zero bytes.
+func (d Digest) IsZero() bool {
+	return d == Digest{}
+}
+
+// ============================================================================
+// DATABASE/SQL
+// ============================================================================
+
+// Value implements driver.Valuer, storing the digest as 32 raw bytes (for
+// BYTEA, BLOB or BINARY(32) columns). For hex text columns, pass d.String()
+// instead.
+func (d Digest) Value() (driver.Value, error) {
+	return d[:], nil
+}
+
+// Scan implements sql.Scanner. It accepts 32 raw bytes or 64 hex
+// characters, as []byte or string. A NULL column is an error; scan nullable
+// columns into a *Digest via a nullable wrapper.
+func (d *Digest) Scan(src any) error {
+	var err error
+	switch v := src.(type) {
+	case []byte:
+		if len(v) == DigestSize {
+			*d, err = DigestFromBytes(v)
+		} else {
+			*d, err = ParseDigest(string(v))
+		}
+	case string:
+		*d, err = ParseDigest(v)
+	case nil:
+		err = errors.New("tachyon: cannot scan NULL into Digest")
+	default:
+		err = fmt.Errorf("tachyon: cannot scan %T into Digest", src)
+	}
+	return err
+}
+
+// ============================================================================
+// COMPOSITE KEYS
+// ============================================================================
+
+// CompositeKey computes the DomainDatabaseIndex digest of a multi-column key,
+// so services index the same row under the same digest.
+//
+// The encoding is specified so other implementations can reproduce it:
+//
+//	column = 0x00                                 (NULL, a nil slice)
+//	column = 0x01 || LE32(len(value)) || value    (any other value)
+//	input  = LE32(number of columns) || column_0 || ... || column_n-1
+//	key    = HashWithDomain(input, DomainDatabaseIndex)
+//
+// Columns are length-prefixed, so ("ab", "c") and ("a", "bc") differ, and a
+// NULL column differs from an empty one. Callers must encode column values
+// the same way everywhere, e.g. integers as fixed-width big-endian bytes.
+func CompositeKey(columns ...[]byte) (Digest, error) {
+	size := 4
+	for _, c := range columns {
+		size += 5 + len(c)
+	}
+	input := make([]byte, 0, size)
+	input = binary.LittleEndian.AppendUint32(input, uint32(len(columns)))
+	for _, c := range columns {
+		if c == nil {
+			input = append(input, 0)
+			continue
+		}
+		input = append(input, 1)
+		input = binary.LittleEndian.AppendUint32(input, uint32(len(c)))
+		input = append(input, c...)
+	}
+	return hashDigest(input, DomainDatabaseIndex, 0, nil)
+}
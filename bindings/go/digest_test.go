@@ -0,0 +1,114 @@
+package tachyon
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDigestRoundTrip(t *testing.T) {
+	hash, err := Hash([]byte("digest"))
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	d, err := DigestFromBytes(hash)
+	if err != nil {
+		t.Fatalf("DigestFromBytes failed: %v", err)
+	}
+	if !bytes.Equal(d[:], hash) {
+		t.Error("Digest should hold the hash bytes")
+	}
+
+	parsed, err := ParseDigest(d.String())
+	if err != nil {
+		t.Fatalf("ParseDigest failed: %v", err)
+	}
+	if parsed != d {
+		t.Error("ParseDigest(d.String()) should round-trip")
+	}
+
+	if d.IsZero() {
+		t.Error("Hash digest should not be zero")
+	}
+	if !(Digest{}).IsZero() {
+		t.Error("Empty digest should be zero")
+	}
+}
+
+func TestDigestErrors(t *testing.T) {
+	if _, err := DigestFromBytes([]byte("short")); err == nil {
+		t.Error("Wrong digest size should return error")
+	}
+	if _, err := ParseDigest("abcd"); err == nil {
+		t.Error("Short hex string should return error")
+	}
+	if _, err := ParseDigest(string(bytes.Repeat([]byte("zz"), 32))); err == nil {
+		t.Error("Invalid hex should return error")
+	}
+}
+
+// Compile-time checks for the database/sql interfaces.
+var (
+	_ driver.Valuer = Digest{}
+	_ sql.Scanner   = (*Digest)(nil)
+)
+
+func TestDigestSQL(t *testing.T) {
+	d, _ := Sum([]byte("row"))
+
+	v, err := d.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	for _, src := range []any{v, d.String(), []byte(d.String())} {
+		var got Digest
+		if err := got.Scan(src); err != nil {
+			t.Fatalf("Scan(%T) failed: %v", src, err)
+		}
+		if got != d {
+			t.Errorf("Scan(%T) should round-trip", src)
+		}
+	}
+
+	for _, src := range []any{nil, 42, []byte("short"), "not hex"} {
+		var got Digest
+		if got.Scan(src) == nil {
+			t.Errorf("Scan(%#v) should return error", src)
+		}
+	}
+}
+
+func TestCompositeKey(t *testing.T) {
+	key, err := CompositeKey([]byte("tenant-7"), []byte("orders"), nil)
+	if err != nil {
+		t.Fatalf("CompositeKey failed: %v", err)
+	}
+
+	// Reproduce the specified encoding by hand
+	input := binary.LittleEndian.AppendUint32(nil, 3)
+	for _, c := range []string{"tenant-7", "orders"} {
+		input = append(input, 1)
+		input = binary.LittleEndian.AppendUint32(input, uint32(len(c)))
+		input = append(input, c...)
+	}
+	input = append(input, 0)
+	want, _ := HashWithDomain(input, DomainDatabaseIndex)
+	if !bytes.Equal(key[:], want) {
+		t.Error("CompositeKey should follow the specified encoding")
+	}
+
+	a, _ := CompositeKey([]byte("ab"), []byte("c"))
+	b, _ := CompositeKey([]byte("a"), []byte("bc"))
+	if a == b {
+		t.Error("Column boundaries should be part of the key")
+	}
+	null, _ := CompositeKey(nil)
+	empty, _ := CompositeKey([]byte{})
+	none, _ := CompositeKey()
+	if null == empty || null == none || empty == none {
+		t.Error("NULL, empty and absent columns should differ")
+	}
+}
@@ -0,0 +1,526 @@
+//go:build cgo
+
+package tachyon
+
+/*
+#include "tachyon.h"
+#include <stdlib.h>
+#include <string.h>
+#if defined(__unix__) || defined(__APPLE__)
+#include <sys/mman.h>
+#include <unistd.h>
+#endif
+
+// Hash one input under several seeds in a single cgo transition.
+static int32_t tachyon_go_hash_seeded_multi(const uint8_t *input_ptr, size_t input_len,
+                                            const uint64_t *seeds, size_t count,
+                                            uint8_t *output_ptr) {
+    for (size_t i = 0; i < count; i++) {
+        int32_t res = tachyon_hash_seeded(input_ptr, input_len, seeds[i], output_ptr + 32 * i);
+        if (res != 0) {
+            return res;
+        }
+    }
+    return 0;
+}
+
+// Compute count MACs under one key in a single cgo transition. The inputs
+// are packed back to back in packed.
+static int32_t tachyon_go_hash_keyed_multi(const uint8_t *packed, const size_t *lens, size_t count,
+                                           const uint8_t *key, uint8_t *output_ptr) {
+    for (size_t i = 0; i < count; i++) {
+        int32_t res = tachyon_hash_keyed(packed, lens[i], key, output_ptr + 32 * i);
+        if (res != 0) {
+            return res;
+        }
+        packed += lens[i];
+    }
+    return 0;
+}
+
+// Expand a root digest into out_len bytes: block i = MAC(root, LE64(i)).
+static int32_t tachyon_go_expand(const uint8_t *root, uint8_t *out, size_t out_len) {
+    uint8_t counter[8], block[32];
+    for (size_t i = 0; i * 32 < out_len; i++) {
+        for (int b = 0; b < 8; b++) {
+            counter[b] = (uint8_t)((uint64_t)i >> (8 * b));
+        }
+        int32_t res = tachyon_hash_keyed(counter, 8, root, block);
+        if (res != 0) {
+            return res;
+        }
+        size_t n = out_len - i * 32 < 32 ? out_len - i * 32 : 32;
+        memcpy(out + i * 32, block, n);
+    }
+    return 0;
+}
+
+// Hash input once under seed, then expand the digest into out_len bytes.
+static int32_t tachyon_go_hash_expand(const uint8_t *input_ptr, size_t input_len, uint64_t seed,
+                                      uint8_t *out, size_t out_len) {
+    uint8_t root[32];
+    int32_t res = tachyon_hash_seeded(input_ptr, input_len, seed, root);
+    if (res != 0) {
+        return res;
+    }
+    return tachyon_go_expand(root, out, out_len);
+}
+
+// Hash into out_len bytes in a single cgo transition.
+//
+// Outputs up to 32 bytes are a prefix of the root hash; longer outputs expand
+// the root.
+static int32_t tachyon_go_hash_sized(const uint8_t *input_ptr, size_t input_len,
+                                     uint64_t domain, uint64_t seed, const uint8_t *key_ptr,
+                                     uint8_t *out, size_t out_len) {
+    uint8_t root[32];
+    int32_t res = tachyon_hash_full(input_ptr, input_len, domain, seed, key_ptr, root);
+    if (res != 0) {
+        return res;
+    }
+    if (out_len <= 32) {
+        memcpy(out, root, out_len);
+        return 0;
+    }
+    return tachyon_go_expand(root, out, out_len);
+}
+
+// A digest returned by value, so the Go caller passes no out-pointer that
+// would escape to the heap.
+typedef struct {
+    int32_t res;
+    uint8_t digest[32];
+} tachyon_go_digest;
+
+static tachyon_go_digest tachyon_go_hash_digest(const uint8_t *input_ptr, size_t input_len,
+                                                uint64_t domain, uint64_t seed,
+                                                const uint8_t *key_ptr) {
+    tachyon_go_digest d;
+    d.res = tachyon_hash_full(input_ptr, input_len, domain, seed, key_ptr, d.digest);
+    return d;
+}
+
+// Generate count keystream blocks: block i = MAC(key, LE64(counter + i) || nonce).
+static int32_t tachyon_go_keystream(const uint8_t *key, const uint8_t *nonce, size_t nonce_len,
+                                    uint64_t counter, size_t count, uint8_t *out) {
+    uint8_t input[8 + 32];
+    if (nonce_len > 32) {
+        return -1;
+    }
+    memcpy(input + 8, nonce, nonce_len);
+    for (size_t i = 0; i < count; i++) {
+        uint64_t c = counter + i;
+        for (int b = 0; b < 8; b++) {
+            input[b] = (uint8_t)(c >> (8 * b));
+        }
+        int32_t res = tachyon_hash_keyed(input, 8 + nonce_len, key, out + 32 * i);
+        if (res != 0) {
+            return res;
+        }
+    }
+    return 0;
+}
+
+// Allocate n zeroed bytes for key material: page-aligned, locked in RAM and
+// excluded from core dumps where the platform allows. Locking is best effort.
+static uint8_t *tachyon_go_secret_alloc(size_t n) {
+#if defined(__unix__) || defined(__APPLE__)
+    size_t page = (size_t)sysconf(_SC_PAGESIZE);
+    size_t size = (n + page - 1) / page * page;
+    void *p = NULL;
+    if (posix_memalign(&p, page, size) != 0) {
+        return NULL;
+    }
+    memset(p, 0, size);
+    (void)mlock(p, size);
+#if defined(MADV_DONTDUMP)
+    (void)madvise(p, size, MADV_DONTDUMP);
+#endif
+    return (uint8_t *)p;
+#else
+    return (uint8_t *)calloc(1, n);
+#endif
+}
+
+// Wipe and free memory from tachyon_go_secret_alloc.
+static void tachyon_go_secret_free(uint8_t *p, size_t n) {
+    volatile uint8_t *v = p;
+    for (size_t i = 0; i < n; i++) {
+        v[i] = 0;
+    }
+#if defined(__unix__) || defined(__APPLE__)
+    size_t page = (size_t)sysconf(_SC_PAGESIZE);
+    (void)munlock(p, (n + page - 1) / page * page);
+#endif
+    free(p);
+}
+*/
+import "C"
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ============================================================================
+// NATIVE ENGINE
+// ============================================================================
+
+// The primitives below are the only place the binding calls into the native
+// library. engine_purego.go provides the same primitives in pure Go for
+// builds without cgo; after SetImplementation(ImplPureGo) they are used here
+// as well.
+
+var errInternal = errors.New("tachyon: internal error")
+
+// pureGo routes the primitives to the pure Go engine.
+var pureGo atomic.Bool
+
+func setPureGo(on bool) { pureGo.Store(on) }
+
+// emptyInput provides a valid non-nil pointer for empty inputs without
+// allocating.
+var emptyInput byte
+
+func inputPtr(data []byte) *C.uint8_t {
+	if len(data) == 0 {
+		return (*C.uint8_t)(unsafe.Pointer(&emptyInput))
+	}
+	return (*C.uint8_t)(unsafe.Pointer(&data[0]))
+}
+
+func keyPtr(key []byte) *C.uint8_t {
+	if key == nil {
+		return nil
+	}
+	return (*C.uint8_t)(unsafe.Pointer(&key[0]))
+}
+
+func backendName() string {
+	if pureGo.Load() {
+		return BackendGo
+	}
+	return nativeBackendName()
+}
+
+func nativeBackendName() string {
+	return C.GoString(C.tachyon_get_backend_name())
+}
+
+// hashFull computes the 32-byte digest of data under domain, seed and an
+// optional 32-byte key.
+func hashFull(data []byte, domain, seed uint64, key []byte, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	if pureGo.Load() {
+		goHashFull(data, domain, seed, key, out)
+		return nil
+	}
+	res := C.tachyon_hash_full(inputPtr(data), C.size_t(len(data)), C.uint64_t(domain),
+		C.uint64_t(seed), keyPtr(key), (*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// hashLeaves hashes len(out) whole chunks of data into Merkle leaves.
+func hashLeaves(data []byte, seed uint64, key []byte, out []Digest) error {
+	if len(out) == 0 {
+		return nil
+	}
+	if pureGo.Load() {
+		goHashLeaves(data, seed, key, out)
+		return nil
+	}
+	res := C.tachyon_hash_leaves(inputPtr(data), C.size_t(len(out)), C.uint64_t(seed), keyPtr(key),
+		(*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// treeKernel hashes an input shorter than a chunk (a node, the last leaf or
+// the root commitment) for trees assembled in Go.
+func treeKernel(data []byte, domain, seed uint64, key []byte, out *Digest) error {
+	if pureGo.Load() {
+		goKernel(data, domain, seed, key, out)
+		return nil
+	}
+	res := C.tachyon_hash_full(inputPtr(data), C.size_t(len(data)), C.uint64_t(domain),
+		C.uint64_t(seed), keyPtr(key), (*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// hashDigest is hashFull returning the digest by value. Unlike a Digest
+// passed to hashFull, the result never escapes to the heap.
+func hashDigest(data []byte, domain, seed uint64, key []byte) (Digest, error) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	var out Digest
+	if pureGo.Load() {
+		goHashFull(data, domain, seed, key, &out)
+		return out, nil
+	}
+	res := C.tachyon_go_hash_digest(inputPtr(data), C.size_t(len(data)), C.uint64_t(domain),
+		C.uint64_t(seed), keyPtr(key))
+	if res.res != 0 {
+		return out, errInternal
+	}
+	out = *(*Digest)(unsafe.Pointer(&res.digest))
+	return out, nil
+}
+
+// hashSizedInto computes a len(out)-byte digest: a prefix of the root for up
+// to 32 bytes, the expanded root beyond.
+func hashSizedInto(data []byte, domain, seed uint64, key []byte, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	if pureGo.Load() {
+		goHashSizedInto(data, domain, seed, key, out)
+		return nil
+	}
+	res := C.tachyon_go_hash_sized(inputPtr(data), C.size_t(len(data)), C.uint64_t(domain),
+		C.uint64_t(seed), keyPtr(key), (*C.uint8_t)(unsafe.Pointer(&out[0])), C.size_t(len(out)))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// expandInto fills out with the expansion of root.
+func expandInto(root *Digest, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpExpand, DomainGeneric, DigestSize, time.Now())
+	}
+	if pureGo.Load() {
+		goExpandInto(root, out)
+		return nil
+	}
+	res := C.tachyon_go_expand((*C.uint8_t)(unsafe.Pointer(&root[0])),
+		(*C.uint8_t)(unsafe.Pointer(&out[0])), C.size_t(len(out)))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// hashExpand hashes data under seed and fills out with the expansion of the
+// digest.
+func hashExpand(data []byte, seed uint64, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, DomainGeneric, len(data), time.Now())
+	}
+	if pureGo.Load() {
+		goHashExpand(data, seed, out)
+		return nil
+	}
+	res := C.tachyon_go_hash_expand(inputPtr(data), C.size_t(len(data)), C.uint64_t(seed),
+		(*C.uint8_t)(unsafe.Pointer(&out[0])), C.size_t(len(out)))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// hashSeededMulti computes HashSeeded(data, seeds[i]) into out[i].
+func hashSeededMulti(data []byte, seeds []uint64, out []Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpBatch, DomainGeneric, len(data)*len(seeds), time.Now())
+	}
+	if pureGo.Load() {
+		goHashSeededMulti(data, seeds, out)
+		return nil
+	}
+	res := C.tachyon_go_hash_seeded_multi(inputPtr(data), C.size_t(len(data)),
+		(*C.uint64_t)(unsafe.Pointer(&seeds[0])), C.size_t(len(seeds)),
+		(*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// hashKeyedMulti computes the MAC of inputs[i] under key into out[i].
+// Inputs must not be empty.
+func hashKeyedMulti(inputs [][]byte, key []byte, out []Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpBatch, DomainMessageAuth, inputBytes(inputs), time.Now())
+	}
+	if pureGo.Load() {
+		goHashKeyedMulti(inputs, key, out)
+		return nil
+	}
+
+	// Packing is cheaper than pinning every input for a pointer array
+	total := 0
+	for _, in := range inputs {
+		total += len(in)
+	}
+	packed := make([]byte, 0, total)
+	lens := make([]C.size_t, len(inputs))
+	for i, in := range inputs {
+		packed = append(packed, in...)
+		lens[i] = C.size_t(len(in))
+	}
+	res := C.tachyon_go_hash_keyed_multi(inputPtr(packed), &lens[0], C.size_t(len(inputs)),
+		keyPtr(key), (*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// keystream fills out (a multiple of 32 bytes) with keystream blocks starting
+// at counter.
+func keystream(key *[32]byte, nonce []byte, counter uint64, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpKeystream, DomainMessageAuth, len(out), time.Now())
+	}
+	if pureGo.Load() {
+		return goKeystream(key, nonce, counter, out)
+	}
+	noncePtr := (*C.uint8_t)(unsafe.Pointer(&key[0])) // Any valid pointer when empty
+	if len(nonce) > 0 {
+		noncePtr = (*C.uint8_t)(unsafe.Pointer(&nonce[0]))
+	}
+	res := C.tachyon_go_keystream((*C.uint8_t)(unsafe.Pointer(&key[0])), noncePtr,
+		C.size_t(len(nonce)), C.uint64_t(counter), C.size_t(len(out)/32),
+		(*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+// deriveKey computes DeriveKey(context, material) into out.
+func deriveKey(context string, material []byte, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpDeriveKey, DomainKeyDerivation, len(material), time.Now())
+	}
+	if pureGo.Load() {
+		return goDeriveKey(context, material, out)
+	}
+	ctx := []byte(context)
+	res := C.tachyon_derive_key(inputPtr(ctx), C.size_t(len(ctx)),
+		(*C.uint8_t)(unsafe.Pointer(&material[0])), (*C.uint8_t)(unsafe.Pointer(&out[0])))
+	if res != 0 {
+		return errors.New("tachyon: internal error or invalid UTF-8")
+	}
+	return nil
+}
+
+// ============================================================================
+// NATIVE STREAMING STATE
+// ============================================================================
+
+// stateHandle is an owned streaming state; nil means none.
+//
+// A state keeps the engine it was created with: states created under
+// SetImplementation(ImplPureGo) are pure Go, all others native.
+type stateHandle = *engineState
+
+type engineState struct {
+	native unsafe.Pointer
+	goS    *goState
+	domain uint64 // For metrics
+}
+
+func newState(domain, seed uint64, key []byte) stateHandle {
+	if pureGo.Load() {
+		return &engineState{goS: newGoState(domain, seed, key), domain: domain}
+	}
+	p := C.tachyon_hasher_new_full(C.uint64_t(domain), C.uint64_t(seed), keyPtr(key))
+	if p == nil {
+		return nil
+	}
+	return &engineState{native: p, domain: domain}
+}
+
+func stateUpdate(s stateHandle, p []byte) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpUpdate, s.domain, len(p), time.Now())
+	}
+	if s.goS != nil {
+		s.goS.update(p)
+		return
+	}
+	if len(p) > 0 {
+		C.tachyon_hasher_update(s.native, (*C.uint8_t)(unsafe.Pointer(&p[0])), C.size_t(len(p)))
+	}
+}
+
+// stateFinalize writes the digest and frees s.
+func stateFinalize(s stateHandle, out *Digest) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpFinalize, s.domain, 0, time.Now())
+	}
+	if s.goS != nil {
+		s.goS.sum(out)
+		return
+	}
+	C.tachyon_hasher_finalize(s.native, (*C.uint8_t)(unsafe.Pointer(&out[0])))
+	s.native = nil
+}
+
+// stateFinalizeReset writes the digest and resets s in place for reuse,
+// keeping its domain, seed and key.
+func stateFinalizeReset(s stateHandle, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpFinalize, s.domain, 0, time.Now())
+	}
+	if s.goS != nil {
+		s.goS.sum(out)
+		s.goS.reset()
+		return nil
+	}
+	if C.tachyon_hasher_finalize_reset(s.native, (*C.uint8_t)(unsafe.Pointer(&out[0]))) != 0 {
+		return errInternal
+	}
+	return nil
+}
+
+func stateClone(s stateHandle) stateHandle {
+	if s.goS != nil {
+		return &engineState{goS: s.goS.clone(), domain: s.domain}
+	}
+	p := C.tachyon_hasher_clone(s.native)
+	if p == nil {
+		return nil
+	}
+	return &engineState{native: p, domain: s.domain}
+}
+
+func stateFree(s stateHandle) {
+	if s.native != nil {
+		C.tachyon_hasher_free(s.native)
+		s.native = nil
+	}
+}
+
+// ============================================================================
+// SECRET MEMORY
+// ============================================================================
+
+// secretAlloc returns n zeroed bytes outside the Go heap, locked in RAM where
+// possible, or nil.
+func secretAlloc(n int) []byte {
+	p := C.tachyon_go_secret_alloc(C.size_t(n))
+	if p == nil {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(p)), n)
+}
+
+// secretFree wipes and releases memory from secretAlloc.
+func secretFree(b []byte) {
+	C.tachyon_go_secret_free((*C.uint8_t)(unsafe.Pointer(&b[0])), C.size_t(len(b)))
+}
@@ -0,0 +1,162 @@
+//go:build !cgo
+
+package tachyon
+
+import (
+	"errors"
+	"time"
+)
+
+// ============================================================================
+// PURE GO ENGINE
+// ============================================================================
+
+// Without cgo (GOOS=js, GOOS=wasip1, CGO_ENABLED=0) the binding runs on the
+// pure Go port of the portable kernel in purego_kernel.go and purego_tree.go.
+// It implements the same primitives as engine_cgo.go and produces identical
+// output; it is considerably slower than the SIMD backends.
+
+var errInternal = errors.New("tachyon: internal error")
+
+func backendName() string {
+	return BackendGo
+}
+
+// selectNative fails for every native kernel: there is none in this build.
+func selectNative(impl Implementation) error {
+	if impl != ImplAuto {
+		return errUnavailable(impl)
+	}
+	return nil
+}
+
+// setPureGo is a no-op: this build always runs the pure Go engine.
+func setPureGo(on bool) {}
+
+func hashFull(data []byte, domain, seed uint64, key []byte, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	goHashFull(data, domain, seed, key, out)
+	return nil
+}
+
+func hashLeaves(data []byte, seed uint64, key []byte, out []Digest) error {
+	goHashLeaves(data, seed, key, out)
+	return nil
+}
+
+func treeKernel(data []byte, domain, seed uint64, key []byte, out *Digest) error {
+	goKernel(data, domain, seed, key, out)
+	return nil
+}
+
+func hashDigest(data []byte, domain, seed uint64, key []byte) (Digest, error) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	var out Digest
+	goHashFull(data, domain, seed, key, &out)
+	return out, nil
+}
+
+func hashSizedInto(data []byte, domain, seed uint64, key []byte, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, domain, len(data), time.Now())
+	}
+	goHashSizedInto(data, domain, seed, key, out)
+	return nil
+}
+
+func expandInto(root *Digest, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpExpand, DomainGeneric, DigestSize, time.Now())
+	}
+	goExpandInto(root, out)
+	return nil
+}
+
+func hashExpand(data []byte, seed uint64, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpHash, DomainGeneric, len(data), time.Now())
+	}
+	goHashExpand(data, seed, out)
+	return nil
+}
+
+func hashSeededMulti(data []byte, seeds []uint64, out []Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpBatch, DomainGeneric, len(data)*len(seeds), time.Now())
+	}
+	goHashSeededMulti(data, seeds, out)
+	return nil
+}
+
+func hashKeyedMulti(inputs [][]byte, key []byte, out []Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpBatch, DomainMessageAuth, inputBytes(inputs), time.Now())
+	}
+	goHashKeyedMulti(inputs, key, out)
+	return nil
+}
+
+func keystream(key *[32]byte, nonce []byte, counter uint64, out []byte) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpKeystream, DomainMessageAuth, len(out), time.Now())
+	}
+	return goKeystream(key, nonce, counter, out)
+}
+
+func deriveKey(context string, material []byte, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpDeriveKey, DomainKeyDerivation, len(material), time.Now())
+	}
+	return goDeriveKey(context, material, out)
+}
+
+// ============================================================================
+// STREAMING STATE
+// ============================================================================
+
+type stateHandle = *goState
+
+func newState(domain, seed uint64, key []byte) stateHandle {
+	return newGoState(domain, seed, key)
+}
+
+func stateUpdate(s stateHandle, p []byte) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpUpdate, s.domain, len(p), time.Now())
+	}
+	s.update(p)
+}
+
+func stateFinalize(s stateHandle, out *Digest) {
+	if m := metrics(); m != nil {
+		defer observe(m, OpFinalize, s.domain, 0, time.Now())
+	}
+	s.sum(out)
+}
+
+func stateFinalizeReset(s stateHandle, out *Digest) error {
+	if m := metrics(); m != nil {
+		defer observe(m, OpFinalize, s.domain, 0, time.Now())
+	}
+	s.sum(out)
+	s.reset()
+	return nil
+}
+
+func stateClone(s stateHandle) stateHandle { return s.clone() }
+
+func stateFree(s stateHandle) {}
+
+// ============================================================================
+// SECRET MEMORY
+// ============================================================================
+
+// Without cgo, secrets live on the Go heap and are only wiped on release.
+
+func secretAlloc(n int) []byte { return make([]byte, n) }
+
+func secretFree(b []byte) { clear(b) }
@@ -0,0 +1,215 @@
+package tachyon
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+)
+
+// The fuzz targets check consistency classes that fixed tests sample only
+// sparsely: every way of computing the same digest must agree for arbitrary
+// inputs and Update split points. Run one with, e.g.:
+//
+//	go test -run '^$' -fuzz FuzzConsistency
+//
+// Without -fuzz, the seed corpus (including the plain test vectors) runs
+// as part of go test.
+
+// maxFuzzInput bounds inputs grown by repetition; it covers the multi-chunk
+// tree path.
+const maxFuzzInput = 4*nativeChunkSize + 1
+
+// referenceVectors loads the plain (unkeyed, default domain and size) test
+// vectors generated by package vectors, keyed by hex input. The package
+// imports tachyon, so its file is decoded directly.
+var referenceVectors = sync.OnceValues(func() (map[string]string, error) {
+	raw, err := os.ReadFile("vectors/vectors.json.gz")
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Vectors []struct {
+			Input, Hash, Key string
+			Seed, Domain     uint64
+			Size             int
+		}
+	}
+	if err := json.NewDecoder(zr).Decode(&file); err != nil {
+		return nil, err
+	}
+	vectors := make(map[string]string)
+	for _, v := range file.Vectors {
+		if v.Key == "" && v.Seed == 0 && v.Domain == 0 && v.Size == 0 {
+			vectors[v.Input] = v.Hash
+		}
+	}
+	return vectors, nil
+})
+
+// fuzzBatch is shared by all fuzz iterations; starting workers per input
+// would dominate the run time.
+var fuzzBatch = sync.OnceValues(func() (*BatchHasher, error) {
+	return NewBatchHasher(BatchConfig{Workers: 2})
+})
+
+// fuzzInput repeats data, bounded by maxFuzzInput.
+func fuzzInput(data []byte, repeat uint16) []byte {
+	if len(data) == 0 || repeat == 0 {
+		return data
+	}
+	n := min(int(repeat)+1, maxFuzzInput/len(data))
+	return bytes.Repeat(data, max(n, 1))
+}
+
+// splitPoints cuts data into pieces whose lengths are drawn from splits.
+func splitPoints(data, splits []byte) [][]byte {
+	var pieces [][]byte
+	for i := 0; len(data) > 0; i++ {
+		n := len(data)
+		if len(splits) > 0 {
+			s := int(splits[i%len(splits)])
+			if n = min(s*s*s+s, len(data)); n == 0 {
+				pieces = append(pieces, nil) // Empty updates are valid
+				n = 1
+			}
+		}
+		pieces = append(pieces, data[:n])
+		data = data[n:]
+	}
+	return pieces
+}
+
+func fuzzSeeds(f *testing.F) {
+	f.Add([]byte(""), []byte{}, uint16(0))
+	f.Add([]byte("abc"), []byte{1}, uint16(0))
+	f.Add([]byte("Tachyon"), []byte{0, 2, 5}, uint16(300))
+	f.Add(bytes.Repeat([]byte{0xA5}, 64), []byte{64, 3}, uint16(8191))
+	f.Add([]byte{1, 2, 3}, []byte{255, 17, 40}, uint16(65535))
+
+	vectors, err := referenceVectors()
+	if err != nil {
+		f.Fatalf("loading reference vectors failed: %v", err)
+	}
+	for in := range vectors {
+		data, _ := hex.DecodeString(in)
+		f.Add(data, []byte{7, 31}, uint16(0))
+	}
+}
+
+// FuzzConsistency checks that one-shot, streaming (split at arbitrary
+// points), seeded-zero, batch, Peek and Clone computations of the same
+// digest agree, and matches reference vectors when an input is one.
+func FuzzConsistency(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data, splits []byte, repeat uint16) {
+		data = fuzzInput(data, repeat)
+
+		want, err := Sum(data)
+		if err != nil {
+			t.Fatalf("Sum failed: %v", err)
+		}
+
+		if vectors, _ := referenceVectors(); vectors != nil {
+			if ref, ok := vectors[hex.EncodeToString(data)]; ok && ref != want.String() {
+				t.Fatalf("%d bytes: reference %s, got %s", len(data), ref, want)
+			}
+		}
+
+		oneShot, _ := Hash(data)
+		seeded, _ := HashSeeded(data, 0)
+		if !bytes.Equal(oneShot, want[:]) || !bytes.Equal(seeded, want[:]) {
+			t.Fatalf("%d bytes: Hash or HashSeeded(0) differs from Sum", len(data))
+		}
+
+		h := NewHasher()
+		pieces := splitPoints(data, splits)
+		var peeked []byte
+		for i, p := range pieces {
+			if err := h.Update(p); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			if i == len(pieces)/2 {
+				peeked, _ = h.Peek()
+			}
+		}
+		clone := h.Clone()
+		streamed, err := h.Finalize()
+		if err != nil {
+			t.Fatalf("Finalize failed: %v", err)
+		}
+		if !bytes.Equal(streamed, want[:]) {
+			t.Fatalf("%d bytes in %d pieces: streaming differs from Sum", len(data), len(pieces))
+		}
+		var cloned Digest
+		if err := clone.SumInto(&cloned); err != nil || cloned != want {
+			t.Fatalf("%d bytes: clone differs from Sum (err %v)", len(data), err)
+		}
+		if peeked != nil {
+			prefix := 0
+			for _, p := range pieces[:len(pieces)/2+1] {
+				prefix += len(p)
+			}
+			if part, _ := Sum(data[:prefix]); !bytes.Equal(peeked, part[:]) {
+				t.Fatalf("Peek after %d bytes differs from Sum of the prefix", prefix)
+			}
+		}
+
+		batch, err := fuzzBatch()
+		if err != nil {
+			t.Fatalf("NewBatchHasher failed: %v", err)
+		}
+		digests, err := batch.HashAll([][]byte{data, nil, data})
+		if err != nil {
+			t.Fatalf("HashAll failed: %v", err)
+		}
+		if digests[0] != want || digests[2] != want {
+			t.Fatalf("%d bytes: batch differs from Sum", len(data))
+		}
+	})
+}
+
+// FuzzDifferential cross-checks the active engine (the native library in cgo
+// builds) against the independent pure Go port for every mode and output
+// size.
+func FuzzDifferential(f *testing.F) {
+	f.Add([]byte("abc"), uint8(0), uint64(0), []byte(nil), uint8(32), uint16(0))
+	f.Add([]byte("Tachyon"), uint8(DomainMessageAuth), uint64(7), bytes.Repeat([]byte{9}, 32), uint8(64), uint16(0))
+	f.Add([]byte{0xff}, uint8(200), ^uint64(0), []byte(nil), uint8(16), uint16(40000))
+	f.Fuzz(func(t *testing.T, data []byte, domain uint8, seed uint64, key []byte, size uint8, repeat uint16) {
+		data = fuzzInput(data, repeat)
+		if len(key) != 32 {
+			key = nil
+		}
+		outSize := []int{Size128, Size192, Size256, Size512}[size%4]
+		dom := outputDomain(uint64(domain), outSize)
+
+		got := make([]byte, outSize)
+		if err := hashSizedInto(data, dom, seed, key, got); err != nil {
+			t.Fatalf("hashSizedInto failed: %v", err)
+		}
+		want := make([]byte, outSize)
+		goHashSizedInto(data, dom, seed, key, want)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%d bytes, domain %d, seed %d, keyed %v, size %d: engine %x, pure Go %x",
+				len(data), domain, seed, key != nil, outSize, got, want)
+		}
+
+		var streamed Digest
+		s := newState(uint64(domain), seed, key)
+		stateUpdate(s, data)
+		stateFinalize(s, &streamed)
+		var full Digest
+		goHashFull(data, uint64(domain), seed, key, &full)
+		if streamed != full {
+			t.Fatalf("%d bytes: engine streaming differs from pure Go", len(data))
+		}
+	})
+}
@@ -0,0 +1,261 @@
+// Package hashring implements consistent hashing keyed by Tachyon digests.
+//
+// Each node is placed on a 64-bit ring at several virtual positions taken
+// from seeded Tachyon hashes of its name (one native call per node), and a
+// key is served by the first node clockwise from the key's hash. Adding or
+// removing a node only moves the keys adjacent to its virtual positions.
+//
+// A ring created with NewBounded additionally implements consistent hashing
+// with bounded loads: Acquire skips nodes whose load would exceed
+// loadFactor times the average.
+//
+// Example:
+//
+//	ring := hashring.New(100)
+//	ring.AddNode("cache-a")
+//	ring.AddNode("cache-b")
+//	node, _ := ring.GetNode([]byte("user:42"))
+package hashring
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrEmpty is returned when a lookup is made on a ring without nodes.
+	ErrEmpty = errors.New("hashring: ring has no nodes")
+
+	// ErrExists is returned when adding a node that is already on the ring.
+	ErrExists = errors.New("hashring: node already present")
+
+	// ErrOverloaded is returned by Acquire when every node is at capacity.
+	ErrOverloaded = errors.New("hashring: all nodes at capacity")
+)
+
+// ============================================================================
+// RING
+// ============================================================================
+
+// DefaultReplicas is the number of virtual nodes used when New is given
+// a non-positive count.
+const DefaultReplicas = 160
+
+// point is one virtual node position on the ring.
+type point struct {
+	hash uint64
+	node string
+}
+
+// Ring is a consistent hash ring. A Ring is safe for concurrent use.
+type Ring struct {
+	mu         sync.RWMutex
+	seeds      []uint64 // One seed per virtual node
+	points     []point  // Sorted by hash
+	nodes      map[string]struct{}
+	loadFactor float64 // 0 for unbounded rings
+	loads      map[string]int64
+	totalLoad  int64
+}
+
+// New creates a ring placing each node at replicas virtual positions.
+func New(replicas int) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+	seeds := make([]uint64, replicas)
+	for i := range seeds {
+		seeds[i] = uint64(i) + 1
+	}
+	return &Ring{
+		seeds: seeds,
+		nodes: make(map[string]struct{}),
+		loads: make(map[string]int64),
+	}
+}
+
+// NewBounded creates a ring with bounded loads.
+//
+// No node is assigned more than ceil(loadFactor * average load) keys through
+// Acquire. loadFactor must be greater than 1; 1.25 is a common choice.
+func NewBounded(replicas int, loadFactor float64) (*Ring, error) {
+	if loadFactor <= 1 {
+		return nil, errors.New("hashring: load factor must be greater than 1")
+	}
+	r := New(replicas)
+	r.loadFactor = loadFactor
+	return r, nil
+}
+
+// AddNode places node on the ring.
+func (r *Ring) AddNode(node string) error {
+	digests, err := tachyon.HashSeededMulti([]byte(node), r.seeds)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.nodes[node]; ok {
+		return ErrExists
+	}
+	r.nodes[node] = struct{}{}
+	for i := range digests {
+		r.points = append(r.points, point{hash: position(digests[i][:]), node: node})
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].node < r.points[j].node
+	})
+	return nil
+}
+
+// RemoveNode removes node and all of its virtual positions from the ring.
+//
+// Removing a node that is not present is a no-op.
+func (r *Ring) RemoveNode(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.nodes[node]; !ok {
+		return
+	}
+	delete(r.nodes, node)
+	r.totalLoad -= r.loads[node]
+	delete(r.loads, node)
+
+	kept := r.points[:0]
+	for _, p := range r.points {
+		if p.node != node {
+			kept = append(kept, p)
+		}
+	}
+	r.points = kept
+}
+
+// GetNode returns the node responsible for key.
+func (r *Ring) GetNode(key []byte) (string, error) {
+	h, err := keyPosition(key)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.points) == 0 {
+		return "", ErrEmpty
+	}
+	return r.points[r.search(h)].node, nil
+}
+
+// Nodes returns the nodes on the ring in sorted order.
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := make([]string, 0, len(r.nodes))
+	for n := range r.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// ============================================================================
+// BOUNDED LOADS
+// ============================================================================
+
+// Acquire assigns key to a node and increments that node's load.
+//
+// On an unbounded ring this is GetNode plus load accounting. On a bounded
+// ring, nodes at capacity are skipped clockwise. Every Acquire must be paired
+// with a Release of the returned node.
+func (r *Ring) Acquire(key []byte) (string, error) {
+	h, err := keyPosition(key)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.points) == 0 {
+		return "", ErrEmpty
+	}
+
+	capacity := int64(math.MaxInt64)
+	if r.loadFactor > 0 {
+		avg := float64(r.totalLoad+1) / float64(len(r.nodes))
+		capacity = int64(math.Ceil(avg * r.loadFactor))
+	}
+
+	start := r.search(h)
+	for i := 0; i < len(r.points); i++ {
+		node := r.points[(start+i)%len(r.points)].node
+		if r.loads[node] < capacity {
+			r.loads[node]++
+			r.totalLoad++
+			return node, nil
+		}
+	}
+	return "", ErrOverloaded
+}
+
+// Release decrements the load of node after a matching Acquire.
+func (r *Ring) Release(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loads[node] > 0 {
+		r.loads[node]--
+		r.totalLoad--
+	}
+}
+
+// Load returns the current load of node.
+func (r *Ring) Load(node string) int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loads[node]
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// search returns the index of the first point at or after h, wrapping around.
+// Caller must hold r.mu.
+func (r *Ring) search(h uint64) int {
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		return 0
+	}
+	return i
+}
+
+// keyPosition returns the ring position of key.
+func keyPosition(key []byte) (uint64, error) {
+	hash, err := tachyon.Hash(key)
+	if err != nil {
+		return 0, err
+	}
+	return position(hash), nil
+}
+
+// position maps a digest to a ring position.
+func position(d []byte) uint64 {
+	return binary.LittleEndian.Uint64(d[:8])
+}
@@ -0,0 +1,117 @@
+package hashring
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func keys(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = []byte(fmt.Sprintf("key-%d", i))
+	}
+	return out
+}
+
+func TestGetNode(t *testing.T) {
+	ring := New(100)
+	if _, err := ring.GetNode([]byte("k")); !errors.Is(err, ErrEmpty) {
+		t.Errorf("GetNode on empty ring = %v, want ErrEmpty", err)
+	}
+
+	for _, n := range []string{"a", "b", "c"} {
+		if err := ring.AddNode(n); err != nil {
+			t.Fatalf("AddNode failed: %v", err)
+		}
+	}
+	if err := ring.AddNode("a"); !errors.Is(err, ErrExists) {
+		t.Errorf("Duplicate AddNode = %v, want ErrExists", err)
+	}
+
+	counts := make(map[string]int)
+	for _, k := range keys(3000) {
+		node, err := ring.GetNode(k)
+		if err != nil {
+			t.Fatalf("GetNode failed: %v", err)
+		}
+		counts[node]++
+
+		again, _ := ring.GetNode(k)
+		if again != node {
+			t.Fatal("Same key should map to same node")
+		}
+	}
+
+	// Virtual nodes keep the distribution reasonably even
+	for n, c := range counts {
+		if c < 600 || c > 1400 {
+			t.Errorf("node %s got %d of 3000 keys", n, c)
+		}
+	}
+}
+
+func TestMinimalDisruption(t *testing.T) {
+	ring := New(100)
+	ring.AddNode("a")
+	ring.AddNode("b")
+	ring.AddNode("c")
+
+	before := make(map[string]string)
+	for _, k := range keys(2000) {
+		before[string(k)], _ = ring.GetNode(k)
+	}
+
+	ring.RemoveNode("b")
+	for _, k := range keys(2000) {
+		after, _ := ring.GetNode(k)
+		if prev := before[string(k)]; prev != "b" && after != prev {
+			t.Fatalf("key %s moved from %s to %s although its node stayed", k, prev, after)
+		}
+		if after == "b" {
+			t.Fatal("Removed node should not receive keys")
+		}
+	}
+
+	if got := ring.Nodes(); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("Nodes() = %v, want [a c]", got)
+	}
+}
+
+func TestBoundedLoad(t *testing.T) {
+	ring, err := NewBounded(50, 1.25)
+	if err != nil {
+		t.Fatalf("NewBounded failed: %v", err)
+	}
+	for _, n := range []string{"a", "b", "c", "d"} {
+		ring.AddNode(n)
+	}
+
+	assigned := make(map[string]int)
+	for _, k := range keys(1000) {
+		node, err := ring.Acquire(k)
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		assigned[node]++
+	}
+
+	// ceil(1.25 * 1000 / 4) = 313
+	for n, c := range assigned {
+		if c > 313 {
+			t.Errorf("node %s holds %d keys, above the bound", n, c)
+		}
+		if int64(c) != ring.Load(n) {
+			t.Errorf("Load(%s) = %d, want %d", n, ring.Load(n), c)
+		}
+	}
+
+	ring.Release("a")
+	if ring.Load("a") != int64(assigned["a"]-1) {
+		t.Error("Release should decrement the node load")
+	}
+
+	if _, err := NewBounded(10, 1); err == nil {
+		t.Error("Load factor 1 should return error")
+	}
+}
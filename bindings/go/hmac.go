@@ -0,0 +1,38 @@
+package tachyon
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// ============================================================================
+// HMAC
+// ============================================================================
+
+// BlockSize is the size in bytes of Tachyon's compression block.
+//
+// This is the block size HMAC pads keys to.
+const BlockSize = 512
+
+// NewHMAC returns a hash.Hash computing HMAC-Tachyon with the given key.
+//
+// This is the standard HMAC construction (RFC 2104) instantiated with
+// Tachyon, for settings that mandate HMAC rather than a native keyed mode;
+// otherwise prefer HashKeyed. Compare MACs with hmac.Equal:
+//
+//	mac := tachyon.NewHMAC(key)
+//	mac.Write(message)
+//	ok := hmac.Equal(mac.Sum(nil), expectedMAC)
+func NewHMAC(key []byte) hash.Hash {
+	return hmac.New(newStdHash, key)
+}
+
+// newStdHash returns a Hasher as a hash.Hash; its Sum does not consume the
+// state, as HMAC requires.
+func newStdHash() hash.Hash {
+	h := NewHasher()
+	if h == nil {
+		panic("tachyon: could not create hasher")
+	}
+	return h
+}
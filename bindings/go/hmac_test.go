@@ -0,0 +1,72 @@
+package tachyon
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"testing"
+)
+
+// referenceHMAC computes H((K ^ opad) || H((K ^ ipad) || m)) from the one-shot API.
+func referenceHMAC(key, msg []byte) []byte {
+	if len(key) > BlockSize {
+		key, _ = Hash(key)
+	}
+	k := make([]byte, BlockSize)
+	copy(k, key)
+
+	inner := make([]byte, 0, BlockSize+len(msg))
+	outer := make([]byte, 0, BlockSize+DigestSize)
+	for _, b := range k {
+		inner = append(inner, b^0x36)
+		outer = append(outer, b^0x5c)
+	}
+	innerHash, _ := Hash(append(inner, msg...))
+	mac, _ := Hash(append(outer, innerHash...))
+	return mac
+}
+
+func TestHMACConstruction(t *testing.T) {
+	msg := []byte("The quick brown fox jumps over the lazy dog")
+	for _, key := range [][]byte{
+		nil,
+		[]byte("key"),
+		bytes.Repeat([]byte{0xaa}, BlockSize),
+		bytes.Repeat([]byte{0xbb}, BlockSize+1), // Hashed down first
+	} {
+		mac := NewHMAC(key)
+		mac.Write(msg)
+		if !hmac.Equal(mac.Sum(nil), referenceHMAC(key, msg)) {
+			t.Errorf("HMAC with %d-byte key differs from the reference construction", len(key))
+		}
+	}
+}
+
+func TestHMACHashInterface(t *testing.T) {
+	mac := NewHMAC([]byte("key"))
+	if mac.Size() != DigestSize || mac.BlockSize() != BlockSize {
+		t.Errorf("Size/BlockSize = %d/%d, want %d/%d", mac.Size(), mac.BlockSize(), DigestSize, BlockSize)
+	}
+
+	// Sum does not consume: writing may continue
+	mac.Write([]byte("part 1, "))
+	first := mac.Sum(nil)
+	if !bytes.Equal(mac.Sum(nil), first) {
+		t.Error("Repeated Sum should be stable")
+	}
+	mac.Write([]byte("part 2"))
+	if !bytes.Equal(mac.Sum(nil), referenceHMAC([]byte("key"), []byte("part 1, part 2"))) {
+		t.Error("Writes after Sum should continue the message")
+	}
+
+	mac.Reset()
+	mac.Write([]byte("fresh"))
+	if !bytes.Equal(mac.Sum(nil), referenceHMAC([]byte("key"), []byte("fresh"))) {
+		t.Error("Reset should restart the message under the same key")
+	}
+
+	other := NewHMAC([]byte("other key"))
+	other.Write([]byte("fresh"))
+	if hmac.Equal(other.Sum(nil), mac.Sum(nil)) {
+		t.Error("Different keys should produce different MACs")
+	}
+}
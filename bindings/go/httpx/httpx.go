@@ -0,0 +1,362 @@
+// Package httpx computes and verifies RFC 9530 Content-Digest headers with
+// Tachyon.
+//
+// Bodies are hashed as they stream through, never buffered. A digest that is
+// known before the body is sent goes in the Content-Digest header; otherwise
+// it follows the body as an HTTP trailer, which RFC 9530 permits. Receivers
+// accept either.
+//
+// The algorithm token is "tachyon" and the value is the 32-byte Tachyon
+// digest of the content (tachyon.Hash), as a structured-field byte sequence:
+//
+//	Content-Digest: tachyon=:<base64 digest>:
+//
+// Example:
+//
+//	client := &http.Client{Transport: httpx.NewTransport(nil, httpx.Options{})}
+//	http.Handle("/upload", httpx.Middleware(uploadHandler, httpx.Options{Require: true}))
+package httpx
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"tachyon"
+)
+
+// ============================================================================
+// FIELD
+// ============================================================================
+
+// Algorithm is the Content-Digest algorithm token for Tachyon digests.
+const Algorithm = "tachyon"
+
+// HeaderName is the RFC 9530 field carrying the digest.
+const HeaderName = "Content-Digest"
+
+var (
+	// ErrDigestMismatch is returned at the end of a body that does not match
+	// its Content-Digest.
+	ErrDigestMismatch = errors.New("httpx: content does not match Content-Digest")
+
+	// ErrMissingDigest is returned when Options.Require is set and a message
+	// carries no tachyon Content-Digest.
+	ErrMissingDigest = errors.New("httpx: missing tachyon Content-Digest")
+)
+
+// Options configures NewTransport and Middleware.
+type Options struct {
+	// Require rejects messages that carry no tachyon Content-Digest. By
+	// default such messages pass unverified, and digests for other
+	// algorithms are ignored.
+	Require bool
+}
+
+// Format returns the Content-Digest field value for d.
+func Format(d tachyon.Digest) string {
+	return Algorithm + "=:" + base64.StdEncoding.EncodeToString(d[:]) + ":"
+}
+
+// Parse extracts the tachyon digest from a Content-Digest field value, which
+// may list several algorithms. ok is false if the value has no tachyon
+// member.
+func Parse(value string) (d tachyon.Digest, ok bool, err error) {
+	for _, member := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(member), "=")
+		if key != Algorithm {
+			continue
+		}
+		val, _, _ = strings.Cut(val, ";") // Parameters carry no meaning here
+		if len(val) < 2 || val[0] != ':' || val[len(val)-1] != ':' {
+			return d, false, fmt.Errorf("httpx: malformed %s digest %q", Algorithm, val)
+		}
+		raw, err := base64.StdEncoding.DecodeString(val[1 : len(val)-1])
+		if err != nil {
+			return d, false, fmt.Errorf("httpx: malformed %s digest: %w", Algorithm, err)
+		}
+		if d, err = tachyon.DigestFromBytes(raw); err != nil {
+			return d, false, err
+		}
+		return d, true, nil
+	}
+	return d, false, nil
+}
+
+// lookup finds the tachyon digest in the header, then the trailer.
+func lookup(header, trailer http.Header) (tachyon.Digest, bool, error) {
+	for _, h := range []http.Header{header, trailer} {
+		if values := h.Values(HeaderName); len(values) > 0 {
+			if d, ok, err := Parse(strings.Join(values, ",")); ok || err != nil {
+				return d, ok, err
+			}
+		}
+	}
+	return tachyon.Digest{}, false, nil
+}
+
+// announced reports whether a message may carry a digest: in the header, or
+// in a trailer it declared.
+func announced(header, trailer http.Header) bool {
+	if _, ok := trailer[HeaderName]; ok {
+		return true
+	}
+	_, ok, _ := lookup(header, nil)
+	return ok
+}
+
+// hasContent reports whether a response to method with status code carries
+// content.
+func hasContent(method string, code int) bool {
+	return method != http.MethodHead && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+// ============================================================================
+// HASHING READER
+// ============================================================================
+
+// hashingReader hashes a body as it is read and calls onEOF with its digest
+// once the body is exhausted.
+type hashingReader struct {
+	r     io.ReadCloser
+	h     *tachyon.Hasher
+	onEOF func(tachyon.Digest) error
+	err   error // Sticky result of onEOF
+	done  bool
+}
+
+func newHashingReader(r io.ReadCloser, onEOF func(tachyon.Digest) error) *hashingReader {
+	return &hashingReader{r: r, h: tachyon.NewHasher(), onEOF: onEOF}
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	if r.done {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	n, err := r.r.Read(p)
+	if uerr := r.h.Update(p[:n]); uerr != nil {
+		return n, uerr
+	}
+	if err == io.EOF {
+		r.done = true
+		var d tachyon.Digest
+		if r.err = r.h.SumInto(&d); r.err == nil {
+			r.err = r.onEOF(d)
+		}
+		if r.err != nil {
+			return n, r.err
+		}
+	}
+	return n, err
+}
+
+func (r *hashingReader) Close() error {
+	r.h.Close()
+	return r.r.Close()
+}
+
+// verify returns an onEOF that checks a digest against the one a message
+// carries in header or trailer.
+func verify(header, trailer http.Header, require bool) func(tachyon.Digest) error {
+	return func(got tachyon.Digest) error {
+		want, ok, err := lookup(header, trailer)
+		switch {
+		case err != nil:
+			return err
+		case !ok && require:
+			return ErrMissingDigest
+		case ok && want != got:
+			return ErrDigestMismatch
+		}
+		return nil
+	}
+}
+
+// ============================================================================
+// CLIENT
+// ============================================================================
+
+// transport adds and verifies Content-Digest on client requests.
+type transport struct {
+	base http.RoundTripper
+	opts Options
+}
+
+// NewTransport returns a RoundTripper that sends a Content-Digest with every
+// request body and verifies the Content-Digest of responses. base defaults to
+// http.DefaultTransport.
+//
+// A request body that can be replayed (Request.GetBody is set, as for bytes,
+// strings and buffers) is hashed once up front and the digest sent as a
+// header. Otherwise the body is hashed while it is sent, chunked, and the
+// digest follows as a trailer.
+//
+// A response body returns ErrDigestMismatch instead of io.EOF if its content
+// does not match. Responses the Transport transparently decompressed
+// (Response.Uncompressed) cannot be verified and pass as is.
+func NewTransport(base http.RoundTripper, opts Options) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, opts: opts}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out, err := t.sign(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(out)
+	if err != nil || resp.Uncompressed || !hasContent(req.Method, resp.StatusCode) {
+		return resp, err
+	}
+	if t.opts.Require && !announced(resp.Header, resp.Trailer) {
+		resp.Body.Close()
+		return nil, ErrMissingDigest
+	}
+	resp.Body = newHashingReader(resp.Body, verify(resp.Header, resp.Trailer, t.opts.Require))
+	return resp, nil
+}
+
+// sign returns a copy of req carrying the digest of its body.
+func (t *transport) sign(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	out := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		sum, err := tachyon.HashReaderContext(req.Context(), body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out.Header.Set(HeaderName, Format(tachyon.Digest(sum)))
+		return out, nil
+	}
+
+	out.ContentLength = -1 // Trailers need chunked encoding
+	out.Trailer = http.Header{HeaderName: nil}
+	for k, v := range req.Trailer {
+		out.Trailer[k] = v
+	}
+	out.Body = newHashingReader(req.Body, func(d tachyon.Digest) error {
+		out.Trailer.Set(HeaderName, Format(d))
+		return nil
+	})
+	return out, nil
+}
+
+// ============================================================================
+// SERVER
+// ============================================================================
+
+// Middleware verifies the Content-Digest of request bodies and sends a
+// Content-Digest trailer with every response.
+//
+// A request body returns ErrDigestMismatch instead of io.EOF if its content
+// does not match, so handlers see the error from the read that completes the
+// body. With Options.Require, requests without a tachyon digest are rejected
+// with 400 before the handler runs.
+//
+// Responses announce the trailer and are therefore sent chunked over
+// HTTP/1.1; a Content-Length the handler sets is removed. Handlers that set
+// Content-Digest themselves keep their own value.
+func Middleware(next http.Handler, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			if opts.Require && !announced(r.Header, r.Trailer) {
+				http.Error(w, ErrMissingDigest.Error(), http.StatusBadRequest)
+				return
+			}
+			body := newHashingReader(r.Body, verify(r.Header, r.Trailer, opts.Require))
+			defer body.h.Close()
+			r.Body = body
+		}
+
+		dw := &digestWriter{ResponseWriter: w, method: r.Method, h: tachyon.NewHasher()}
+		defer dw.h.Close()
+		next.ServeHTTP(dw, r)
+		dw.finish()
+	})
+}
+
+// digestWriter hashes a response body and sets its Content-Digest trailer.
+type digestWriter struct {
+	http.ResponseWriter
+	method      string
+	h           *tachyon.Hasher
+	wroteHeader bool
+	hashing     bool
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code) // Informational, more headers follow
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	w.hashing = hasContent(w.method, code) && header.Get(HeaderName) == ""
+	if w.hashing {
+		header.Add("Trailer", HeaderName)
+		header.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *digestWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(p)
+	if w.hashing {
+		if uerr := w.h.Update(p[:n]); uerr != nil && err == nil {
+			err = uerr
+		}
+	}
+	return n, err
+}
+
+// Flush forwards to the underlying writer if it supports flushing.
+func (w *digestWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *digestWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sets the trailer once the handler has returned.
+func (w *digestWriter) finish() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.hashing {
+		return
+	}
+	var d tachyon.Digest
+	if err := w.h.SumInto(&d); err == nil {
+		w.Header().Set(HeaderName, Format(d))
+	}
+}
@@ -0,0 +1,160 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tachyon"
+)
+
+func digestOf(t *testing.T, data []byte) tachyon.Digest {
+	t.Helper()
+	d, err := tachyon.Sum(data)
+	if err != nil {
+		t.Fatalf("Sum failed: %v", err)
+	}
+	return d
+}
+
+func TestFormatParse(t *testing.T) {
+	d := digestOf(t, []byte("hello"))
+	value := "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:, " + Format(d) + ";note=1"
+	got, ok, err := Parse(value)
+	if err != nil || !ok || got != d {
+		t.Fatalf("Parse(%q) = %v, %v, %v", value, got, ok, err)
+	}
+
+	if _, ok, err := Parse("sha-256=:AAAA:"); ok || err != nil {
+		t.Error("Value without a tachyon member should not match")
+	}
+	for _, bad := range []string{"tachyon=abc", "tachyon=:!!:", "tachyon=:AAAA:"} {
+		if _, _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should return error", bad)
+		}
+	}
+}
+
+// echo returns the request body and its read error.
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Length", "999") // Removed by the middleware
+	w.Write(body)
+}
+
+func TestRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(Middleware(http.HandlerFunc(echo), Options{Require: true}))
+	defer srv.Close()
+	client := &http.Client{Transport: NewTransport(nil, Options{Require: true})}
+
+	payload := bytes.Repeat([]byte("payload "), 100000)
+	bodies := map[string]io.Reader{
+		"header":  bytes.NewReader(payload),                 // GetBody set
+		"trailer": io.MultiReader(bytes.NewReader(payload)), // Streamed
+	}
+	for name, body := range bodies {
+		resp, err := client.Post(srv.URL, "application/octet-stream", body)
+		if err != nil {
+			t.Fatalf("%s: Post failed: %v", name, err)
+		}
+		got, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: reading response failed: %v", name, err)
+		}
+		if resp.StatusCode != http.StatusOK || !bytes.Equal(got, payload) {
+			t.Fatalf("%s: status %d, %d bytes", name, resp.StatusCode, len(got))
+		}
+		want := Format(digestOf(t, payload))
+		if resp.Trailer.Get(HeaderName) != want {
+			t.Errorf("%s: trailer = %q, want %q", name, resp.Trailer.Get(HeaderName), want)
+		}
+	}
+}
+
+func TestServerRejects(t *testing.T) {
+	srv := httptest.NewServer(Middleware(http.HandlerFunc(echo), Options{Require: true}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "text/plain", strings.NewReader("unsigned"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for a missing digest", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("tampered"))
+	req.Header.Set(HeaderName, Format(digestOf(t, []byte("original"))))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	msg, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(string(msg), ErrDigestMismatch.Error()) {
+		t.Errorf("status = %d (%s), want the handler to see ErrDigestMismatch", resp.StatusCode, msg)
+	}
+}
+
+func TestClientDetectsMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.Header().Set(HeaderName, Format(digestOf(t, []byte("other"))))
+		}
+		io.WriteString(w, "body")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, Options{})}
+	resp, err := client.Get(srv.URL + "/bad")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("err = %v, want ErrDigestMismatch", err)
+	}
+
+	resp, err = client.Get(srv.URL + "/none")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("Unsigned response should pass without Require: %v", err)
+	}
+	resp.Body.Close()
+
+	strict := &http.Client{Transport: NewTransport(nil, Options{Require: true})}
+	if _, err := strict.Get(srv.URL + "/none"); !errors.Is(err, ErrMissingDigest) {
+		t.Errorf("err = %v, want ErrMissingDigest", err)
+	}
+}
+
+func TestNoContentResponses(t *testing.T) {
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), Options{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get("Trailer") != "" || rec.Header().Get(HeaderName) != "" {
+		t.Error("204 response should carry no digest")
+	}
+
+	rec = httptest.NewRecorder()
+	Middleware(http.HandlerFunc(echo), Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+	if rec.Header().Get(HeaderName) != "" {
+		t.Error("HEAD response should carry no digest")
+	}
+}
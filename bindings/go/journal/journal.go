@@ -0,0 +1,519 @@
+// Package journal implements a crash-safe, append-only manifest journal.
+//
+// A journal records path → digest updates (put and remove) as chained
+// records: each record commits to the chain digest of the record before it,
+// so reordering, dropping or editing records in the middle of the file is
+// detected on open. A record torn by a crash is discarded and truncated away.
+// Compaction rewrites the journal as one put per live path and atomically
+// replaces the file.
+//
+// Example:
+//
+//	j, err := journal.Open("manifest.tj", journal.Options{Sync: true})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer j.Close()
+//	j.Put("src/main.go", digest)
+//	j.Remove("old.txt")
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"tachyon"
+)
+
+// ============================================================================
+// FORMAT
+// ============================================================================
+
+// magic starts every journal file. The chain of the first record links to
+// Hash(magic).
+const magic = "TACHJRN1"
+
+// Op identifies the kind of journal record.
+type Op byte
+
+const (
+	// OpPut adds a path or changes its digest.
+	OpPut Op = 1
+	// OpRemove deletes a path.
+	OpRemove Op = 2
+)
+
+// maxPathLen bounds path length so a corrupt length cannot trigger a huge read.
+const maxPathLen = 1 << 16
+
+// ErrCorrupt is returned when a complete record fails chain verification.
+var ErrCorrupt = errors.New("journal: chain verification failed")
+
+// Record layout (little-endian):
+//
+//	op u8 | pathLen u32 | path | digest [32] | chain [32]
+//
+// chain = Hash(prevChain | op | pathLen | path | digest). Remove records
+// carry a zero digest.
+type record struct {
+	op     Op
+	path   string
+	digest tachyon.Digest
+}
+
+func (r record) encode(prev tachyon.Digest) ([]byte, tachyon.Digest, error) {
+	buf := make([]byte, 0, 1+4+len(r.path)+64)
+	buf = append(buf, byte(r.op))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(r.path)))
+	buf = append(buf, r.path...)
+	buf = append(buf, r.digest[:]...)
+
+	chain, err := chainDigest(prev, buf)
+	if err != nil {
+		return nil, chain, err
+	}
+	return append(buf, chain[:]...), chain, nil
+}
+
+func chainDigest(prev tachyon.Digest, body []byte) (tachyon.Digest, error) {
+	var d tachyon.Digest
+	sum, err := tachyon.Hash(append(prev[:], body...))
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], sum)
+	return d, nil
+}
+
+func genesis() (tachyon.Digest, error) {
+	var d tachyon.Digest
+	sum, err := tachyon.Hash([]byte(magic))
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], sum)
+	return d, nil
+}
+
+// ============================================================================
+// JOURNAL
+// ============================================================================
+
+// Options configures a Journal.
+type Options struct {
+	// Sync fsyncs after every appended record.
+	Sync bool
+
+	// CompactThreshold triggers automatic compaction once the journal holds
+	// more than CompactThreshold records and at least twice as many records
+	// as live paths. Zero disables automatic compaction.
+	CompactThreshold int
+
+	// CompactError, if set, is called with the error of a failed automatic
+	// compaction. The append that triggered it has still succeeded and the
+	// journal stays usable; compaction is retried on the next append.
+	CompactError func(error)
+}
+
+// Journal is an open manifest journal. A Journal is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	opts    Options
+	f       *os.File
+	state   map[string]tachyon.Digest
+	head    tachyon.Digest
+	records int
+	size    int64 // End of the last complete record
+	err     error // Set when a failed append could not be rolled back
+}
+
+// Open opens or creates the journal at path and replays it.
+//
+// A trailing partial record (from a crash mid-append) is truncated. Returns
+// ErrCorrupt if a complete record does not verify.
+func Open(path string, opts Options) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{path: path, opts: opts, f: f, state: make(map[string]tachyon.Digest)}
+	if err := j.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// replay reads the journal from the start, rebuilding state and head.
+func (j *Journal) replay() error {
+	head, err := genesis()
+	if err != nil {
+		return err
+	}
+	j.head = head
+
+	info, err := j.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		if _, err := j.f.Write([]byte(magic)); err != nil {
+			return err
+		}
+		j.size = int64(len(magic))
+		return j.sync()
+	}
+
+	r := bufio.NewReader(j.f)
+	hdr := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, hdr); err != nil || string(hdr) != magic {
+		return errors.New("journal: not a journal file")
+	}
+
+	valid := int64(len(magic))
+	for {
+		rec, raw, chain, err := readRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break // Clean end or torn trailing record
+		}
+		if err != nil {
+			return err
+		}
+
+		want, err := chainDigest(j.head, raw)
+		if err != nil {
+			return err
+		}
+		if want != chain {
+			return fmt.Errorf("%w at offset %d", ErrCorrupt, valid)
+		}
+
+		j.apply(rec)
+		j.head = chain
+		j.records++
+		valid += int64(len(raw) + 32)
+	}
+
+	if valid < info.Size() {
+		if err := j.f.Truncate(valid); err != nil {
+			return err
+		}
+	}
+	j.size = valid
+	_, err = j.f.Seek(valid, io.SeekStart)
+	return err
+}
+
+// readRecord reads one record, returning it with its raw body and chain.
+func readRecord(r *bufio.Reader) (record, []byte, tachyon.Digest, error) {
+	var rec record
+	var chain tachyon.Digest
+
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return rec, nil, chain, err
+	}
+	n := binary.LittleEndian.Uint32(hdr[1:])
+	if n > maxPathLen {
+		return rec, nil, chain, fmt.Errorf("%w: path length %d", ErrCorrupt, n)
+	}
+
+	raw := make([]byte, 5+int(n)+32)
+	copy(raw, hdr[:])
+	if _, err := io.ReadFull(r, raw[5:]); err != nil {
+		return rec, nil, chain, io.ErrUnexpectedEOF
+	}
+	if _, err := io.ReadFull(r, chain[:]); err != nil {
+		return rec, nil, chain, io.ErrUnexpectedEOF
+	}
+
+	rec.op = Op(hdr[0])
+	rec.path = string(raw[5 : 5+n])
+	copy(rec.digest[:], raw[5+n:])
+	if rec.op != OpPut && rec.op != OpRemove {
+		return rec, nil, chain, fmt.Errorf("%w: unknown op %d", ErrCorrupt, rec.op)
+	}
+	return rec, raw, chain, nil
+}
+
+func (j *Journal) apply(rec record) {
+	if rec.op == OpPut {
+		j.state[rec.path] = rec.digest
+	} else {
+		delete(j.state, rec.path)
+	}
+}
+
+// Put records that path now has digest d.
+func (j *Journal) Put(path string, d tachyon.Digest) error {
+	return j.append(record{op: OpPut, path: path, digest: d})
+}
+
+// Remove records that path no longer exists. Removing an unknown path is a no-op.
+func (j *Journal) Remove(path string) error {
+	j.mu.Lock()
+	_, ok := j.state[path]
+	j.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return j.append(record{op: OpRemove, path: path})
+}
+
+func (j *Journal) append(rec record) error {
+	if len(rec.path) > maxPathLen {
+		return errors.New("journal: path too long")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return errors.New("journal: closed")
+	}
+	if j.err != nil {
+		return j.err
+	}
+	buf, chain, err := rec.encode(j.head)
+	if err != nil {
+		return err
+	}
+	if err := j.write(buf); err != nil {
+		return err
+	}
+
+	j.apply(rec)
+	j.head = chain
+	j.records++
+
+	// The record is durable now, so a failed compaction must not fail the
+	// append and invite a duplicate retry
+	t := j.opts.CompactThreshold
+	if t > 0 && j.records > t && j.records >= 2*len(j.state) {
+		if err := j.compactLocked(); err != nil && j.opts.CompactError != nil {
+			j.opts.CompactError(err)
+		}
+	}
+	return nil
+}
+
+// write appends one encoded record. If the write or sync fails, the file is
+// truncated back to the last complete record so the next append does not
+// follow a partial one; if that fails too, the journal refuses further
+// appends until a successful Compact.
+func (j *Journal) write(buf []byte) error {
+	_, err := j.f.Write(buf)
+	if err == nil {
+		err = j.sync()
+	}
+	if err == nil {
+		j.size += int64(len(buf))
+		return nil
+	}
+	if rerr := j.rollback(); rerr != nil {
+		j.err = fmt.Errorf("journal: append failed and could not be rolled back: %w", err)
+	}
+	return err
+}
+
+// rollback truncates the file to the end of the last complete record.
+func (j *Journal) rollback() error {
+	if err := j.f.Truncate(j.size); err != nil {
+		return err
+	}
+	_, err := j.f.Seek(j.size, io.SeekStart)
+	return err
+}
+
+// Get returns the digest recorded for path.
+func (j *Journal) Get(path string) (tachyon.Digest, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	d, ok := j.state[path]
+	return d, ok
+}
+
+// Snapshot returns a copy of the current path → digest manifest.
+func (j *Journal) Snapshot() map[string]tachyon.Digest {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	out := make(map[string]tachyon.Digest, len(j.state))
+	for p, d := range j.state {
+		out[p] = d
+	}
+	return out
+}
+
+// Head returns the chain digest of the last record.
+//
+// The head depends on the full history and changes on compaction; use
+// StateDigest to compare manifests.
+func (j *Journal) Head() tachyon.Digest {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.head
+}
+
+// Records returns the number of records in the journal file.
+func (j *Journal) Records() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.records
+}
+
+// StateDigest returns a digest of the current manifest that is independent
+// of history: equal manifests have equal state digests.
+//
+// It hashes, in sorted path order, LE32(len(path)) | path | digest for each
+// entry under DomainFileChecksum.
+func (j *Journal) StateDigest() (tachyon.Digest, error) {
+	var d tachyon.Digest
+	snap := j.Snapshot()
+	paths := make([]string, 0, len(snap))
+	for p := range snap {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	hasher := tachyon.NewHasherWithDomain(tachyon.DomainFileChecksum)
+	if hasher == nil {
+		return d, errors.New("journal: could not create hasher")
+	}
+	var n [4]byte
+	for _, p := range paths {
+		binary.LittleEndian.PutUint32(n[:], uint32(len(p)))
+		digest := snap[p]
+		hasher.Update(n[:])
+		hasher.Update([]byte(p))
+		hasher.Update(digest[:])
+	}
+	sum, err := hasher.Finalize()
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], sum)
+	return d, nil
+}
+
+// ============================================================================
+// COMPACTION
+// ============================================================================
+
+// Compact rewrites the journal as one put record per live path.
+//
+// The new journal is written to a temporary file, synced and renamed over
+// the old one, so a crash leaves either the old or the new journal intact.
+// Compact also recovers a journal whose failed append could not be rolled
+// back.
+func (j *Journal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.compactLocked()
+}
+
+func (j *Journal) compactLocked() error {
+	if j.f == nil {
+		return errors.New("journal: closed")
+	}
+
+	tmpPath := j.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	head, err := genesis()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	paths := make([]string, 0, len(j.state))
+	for p := range j.state {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	w := bufio.NewWriter(tmp)
+	size, err := w.WriteString(magic)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	for _, p := range paths {
+		buf, chain, err := record{op: OpPut, path: p, digest: j.state[p]}.encode(head)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		n, err := w.Write(buf)
+		size += n
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		head = chain
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	j.f.Close()
+	j.f = tmp
+	j.head = head
+	j.records = len(paths)
+	j.size = int64(size)
+	j.err = nil
+
+	// Make the rename itself durable
+	return syncDir(filepath.Dir(j.path))
+}
+
+// syncDir fsyncs a directory, persisting entries renamed into it.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+func (j *Journal) sync() error {
+	if !j.opts.Sync {
+		return nil
+	}
+	return j.f.Sync()
+}
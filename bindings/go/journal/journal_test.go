@@ -0,0 +1,235 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tachyon"
+)
+
+func digestOf(s string) tachyon.Digest {
+	var d tachyon.Digest
+	sum, _ := tachyon.Hash([]byte(s))
+	copy(d[:], sum)
+	return d
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, err := Open(path, Options{Sync: true})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	j.Put("a.txt", digestOf("a1"))
+	j.Put("b.txt", digestOf("b1"))
+	j.Put("a.txt", digestOf("a2")) // Change
+	j.Remove("b.txt")
+	head := j.Head()
+	j.Close()
+
+	j, err = Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer j.Close()
+
+	if d, ok := j.Get("a.txt"); !ok || d != digestOf("a2") {
+		t.Error("a.txt should have its latest digest")
+	}
+	if _, ok := j.Get("b.txt"); ok {
+		t.Error("b.txt should be removed")
+	}
+	if j.Head() != head || j.Records() != 4 {
+		t.Errorf("Replay head/records = %v/%d, want %v/4", j.Head(), j.Records(), head)
+	}
+}
+
+func TestTornRecordTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, _ := Open(path, Options{})
+	j.Put("a.txt", digestOf("a"))
+	j.Put("b.txt", digestOf("b"))
+	j.Close()
+
+	// Simulate a crash halfway through the last record
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-10)
+
+	j, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open after torn write failed: %v", err)
+	}
+	if _, ok := j.Get("b.txt"); ok {
+		t.Error("Torn record should be discarded")
+	}
+	if _, ok := j.Get("a.txt"); !ok {
+		t.Error("Complete records should survive")
+	}
+
+	// Appending after recovery produces a valid journal
+	j.Put("c.txt", digestOf("c"))
+	j.Close()
+	j, err = Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer j.Close()
+	if _, ok := j.Get("c.txt"); !ok {
+		t.Error("Record appended after recovery should replay")
+	}
+}
+
+func TestFailedAppendRolledBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, _ := Open(path, Options{})
+	j.Put("a.txt", digestOf("a"))
+
+	// A short write leaves part of a record; rolling back must remove it so
+	// the next append lands on a record boundary
+	j.f.Write([]byte{byte(OpPut), 9, 0})
+	if err := j.rollback(); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	j.Put("b.txt", digestOf("b"))
+	j.Close()
+
+	j, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open after rollback failed: %v", err)
+	}
+	defer j.Close()
+	if j.Records() != 2 {
+		t.Errorf("Records = %d, want 2", j.Records())
+	}
+}
+
+func TestFailedAppendStopsJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, _ := Open(path, Options{})
+	j.Put("a.txt", digestOf("a"))
+
+	// A read-only handle fails both the write and the rollback
+	rw := j.f
+	defer rw.Close()
+	j.f, _ = os.Open(path)
+	if err := j.Put("b.txt", digestOf("b")); err == nil {
+		t.Fatal("Write to a read-only file should return error")
+	}
+	if err := j.Put("c.txt", digestOf("c")); err == nil {
+		t.Error("Append after an unrecoverable failure should return error")
+	}
+	if _, ok := j.Get("b.txt"); ok {
+		t.Error("Failed append should not change state")
+	}
+
+	// Compaction rewrites the journal from the in-memory state
+	if err := j.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if err := j.Put("c.txt", digestOf("c")); err != nil {
+		t.Errorf("Append after Compact failed: %v", err)
+	}
+	j.Close()
+
+	j, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer j.Close()
+	if _, ok := j.Get("c.txt"); !ok || j.Records() != 2 {
+		t.Errorf("Reopened journal has %d records, want a.txt and c.txt", j.Records())
+	}
+}
+
+func TestTamperDetected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, _ := Open(path, Options{})
+	j.Put("a.txt", digestOf("a"))
+	j.Put("b.txt", digestOf("b"))
+	j.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(magic)+5] ^= 0xff // Flip a byte of the first path
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := Open(path, Options{}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open of edited journal = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	j, _ := Open(path, Options{CompactThreshold: 50})
+
+	for i := 0; i < 100; i++ {
+		j.Put(fmt.Sprintf("file-%d", i%5), digestOf(fmt.Sprint(i)))
+	}
+	before, _ := j.StateDigest()
+	if j.Records() > 50 {
+		t.Errorf("Records() = %d, automatic compaction should keep it bounded", j.Records())
+	}
+
+	if err := j.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if j.Records() != 5 {
+		t.Errorf("Records() after Compact = %d, want 5", j.Records())
+	}
+	after, _ := j.StateDigest()
+	if before != after {
+		t.Error("Compaction should not change the manifest")
+	}
+	j.Close()
+
+	j, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Reopen after compaction failed: %v", err)
+	}
+	defer j.Close()
+	reopened, _ := j.StateDigest()
+	if reopened != before || len(j.Snapshot()) != 5 {
+		t.Error("Compacted journal should replay to the same manifest")
+	}
+}
+
+func TestFailedAutoCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.tj")
+	var failures int
+	j, _ := Open(path, Options{CompactThreshold: 4, CompactError: func(error) { failures++ }})
+
+	// A directory in the way of the temporary file makes compaction fail
+	if err := os.Mkdir(path+".compact", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := j.Put("a.txt", digestOf(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Put %d failed: %v", i, err)
+		}
+	}
+	if failures == 0 {
+		t.Error("Failed compaction should be reported to CompactError")
+	}
+	if j.Records() != 6 {
+		t.Errorf("Records() = %d, want 6 appended records", j.Records())
+	}
+
+	// Once the obstacle is gone, the next append compacts
+	os.Remove(path + ".compact")
+	j.Put("a.txt", digestOf("last"))
+	if j.Records() != 1 {
+		t.Errorf("Records() = %d after compaction, want 1", j.Records())
+	}
+	j.Close()
+
+	j, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer j.Close()
+	if d, _ := j.Get("a.txt"); d != digestOf("last") {
+		t.Error("Reopened journal should hold the last digest")
+	}
+}
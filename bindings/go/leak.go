@@ -0,0 +1,75 @@
+package tachyon
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// ============================================================================
+// LEAK DETECTION
+// ============================================================================
+
+// A Hasher abandoned without Finalize or Close would leak its native state.
+// Every Hasher carries a finalizer that frees the state once the Hasher is
+// garbage collected; with a leak logger installed, the finalizer also
+// reports where the Hasher was created.
+
+var leakLogger atomic.Pointer[func(string)]
+
+// SetLeakLogger installs fn to be called with a report, including the
+// creation stack, for every Hasher garbage collected without Finalize or
+// Close. Pass nil to stop reporting.
+//
+// Only hashers created while a logger is installed record their stack, and
+// recording costs a stack walk per hasher, so use it for debugging.
+// fn runs on the finalizer goroutine and must not block.
+func SetLeakLogger(fn func(report string)) {
+	if fn == nil {
+		leakLogger.Store(nil)
+		return
+	}
+	leakLogger.Store(&fn)
+}
+
+// newHasher wraps state in a Hasher whose state is freed if it is leaked.
+func newHasher(state stateHandle, size int) *Hasher {
+	h := &Hasher{state: state, size: size}
+	if leakLogger.Load() != nil {
+		pcs := make([]uintptr, 32)
+		h.origin = pcs[:runtime.Callers(3, pcs)]
+	}
+	runtime.SetFinalizer(h, (*Hasher).collect)
+	return h
+}
+
+// collect frees the native state of an unreachable Hasher.
+func (h *Hasher) collect() {
+	if h.template != nil {
+		stateFree(h.template)
+		h.template = nil
+	}
+	if h.state == nil || h.finalized {
+		return
+	}
+	stateFree(h.state)
+	h.state = nil
+
+	if fn := leakLogger.Load(); fn != nil && h.origin != nil {
+		(*fn)(leakReport(h))
+	}
+}
+
+func leakReport(h *Hasher) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tachyon: Hasher leaked after %d bytes without Finalize or Close, created at:\n", h.n)
+	frames := runtime.CallersFrames(h.origin)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package tachyon
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+//go:noinline
+func leakHasher() {
+	h := NewHasher()
+	h.Update([]byte("never finalized"))
+}
+
+func TestLeakLogger(t *testing.T) {
+	reports := make(chan string, 16)
+	SetLeakLogger(func(report string) { reports <- report })
+	defer SetLeakLogger(nil)
+
+	leakHasher()
+
+	// Finalized hashers are not reported
+	NewHasher().Finalize()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case r := <-reports:
+			if !strings.Contains(r, "leakHasher") || !strings.Contains(r, "after 15 bytes") {
+				t.Errorf("report should name the creating function and size:\n%s", r)
+			}
+			return
+		case <-deadline:
+			t.Fatal("leaked Hasher was not reported")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
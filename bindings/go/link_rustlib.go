@@ -0,0 +1,20 @@
+//go:build tachyon_rustlib
+
+package tachyon
+
+// Link the Rust library built by `cargo build --release` instead of the
+// vendored C backend.
+
+/*
+#cgo LDFLAGS: -L${SRCDIR}/../../target/release -ltachyon
+*/
+import "C"
+
+// selectNative checks impl against the Rust dispatcher, which cannot be
+// pinned: only the kernel it selects itself is available.
+func selectNative(impl Implementation) error {
+	if impl != ImplAuto && impl.String() != nativeBackendName() {
+		return errUnavailable(impl)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package logship provides a tamper-evident envelope for shipping log batches.
+//
+// A Sender groups log lines into numbered batches. Every batch commits to the
+// digest of the batch before it (forming a hash chain) and carries a Tachyon
+// MAC over its own chain digest. A Receiver verifies the MAC, checks the chain
+// link and reports sequence gaps, so dropped, reordered, replayed or altered
+// batches are detected in transit.
+//
+// Example:
+//
+//	sender, _ := logship.NewSender(key, 100)
+//	if sender.Append(line) {
+//	    batch, _ := sender.Flush()
+//	    wire, _ := batch.MarshalBinary()
+//	    ship(wire)
+//	}
+//
+//	receiver, _ := logship.NewReceiver(key)
+//	var batch logship.Batch
+//	_ = batch.UnmarshalBinary(wire)
+//	lines, err := receiver.Open(&batch)
+package logship
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrTampered is returned when a batch's tag or chain link does not verify.
+	ErrTampered = errors.New("logship: batch failed verification")
+
+	// ErrReplayed is returned for a batch whose sequence number was already seen.
+	ErrReplayed = errors.New("logship: batch replayed or reordered")
+
+	errMalformed = errors.New("logship: malformed batch encoding")
+	errKeySize   = errors.New("logship: key must be 32 bytes")
+)
+
+// GapError reports batches missing between the last accepted batch and the
+// current one. The current batch itself verified and its lines are returned
+// alongside the error.
+type GapError struct {
+	Expected uint64 // Sequence number the receiver expected
+	Got      uint64 // Sequence number that arrived
+}
+
+func (e *GapError) Error() string {
+	return fmt.Sprintf("logship: %d batch(es) missing (expected seq %d, got %d)",
+		e.Got-e.Expected, e.Expected, e.Got)
+}
+
+// ============================================================================
+// BATCH
+// ============================================================================
+
+// chainLabel prefixes every chain digest so it cannot collide with other
+// hashes of the same bytes.
+const chainLabel = "tachyon-logship-v1"
+
+// Batch is a sealed group of log lines.
+type Batch struct {
+	Seq   uint64         // Position in the stream, starting at 0
+	Prev  tachyon.Digest // Chain digest of the previous batch (zero for the first)
+	Lines [][]byte       // Log lines in order
+	Tag   [32]byte       // MAC over the batch's chain digest
+}
+
+// Digest returns the batch's chain digest, which the next batch links to.
+func (b *Batch) Digest() (tachyon.Digest, error) {
+	var d tachyon.Digest
+	hasher := tachyon.NewHasher()
+	if hasher == nil {
+		return d, errors.New("logship: could not create hasher")
+	}
+	defer hasher.Close()
+
+	var hdr [16]byte
+	binary.LittleEndian.PutUint64(hdr[:8], b.Seq)
+	binary.LittleEndian.PutUint64(hdr[8:], uint64(len(b.Lines)))
+	hasher.Update([]byte(chainLabel))
+	hasher.Update(b.Prev[:])
+	hasher.Update(hdr[:])
+
+	var n [8]byte
+	for _, line := range b.Lines {
+		binary.LittleEndian.PutUint64(n[:], uint64(len(line)))
+		hasher.Update(n[:])
+		hasher.Update(line)
+	}
+
+	sum, err := hasher.Finalize()
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], sum)
+	return d, nil
+}
+
+// MarshalBinary encodes the batch for transport.
+//
+// Layout (little-endian): seq u64 | prev [32] | count u32 |
+// count × (len u32 | line) | tag [32].
+func (b *Batch) MarshalBinary() ([]byte, error) {
+	size := 8 + 32 + 4 + 32
+	for _, line := range b.Lines {
+		size += 4 + len(line)
+	}
+
+	out := make([]byte, 0, size)
+	out = binary.LittleEndian.AppendUint64(out, b.Seq)
+	out = append(out, b.Prev[:]...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(b.Lines)))
+	for _, line := range b.Lines {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(line)))
+		out = append(out, line...)
+	}
+	out = append(out, b.Tag[:]...)
+	return out, nil
+}
+
+// UnmarshalBinary decodes a batch produced by MarshalBinary.
+//
+// Decoding does not verify anything; pass the batch to Receiver.Open.
+func (b *Batch) UnmarshalBinary(data []byte) error {
+	if len(data) < 8+32+4+32 {
+		return errMalformed
+	}
+	b.Seq = binary.LittleEndian.Uint64(data)
+	copy(b.Prev[:], data[8:40])
+	count := binary.LittleEndian.Uint32(data[40:])
+	rest := data[44:]
+
+	b.Lines = make([][]byte, 0, min(int(count), len(rest)/4))
+	for i := uint32(0); i < count; i++ {
+		if len(rest) < 4 {
+			return errMalformed
+		}
+		n := binary.LittleEndian.Uint32(rest)
+		rest = rest[4:]
+		if uint64(len(rest)) < uint64(n) {
+			return errMalformed
+		}
+		b.Lines = append(b.Lines, append([]byte(nil), rest[:n]...))
+		rest = rest[n:]
+	}
+
+	if len(rest) != 32 {
+		return errMalformed
+	}
+	copy(b.Tag[:], rest)
+	return nil
+}
+
+// ============================================================================
+// SENDER
+// ============================================================================
+
+// Sender accumulates log lines and seals them into chained batches.
+//
+// A Sender is not safe for concurrent use.
+type Sender struct {
+	key      []byte
+	maxLines int
+	seq      uint64
+	prev     tachyon.Digest
+	pending  [][]byte
+}
+
+// NewSender creates a sender with a 32-byte MAC key.
+//
+// Append reports a batch as ready once maxLines lines are pending.
+func NewSender(key []byte, maxLines int) (*Sender, error) {
+	if len(key) != 32 {
+		return nil, errKeySize
+	}
+	if maxLines < 1 {
+		return nil, errors.New("logship: maxLines must be positive")
+	}
+	return &Sender{key: append([]byte(nil), key...), maxLines: maxLines}, nil
+}
+
+// Append queues a line and reports whether the batch is full and should be flushed.
+func (s *Sender) Append(line []byte) bool {
+	s.pending = append(s.pending, append([]byte(nil), line...))
+	return len(s.pending) >= s.maxLines
+}
+
+// Flush seals all pending lines into the next batch.
+//
+// Flushing with no pending lines produces an empty batch, which can serve as
+// a heartbeat so receivers detect gaps while the log is idle.
+func (s *Sender) Flush() (*Batch, error) {
+	b := &Batch{Seq: s.seq, Prev: s.prev, Lines: s.pending}
+
+	chain, err := b.Digest()
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tachyon.HashKeyed(chain[:], s.key)
+	if err != nil {
+		return nil, err
+	}
+	copy(b.Tag[:], tag)
+
+	s.seq++
+	s.prev = chain
+	s.pending = nil
+	return b, nil
+}
+
+// ============================================================================
+// RECEIVER
+// ============================================================================
+
+// Receiver verifies batches produced by a Sender sharing the same key.
+//
+// A Receiver is not safe for concurrent use.
+type Receiver struct {
+	key  []byte
+	next uint64
+	prev tachyon.Digest
+}
+
+// NewReceiver creates a receiver with a 32-byte MAC key.
+func NewReceiver(key []byte) (*Receiver, error) {
+	if len(key) != 32 {
+		return nil, errKeySize
+	}
+	return &Receiver{key: append([]byte(nil), key...)}, nil
+}
+
+// Open verifies a batch and returns its lines.
+//
+// Returns ErrTampered if the tag or chain link is invalid and ErrReplayed for
+// sequence numbers already accepted. If batches are missing, the lines are
+// returned together with a *GapError and the receiver resynchronizes on the
+// current batch.
+func (r *Receiver) Open(b *Batch) ([][]byte, error) {
+	chain, err := b.Digest()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := tachyon.VerifyMAC(chain[:], r.key, b.Tag[:])
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTampered
+	}
+
+	switch {
+	case b.Seq < r.next:
+		return nil, ErrReplayed
+	case b.Seq > r.next:
+		gap := &GapError{Expected: r.next, Got: b.Seq}
+		r.next = b.Seq + 1
+		r.prev = chain
+		return b.Lines, gap
+	case b.Prev != r.prev:
+		return nil, ErrTampered
+	}
+
+	r.next++
+	r.prev = chain
+	return b.Lines, nil
+}
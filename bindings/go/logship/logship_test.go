@@ -0,0 +1,125 @@
+package logship
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte("L"), 32)
+
+func ship(t *testing.T, s *Sender, lines ...string) *Batch {
+	t.Helper()
+	for _, line := range lines {
+		s.Append([]byte(line))
+	}
+	b, err := s.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// Round-trip through the wire format
+	wire, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	var decoded Batch
+	if err := decoded.UnmarshalBinary(wire); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	return &decoded
+}
+
+func TestChainVerifies(t *testing.T) {
+	sender, _ := NewSender(testKey, 2)
+	receiver, _ := NewReceiver(testKey)
+
+	if sender.Append([]byte("one")) {
+		t.Error("Batch should not be ready after one line")
+	}
+	if !sender.Append([]byte("two")) {
+		t.Error("Batch should be ready after maxLines lines")
+	}
+	b0, _ := sender.Flush()
+	b1 := ship(t, sender, "three")
+	b2 := ship(t, sender) // Heartbeat
+
+	for i, b := range []*Batch{b0, b1, b2} {
+		if _, err := receiver.Open(b); err != nil {
+			t.Fatalf("batch %d: Open failed: %v", i, err)
+		}
+	}
+
+	if len(b0.Lines) != 2 || string(b1.Lines[0]) != "three" || len(b2.Lines) != 0 {
+		t.Error("Batches should carry their lines")
+	}
+}
+
+func TestTamperDetected(t *testing.T) {
+	sender, _ := NewSender(testKey, 10)
+	receiver, _ := NewReceiver(testKey)
+
+	b := ship(t, sender, "login ok", "logout")
+	b.Lines[0] = []byte("login failed")
+	if _, err := receiver.Open(b); !errors.Is(err, ErrTampered) {
+		t.Errorf("Altered batch = %v, want ErrTampered", err)
+	}
+
+	// Wrong key
+	other, _ := NewReceiver(bytes.Repeat([]byte("X"), 32))
+	if _, err := other.Open(ship(t, sender, "line")); !errors.Is(err, ErrTampered) {
+		t.Errorf("Wrong key = %v, want ErrTampered", err)
+	}
+}
+
+func TestGapAndReplay(t *testing.T) {
+	sender, _ := NewSender(testKey, 10)
+	receiver, _ := NewReceiver(testKey)
+
+	b0 := ship(t, sender, "a")
+	_ = ship(t, sender, "b") // Dropped in transit
+	b2 := ship(t, sender, "c")
+	b3 := ship(t, sender, "d")
+
+	if _, err := receiver.Open(b0); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	lines, err := receiver.Open(b2)
+	var gap *GapError
+	if !errors.As(err, &gap) {
+		t.Fatalf("Open after drop = %v, want *GapError", err)
+	}
+	if gap.Expected != 1 || gap.Got != 2 {
+		t.Errorf("GapError = %+v, want Expected 1, Got 2", gap)
+	}
+	if len(lines) != 1 || string(lines[0]) != "c" {
+		t.Error("Gap batch lines should still be returned")
+	}
+
+	// Receiver resynchronized on b2
+	if _, err := receiver.Open(b3); err != nil {
+		t.Errorf("Open after resync failed: %v", err)
+	}
+	if _, err := receiver.Open(b2); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Replayed batch = %v, want ErrReplayed", err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	var b Batch
+	if err := b.UnmarshalBinary([]byte("short")); err == nil {
+		t.Error("Short encoding should return error")
+	}
+
+	sender, _ := NewSender(testKey, 10)
+	good := ship(t, sender, "line")
+	wire, _ := good.MarshalBinary()
+	if err := b.UnmarshalBinary(wire[:len(wire)-1]); err == nil {
+		t.Error("Truncated encoding should return error")
+	}
+
+	if _, err := NewSender([]byte("short"), 1); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+}
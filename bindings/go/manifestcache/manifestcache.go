@@ -0,0 +1,327 @@
+// Package manifestcache provides a stale-while-revalidate cache for remote
+// manifests.
+//
+// Manifests are fetched over HTTP, verified against the digest the server
+// announces, and stored keyed by their Tachyon digest (DomainContentAddressed,
+// matching package cas). Fresh copies are served from memory; once a copy
+// goes stale it is still served while a single background request refreshes
+// it, so callers never wait on the origin unless they have nothing to serve.
+//
+// Example:
+//
+//	cache := manifestcache.New(manifestcache.Options{MaxAge: time.Minute})
+//	m, err := cache.Get(ctx, "https://origin.example/releases/latest.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	use(m.Data)
+package manifestcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrDigestMismatch is returned when a fetched body does not match the
+	// digest announced by the server. The previously cached copy is kept.
+	ErrDigestMismatch = errors.New("manifestcache: manifest does not match announced digest")
+
+	// ErrMissingDigest is returned when RequireDigest is set and the server
+	// did not announce a digest.
+	ErrMissingDigest = errors.New("manifestcache: server did not announce a digest")
+)
+
+// StatusError reports a non-200 response from the origin.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("manifestcache: GET %s: status %d", e.URL, e.Code)
+}
+
+// ============================================================================
+// CACHE
+// ============================================================================
+
+// DefaultDigestHeader carries the hex Tachyon digest of the response body.
+const DefaultDigestHeader = "X-Tachyon-Digest"
+
+// DefaultMaxBytes bounds the size of a fetched manifest.
+const DefaultMaxBytes = 16 << 20
+
+// Options configures a Cache.
+type Options struct {
+	// Client performs fetches. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// MaxAge is how long a copy is fresh (served without revalidation).
+	// Defaults to one minute.
+	MaxAge time.Duration
+
+	// StaleFor is how long after MaxAge a stale copy is still served while
+	// refreshing in the background. Beyond that, Get fetches synchronously
+	// and falls back to the stale copy only if the fetch fails. Defaults to
+	// one hour.
+	StaleFor time.Duration
+
+	// DigestHeader names the response header announcing the body digest.
+	// Defaults to DefaultDigestHeader.
+	DigestHeader string
+
+	// RequireDigest rejects responses without a digest header.
+	RequireDigest bool
+
+	// MaxBytes bounds the manifest size. Defaults to DefaultMaxBytes.
+	MaxBytes int64
+}
+
+// Manifest is a cached manifest.
+type Manifest struct {
+	URL       string
+	Digest    tachyon.Digest
+	Data      []byte // Shared with the cache; do not modify
+	FetchedAt time.Time
+	Stale     bool // Served past MaxAge
+}
+
+// Cache is a stale-while-revalidate manifest cache. A Cache is safe for
+// concurrent use.
+type Cache struct {
+	opts Options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry        // URL → latest verified copy
+	blobs   map[tachyon.Digest]*blob // Digest → content, shared between URLs
+	flights map[string]*flight       // URL → in-progress fetch
+}
+
+type entry struct {
+	digest    tachyon.Digest
+	fetchedAt time.Time
+}
+
+type blob struct {
+	data []byte
+	refs int
+}
+
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// New creates an empty cache.
+func New(opts Options) *Cache {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = time.Minute
+	}
+	if opts.StaleFor <= 0 {
+		opts.StaleFor = time.Hour
+	}
+	if opts.DigestHeader == "" {
+		opts.DigestHeader = DefaultDigestHeader
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	return &Cache{
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+		blobs:   make(map[tachyon.Digest]*blob),
+		flights: make(map[string]*flight),
+	}
+}
+
+// Get returns the manifest at url.
+//
+//   - Fresh copies are returned directly.
+//   - Stale copies within StaleFor are returned immediately and refreshed in
+//     the background.
+//   - Otherwise the manifest is fetched; if that fails and a copy exists, the
+//     copy is returned (marked Stale) together with the fetch error.
+//
+// Concurrent fetches of the same URL share a single request.
+func (c *Cache) Get(ctx context.Context, url string) (Manifest, error) {
+	c.mu.Lock()
+	m, ok := c.lookup(url)
+	c.mu.Unlock()
+
+	if ok {
+		age := c.now().Sub(m.FetchedAt)
+		if age < c.opts.MaxAge {
+			return m, nil
+		}
+		if age < c.opts.MaxAge+c.opts.StaleFor {
+			m.Stale = true
+			c.revalidate(url)
+			return m, nil
+		}
+	}
+
+	err := c.fetchShared(ctx, url)
+
+	c.mu.Lock()
+	fresh, ok := c.lookup(url)
+	c.mu.Unlock()
+	if !ok {
+		return Manifest{}, err
+	}
+	if err != nil {
+		fresh.Stale = true
+	}
+	return fresh, err
+}
+
+// GetByDigest returns cached manifest content by digest.
+func (c *Cache) GetByDigest(d tachyon.Digest) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.blobs[d]
+	if !ok {
+		return nil, false
+	}
+	return b.data, true
+}
+
+// Invalidate drops the cached copy of url.
+func (c *Cache) Invalidate(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[url]; ok {
+		c.release(e.digest)
+		delete(c.entries, url)
+	}
+}
+
+// lookup returns the cached manifest for url. Caller holds c.mu.
+func (c *Cache) lookup(url string) (Manifest, bool) {
+	e, ok := c.entries[url]
+	if !ok {
+		return Manifest{}, false
+	}
+	return Manifest{URL: url, Digest: e.digest, Data: c.blobs[e.digest].data, FetchedAt: e.fetchedAt}, true
+}
+
+// revalidate refreshes url in the background unless a fetch is in flight.
+func (c *Cache) revalidate(url string) {
+	go c.fetchShared(context.Background(), url)
+}
+
+// fetchShared fetches url, joining an in-flight fetch if there is one.
+//
+// The fetch runs detached from ctx, so a caller giving up does not cancel it
+// for the callers that joined; each caller only stops waiting on its own ctx.
+// Client timeouts still bound the fetch.
+func (c *Cache) fetchShared(ctx context.Context, url string) error {
+	c.mu.Lock()
+	f, ok := c.flights[url]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		c.flights[url] = f
+		go c.fly(context.WithoutCancel(ctx), url, f)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fly performs the fetch of flight f and releases its waiters.
+func (c *Cache) fly(ctx context.Context, url string, f *flight) {
+	f.err = c.fetch(ctx, url)
+
+	c.mu.Lock()
+	delete(c.flights, url)
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// fetch downloads, verifies and stores url.
+func (c *Cache) fetch(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{URL: url, Code: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.opts.MaxBytes+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.opts.MaxBytes {
+		return fmt.Errorf("manifestcache: manifest at %s exceeds %d bytes", url, c.opts.MaxBytes)
+	}
+
+	sum, err := tachyon.HashWithDomain(data, tachyon.DomainContentAddressed)
+	if err != nil {
+		return err
+	}
+	digest := tachyon.Digest(sum)
+
+	if announced := resp.Header.Get(c.opts.DigestHeader); announced != "" {
+		want, err := tachyon.ParseDigest(announced)
+		if err != nil || want != digest {
+			return ErrDigestMismatch
+		}
+	} else if c.opts.RequireDigest {
+		return ErrMissingDigest
+	}
+
+	c.store(url, digest, data)
+	return nil
+}
+
+// store records data as the latest copy of url.
+func (c *Cache) store(url string, d tachyon.Digest, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, ok := c.blobs[d]; ok {
+		b.refs++
+	} else {
+		c.blobs[d] = &blob{data: data, refs: 1}
+	}
+	if old, ok := c.entries[url]; ok {
+		c.release(old.digest)
+	}
+	c.entries[url] = &entry{digest: d, fetchedAt: c.now()}
+}
+
+// release drops one reference to d. Caller holds c.mu.
+func (c *Cache) release(d tachyon.Digest) {
+	b := c.blobs[d]
+	if b.refs--; b.refs == 0 {
+		delete(c.blobs, d)
+	}
+}
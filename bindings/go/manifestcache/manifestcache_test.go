@@ -0,0 +1,174 @@
+package manifestcache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tachyon"
+)
+
+// origin serves a mutable manifest with its digest header.
+type origin struct {
+	mu       sync.Mutex
+	body     string
+	announce string // Overrides the digest header when set
+	hits     atomic.Int32
+}
+
+func (o *origin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.hits.Add(1)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	sum, _ := tachyon.HashWithDomain([]byte(o.body), tachyon.DomainContentAddressed)
+	digest := tachyon.Digest(sum).String()
+	if o.announce != "" {
+		digest = o.announce
+	}
+	w.Header().Set(DefaultDigestHeader, digest)
+	w.Write([]byte(o.body))
+}
+
+func (o *origin) set(body string) {
+	o.mu.Lock()
+	o.body = body
+	o.mu.Unlock()
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	o := &origin{body: `{"version":1}`}
+	srv := httptest.NewServer(o)
+	defer srv.Close()
+
+	clock := time.Unix(1_700_000_000, 0)
+	c := New(Options{MaxAge: time.Minute, StaleFor: time.Hour})
+	c.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	m, err := c.Get(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(m.Data) != `{"version":1}` || m.Stale {
+		t.Errorf("First Get = %q (stale %v)", m.Data, m.Stale)
+	}
+	if data, ok := c.GetByDigest(m.Digest); !ok || string(data) != `{"version":1}` {
+		t.Error("Manifest should be retrievable by digest")
+	}
+
+	// Fresh: served from memory
+	c.Get(ctx, srv.URL)
+	if o.hits.Load() != 1 {
+		t.Errorf("Fresh copy caused %d fetches, want 1", o.hits.Load())
+	}
+
+	// Stale: old copy served at once, refreshed in the background
+	o.set(`{"version":2}`)
+	clock = clock.Add(2 * time.Minute)
+	m, err = c.Get(ctx, srv.URL)
+	if err != nil || !m.Stale || string(m.Data) != `{"version":1}` {
+		t.Fatalf("Stale Get = %q (stale %v), %v", m.Data, m.Stale, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m, _ = c.Get(ctx, srv.URL)
+		if string(m.Data) == `{"version":2}` {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Background refresh did not complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := c.GetByDigest(tachyon.Digest{}); ok {
+		t.Error("Unknown digest should not be found")
+	}
+}
+
+func TestVerifyOnFetch(t *testing.T) {
+	o := &origin{body: "good"}
+	srv := httptest.NewServer(o)
+	defer srv.Close()
+
+	clock := time.Unix(1_700_000_000, 0)
+	c := New(Options{MaxAge: time.Minute, StaleFor: time.Minute})
+	c.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	good, err := c.Get(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// Past the stale window, a tampered response is rejected and the last
+	// verified copy is served
+	o.set("evil")
+	o.announce = good.Digest.String()
+	clock = clock.Add(time.Hour)
+	m, err := c.Get(ctx, srv.URL)
+	if !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Get of tampered manifest = %v, want ErrDigestMismatch", err)
+	}
+	if string(m.Data) != "good" || !m.Stale {
+		t.Errorf("Fallback = %q (stale %v), want last verified copy", m.Data, m.Stale)
+	}
+
+	c.Invalidate(srv.URL)
+	if _, err := c.Get(ctx, srv.URL); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Get without cached copy = %v, want ErrDigestMismatch", err)
+	}
+	if _, ok := c.GetByDigest(good.Digest); ok {
+		t.Error("Invalidate should drop unreferenced content")
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	var status *StatusError
+	if _, err := c.Get(ctx, missing.URL); !errors.As(err, &status) || status.Code != 404 {
+		t.Errorf("Get of missing manifest = %v, want StatusError 404", err)
+	}
+}
+
+func TestCanceledCallerDoesNotFailOthers(t *testing.T) {
+	o := &origin{body: `{"version":1}`}
+	srv := httptest.NewServer(o)
+	defer srv.Close()
+	c := New(Options{})
+
+	// Hold the origin so the first fetch stays in flight
+	o.mu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Get(ctx, srv.URL)
+		first <- err
+	}()
+	for o.hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Get(context.Background(), srv.URL)
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond) // Let the second caller join
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("Canceled caller = %v, want context.Canceled", err)
+	}
+	o.mu.Unlock()
+	if err := <-second; err != nil {
+		t.Errorf("Joined caller failed: %v", err)
+	}
+	if n := o.hits.Load(); n != 1 {
+		t.Errorf("Origin hit %d times, want one shared fetch", n)
+	}
+}
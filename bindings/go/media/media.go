@@ -0,0 +1,209 @@
+// Package media computes container-independent digests of media files.
+//
+// A container digest changes whenever a file is remuxed, even though the
+// audio and video it carries are bit-for-bit the same. An essence digest
+// hashes the demuxed streams instead, so an MKV and an MP4 carrying the same
+// streams compare equal. Demuxing is pluggable: callers supply a Demuxer for
+// their container formats (typically a thin adapter over an existing
+// demuxing library).
+//
+// Example:
+//
+//	d, err := mp4demux.Open(f) // Any Demuxer implementation
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	res, err := media.EssenceDigest(d, media.Options{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(res.Digest)
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"tachyon"
+)
+
+// ============================================================================
+// DEMUXER INTERFACE
+// ============================================================================
+
+// StreamInfo describes one elementary stream of a container.
+//
+// Kind and Codec are part of the digest, so demuxers for different
+// containers must report the same names for the same essence, e.g. "video"
+// and "h264".
+type StreamInfo struct {
+	Index int    // Stream index used by Packet.Stream
+	Kind  string // "video", "audio", "subtitle", ...
+	Codec string // Codec identifier, e.g. "h264", "aac"
+}
+
+// Packet is one unit of demuxed essence.
+type Packet struct {
+	Stream int    // StreamInfo.Index of the stream the packet belongs to
+	Data   []byte // Payload; only valid until the next ReadPacket
+}
+
+// Demuxer extracts elementary streams from a container.
+type Demuxer interface {
+	// Streams lists the streams of the container.
+	Streams() []StreamInfo
+
+	// ReadPacket returns the next packet in container order, or io.EOF
+	// after the last one.
+	ReadPacket() (Packet, error)
+}
+
+// Opener opens a Demuxer over container bytes.
+type Opener func(r io.Reader) (Demuxer, error)
+
+// ============================================================================
+// ESSENCE DIGEST
+// ============================================================================
+
+// Options configures EssenceDigest.
+type Options struct {
+	// Include selects the streams to digest. Defaults to all streams; use it
+	// to skip streams that only some containers carry (e.g. timecode or
+	// chapter tracks).
+	Include func(StreamInfo) bool
+}
+
+// StreamDigest is the digest of one stream's essence.
+type StreamDigest struct {
+	StreamInfo
+	Digest tachyon.Digest
+	Bytes  int64 // Payload bytes hashed
+}
+
+// Result is the essence digest of a container.
+type Result struct {
+	Digest  tachyon.Digest
+	Streams []StreamDigest // In canonical (digest) order, not container order
+}
+
+// Personalization separates essence digests from all other Tachyon hashes.
+const Personalization = "tachyon media essence v1"
+
+// EssenceDigest hashes the streams read from d.
+//
+// The digest is specified so other implementations can reproduce it:
+//
+//	stream = Hash(payload_0 || payload_1 || ..., WithPersonalization(Personalization))
+//	entry  = LE32(len(kind)) || kind || LE32(len(codec)) || codec || stream
+//	digest = Hash(entry_0 || entry_1 || ..., WithPersonalization(Personalization))
+//
+// Payloads are concatenated without framing, so repacketization does not
+// change a stream's digest, and entries are sorted bytewise, so neither does
+// track order. Timestamps and container metadata are not hashed.
+func EssenceDigest(d Demuxer, opts Options) (Result, error) {
+	hashers := make(map[int]*tachyon.Hasher)
+	defer func() {
+		for _, h := range hashers {
+			h.Close()
+		}
+	}()
+
+	streams := make(map[int]*StreamDigest)
+	skipped := make(map[int]bool)
+	for _, info := range d.Streams() {
+		if _, dup := streams[info.Index]; dup || skipped[info.Index] {
+			return Result{}, fmt.Errorf("media: duplicate stream index %d", info.Index)
+		}
+		if opts.Include != nil && !opts.Include(info) {
+			skipped[info.Index] = true
+			continue
+		}
+		h, err := tachyon.New(tachyon.WithPersonalization(Personalization))
+		if err != nil {
+			return Result{}, err
+		}
+		hashers[info.Index] = h
+		streams[info.Index] = &StreamDigest{StreamInfo: info}
+	}
+
+	for {
+		pkt, err := d.ReadPacket()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		h, ok := hashers[pkt.Stream]
+		if !ok {
+			if skipped[pkt.Stream] {
+				continue
+			}
+			return Result{}, fmt.Errorf("media: packet for unknown stream %d", pkt.Stream)
+		}
+		if err := h.Update(pkt.Data); err != nil {
+			return Result{}, err
+		}
+		streams[pkt.Stream].Bytes += int64(len(pkt.Data))
+	}
+
+	type keyed struct {
+		entry []byte
+		sd    *StreamDigest
+	}
+	sorted := make([]keyed, 0, len(streams))
+	for index, sd := range streams {
+		sum, err := hashers[index].Finalize()
+		delete(hashers, index)
+		if err != nil {
+			return Result{}, err
+		}
+		sd.Digest = tachyon.Digest(sum)
+		sorted = append(sorted, keyed{entry(sd), sd})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if c := bytes.Compare(sorted[i].entry, sorted[j].entry); c != 0 {
+			return c < 0
+		}
+		return sorted[i].sd.Index < sorted[j].sd.Index
+	})
+
+	res := Result{Streams: make([]StreamDigest, 0, len(sorted))}
+	var all []byte
+	for _, k := range sorted {
+		res.Streams = append(res.Streams, *k.sd)
+		all = append(all, k.entry...)
+	}
+	sum, err := tachyon.Hash(all, tachyon.WithPersonalization(Personalization))
+	if err != nil {
+		return Result{}, err
+	}
+	res.Digest = tachyon.Digest(sum)
+	return res, nil
+}
+
+// DigestReader opens r with open and returns its essence digest.
+func DigestReader(r io.Reader, open Opener, opts Options) (Result, error) {
+	if open == nil {
+		return Result{}, errors.New("media: no demuxer")
+	}
+	d, err := open(r)
+	if err != nil {
+		return Result{}, err
+	}
+	return EssenceDigest(d, opts)
+}
+
+// entry encodes one stream's contribution to the container digest.
+func entry(sd *StreamDigest) []byte {
+	e := make([]byte, 0, 8+len(sd.Kind)+len(sd.Codec)+tachyon.DigestSize)
+	e = binary.LittleEndian.AppendUint32(e, uint32(len(sd.Kind)))
+	e = append(e, sd.Kind...)
+	e = binary.LittleEndian.AppendUint32(e, uint32(len(sd.Codec)))
+	e = append(e, sd.Codec...)
+	return append(e, sd.Digest[:]...)
+}
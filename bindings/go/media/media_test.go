@@ -0,0 +1,178 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// sliceDemuxer replays fixed packets.
+type sliceDemuxer struct {
+	streams []StreamInfo
+	packets []Packet
+}
+
+func (d *sliceDemuxer) Streams() []StreamInfo { return d.streams }
+
+func (d *sliceDemuxer) ReadPacket() (Packet, error) {
+	if len(d.packets) == 0 {
+		return Packet{}, io.EOF
+	}
+	p := d.packets[0]
+	d.packets = d.packets[1:]
+	return p, nil
+}
+
+var (
+	video = bytes.Repeat([]byte("frame-data-"), 500)
+	audio = bytes.Repeat([]byte("pcm"), 700)
+)
+
+// packetize splits data into packets of size n for stream.
+func packetize(stream int, data []byte, n int) []Packet {
+	var out []Packet
+	for len(data) > 0 {
+		k := min(n, len(data))
+		out = append(out, Packet{Stream: stream, Data: data[:k]})
+		data = data[k:]
+	}
+	return out
+}
+
+// interleave merges packet lists round-robin.
+func interleave(lists ...[]Packet) []Packet {
+	var out []Packet
+	for more := true; more; {
+		more = false
+		for i := range lists {
+			if len(lists[i]) > 0 {
+				out = append(out, lists[i][0])
+				lists[i] = lists[i][1:]
+				more = true
+			}
+		}
+	}
+	return out
+}
+
+func mkvLike() *sliceDemuxer {
+	return &sliceDemuxer{
+		streams: []StreamInfo{{0, "video", "h264"}, {1, "audio", "aac"}},
+		packets: interleave(packetize(0, video, 100), packetize(1, audio, 64)),
+	}
+}
+
+func mp4Like() *sliceDemuxer {
+	return &sliceDemuxer{
+		streams: []StreamInfo{{1, "audio", "aac"}, {2, "video", "h264"}, {3, "data", "tmcd"}},
+		packets: append(append(packetize(2, video, 333), packetize(1, audio, 1000)...), Packet{Stream: 3, Data: []byte{1, 2, 3, 4}}),
+	}
+}
+
+func TestEssenceDigestRemux(t *testing.T) {
+	noData := Options{Include: func(s StreamInfo) bool { return s.Kind != "data" }}
+
+	a, err := EssenceDigest(mkvLike(), noData)
+	if err != nil {
+		t.Fatalf("EssenceDigest failed: %v", err)
+	}
+	b, err := EssenceDigest(mp4Like(), noData)
+	if err != nil {
+		t.Fatalf("EssenceDigest failed: %v", err)
+	}
+	if a.Digest != b.Digest {
+		t.Error("Remuxed essence should have the same digest")
+	}
+	if len(a.Streams) != 2 || len(b.Streams) != 2 {
+		t.Fatalf("got %d and %d streams, want 2", len(a.Streams), len(b.Streams))
+	}
+	for i := range a.Streams {
+		if a.Streams[i].Digest != b.Streams[i].Digest || a.Streams[i].Bytes != b.Streams[i].Bytes {
+			t.Errorf("stream %d should match across containers", i)
+		}
+	}
+
+	all, err := EssenceDigest(mp4Like(), Options{})
+	if err != nil {
+		t.Fatalf("EssenceDigest failed: %v", err)
+	}
+	if all.Digest == b.Digest {
+		t.Error("Extra stream should change the digest")
+	}
+}
+
+func TestEssenceDigestDetectsChanges(t *testing.T) {
+	base, _ := EssenceDigest(mkvLike(), Options{})
+
+	d := mkvLike()
+	d.packets[3].Data = append([]byte(nil), d.packets[3].Data...)
+	d.packets[3].Data[0] ^= 1
+	changed, _ := EssenceDigest(d, Options{})
+	if changed.Digest == base.Digest {
+		t.Error("Modified essence should change the digest")
+	}
+
+	d = mkvLike()
+	d.streams[1].Codec = "opus"
+	recoded, _ := EssenceDigest(d, Options{})
+	if recoded.Digest == base.Digest {
+		t.Error("Codec should be part of the digest")
+	}
+}
+
+func TestEssenceDigestErrors(t *testing.T) {
+	d := &sliceDemuxer{streams: []StreamInfo{{0, "video", "h264"}}, packets: []Packet{{Stream: 7}}}
+	if _, err := EssenceDigest(d, Options{}); err == nil {
+		t.Error("Packet for unknown stream should return error")
+	}
+
+	d = &sliceDemuxer{streams: []StreamInfo{{0, "video", "h264"}, {0, "audio", "aac"}}}
+	if _, err := EssenceDigest(d, Options{}); err == nil {
+		t.Error("Duplicate stream index should return error")
+	}
+}
+
+// openToy demuxes a toy container of "kind codec" header lines followed by a
+// blank line and "index payload" packet lines.
+func openToy(r io.Reader) (Demuxer, error) {
+	d := &sliceDemuxer{}
+	sc := bufio.NewScanner(r)
+	header := true
+	for sc.Scan() {
+		fields := bytes.Fields(sc.Bytes())
+		switch {
+		case header && len(fields) == 0:
+			header = false
+		case header && len(fields) == 2:
+			d.streams = append(d.streams, StreamInfo{len(d.streams), string(fields[0]), string(fields[1])})
+		case !header && len(fields) == 2 && len(fields[0]) == 1:
+			d.packets = append(d.packets, Packet{Stream: int(fields[0][0] - '0'), Data: fields[1]})
+		default:
+			return nil, errors.New("toy: malformed container")
+		}
+	}
+	return d, sc.Err()
+}
+
+func TestDigestReader(t *testing.T) {
+	a, err := DigestReader(bytes.NewBufferString("video h264\naudio aac\n\n0 abc\n1 xy\n0 def\n"), openToy, Options{})
+	if err != nil {
+		t.Fatalf("DigestReader failed: %v", err)
+	}
+	b, err := DigestReader(bytes.NewBufferString("audio aac\nvideo h264\n\n1 abcdef\n0 x\n0 y\n"), openToy, Options{})
+	if err != nil {
+		t.Fatalf("DigestReader failed: %v", err)
+	}
+	if a.Digest != b.Digest {
+		t.Error("Same essence in different containers should compare equal")
+	}
+
+	if _, err := DigestReader(bytes.NewBufferString("bogus line here\n"), openToy, Options{}); err == nil {
+		t.Error("Demuxer error should be returned")
+	}
+	if _, err := DigestReader(bytes.NewBufferString(""), nil, Options{}); err == nil {
+		t.Error("Missing demuxer should return error")
+	}
+}
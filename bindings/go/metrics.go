@@ -0,0 +1,170 @@
+package tachyon
+
+import (
+	"expvar"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// METRICS
+// ============================================================================
+
+// Engine call operations reported in Call.Op.
+const (
+	OpHash      = "hash"      // One-shot hash, any output size
+	OpExpand    = "expand"    // Expansion of a root digest
+	OpBatch     = "batch"     // Multi-seed or multi-MAC call
+	OpKeystream = "keystream" // Keystream generation
+	OpDeriveKey = "derive"    // Key derivation
+	OpUpdate    = "update"    // Streaming update
+	OpFinalize  = "finalize"  // Streaming finalization
+)
+
+// Call describes one engine call: with cgo, a single transition into the
+// native library.
+type Call struct {
+	Op      string        // One of the Op constants
+	Domain  uint64        // Domain the input was hashed under
+	Bytes   int           // Input bytes processed
+	Elapsed time.Duration // Time spent in the call
+}
+
+// MetricsCollector receives a Call after every engine call. It runs on the
+// hashing goroutine, possibly from many goroutines at once, and must be fast
+// and safe for concurrent use.
+type MetricsCollector interface {
+	ObserveCall(c Call)
+}
+
+var collector atomic.Pointer[MetricsCollector]
+
+// SetMetricsCollector installs c to observe every engine call. Pass nil to
+// stop collecting. Without a collector the overhead is one atomic load per
+// call; with one, two clock reads are added.
+func SetMetricsCollector(c MetricsCollector) {
+	if c == nil {
+		collector.Store(nil)
+		return
+	}
+	collector.Store(&c)
+}
+
+// metrics returns the installed collector, or nil.
+func metrics() MetricsCollector {
+	if c := collector.Load(); c != nil {
+		return *c
+	}
+	return nil
+}
+
+// observe reports a call that started at start. Engine primitives defer it
+// with start = time.Now() when a collector is installed.
+func observe(c MetricsCollector, op string, domain uint64, n int, start time.Time) {
+	c.ObserveCall(Call{
+		Op:      op,
+		Domain:  domain &^ (0xff << 56), // Drop the output-size separation
+		Bytes:   n,
+		Elapsed: time.Since(start),
+	})
+}
+
+// inputBytes returns the total length of inputs.
+func inputBytes(inputs [][]byte) int {
+	n := 0
+	for _, in := range inputs {
+		n += len(in)
+	}
+	return n
+}
+
+// ============================================================================
+// EXPVAR COLLECTOR
+// ============================================================================
+
+// latencyBounds are the upper bounds, in microseconds, of the latency
+// histogram buckets; slower calls fall into "inf".
+var latencyBounds = [...]int64{1, 4, 16, 64, 256, 1024, 4096, 16384}
+
+// latencyBuckets names the histogram buckets: the bounds, then "inf".
+var latencyBuckets = func() (names [len(latencyBounds) + 1]string) {
+	for i, bound := range latencyBounds {
+		names[i] = strconv.FormatInt(bound, 10)
+	}
+	names[len(latencyBounds)] = "inf"
+	return names
+}()
+
+// domainNames label the standard domains in per-domain counters.
+var domainNames = map[uint64]string{
+	DomainGeneric:          "generic",
+	DomainFileChecksum:     "file_checksum",
+	DomainKeyDerivation:    "key_derivation",
+	DomainMessageAuth:      "message_auth",
+	DomainDatabaseIndex:    "database_index",
+	DomainContentAddressed: "content_addressed",
+}
+
+// ExpvarMetrics is a MetricsCollector publishing counters through expvar,
+// and so on /debug/vars when net/http/pprof or expvar's handler is served:
+//
+//	bytes         input bytes hashed
+//	calls         engine calls, by Op
+//	domain_bytes  input bytes, by domain name (or number)
+//	latency_us    engine calls by latency bucket: calls taking at most
+//	              1, 4, 16, ... µs, or "inf"
+//
+// A rising share of slow calls under load points at cgo contention.
+type ExpvarMetrics struct {
+	bytes   *expvar.Int
+	calls   *expvar.Map
+	domains *expvar.Map
+	latency *expvar.Map
+}
+
+// NewExpvarMetrics creates an ExpvarMetrics published under name. Like
+// expvar.Publish, it panics if name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{
+		bytes:   new(expvar.Int),
+		calls:   new(expvar.Map).Init(),
+		domains: new(expvar.Map).Init(),
+		latency: new(expvar.Map).Init(),
+	}
+	for _, op := range []string{OpHash, OpExpand, OpBatch, OpKeystream, OpDeriveKey, OpUpdate, OpFinalize} {
+		m.calls.Add(op, 0)
+	}
+	for _, bucket := range latencyBuckets {
+		m.latency.Add(bucket, 0)
+	}
+
+	root := expvar.NewMap(name)
+	root.Set("bytes", m.bytes)
+	root.Set("calls", m.calls)
+	root.Set("domain_bytes", m.domains)
+	root.Set("latency_us", m.latency)
+	return m
+}
+
+// ObserveCall implements MetricsCollector.
+func (m *ExpvarMetrics) ObserveCall(c Call) {
+	m.bytes.Add(int64(c.Bytes))
+	m.calls.Add(c.Op, 1)
+
+	name, ok := domainNames[c.Domain]
+	if !ok {
+		name = strconv.FormatUint(c.Domain, 10)
+	}
+	m.domains.Add(name, int64(c.Bytes))
+
+	bucket := len(latencyBounds)
+	us := c.Elapsed.Microseconds()
+	for i, bound := range latencyBounds {
+		if us <= bound {
+			bucket = i
+			break
+		}
+	}
+	m.latency.Add(latencyBuckets[bucket], 1)
+}
@@ -0,0 +1,113 @@
+package tachyon
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// recorder collects every Call.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) ObserveCall(c Call) {
+	r.mu.Lock()
+	r.calls = append(r.calls, c)
+	r.mu.Unlock()
+}
+
+func TestMetricsCollector(t *testing.T) {
+	rec := &recorder{}
+	SetMetricsCollector(rec)
+	defer SetMetricsCollector(nil)
+
+	HashWithDomain(make([]byte, 1000), DomainFileChecksum)
+	Hash([]byte("abc"), WithOutputSize(Size128), WithDomain(DomainDatabaseIndex))
+	h := NewHasherWithDomain(DomainContentAddressed)
+	h.Update(make([]byte, 500))
+	h.Finalize()
+
+	want := []Call{
+		{Op: OpHash, Domain: DomainFileChecksum, Bytes: 1000},
+		{Op: OpHash, Domain: DomainDatabaseIndex, Bytes: 3},
+		{Op: OpUpdate, Domain: DomainContentAddressed, Bytes: 500},
+		{Op: OpFinalize, Domain: DomainContentAddressed},
+	}
+	if len(rec.calls) != len(want) {
+		t.Fatalf("got %d calls, want %d: %+v", len(rec.calls), len(want), rec.calls)
+	}
+	for i, c := range rec.calls {
+		c.Elapsed = 0
+		if c != want[i] {
+			t.Errorf("call %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+
+	SetMetricsCollector(nil)
+	Hash([]byte("unobserved"))
+	if len(rec.calls) != len(want) {
+		t.Error("Removed collector should not observe calls")
+	}
+}
+
+// expvarRuns keeps expvar names unique across -count runs.
+var expvarRuns atomic.Int32
+
+func TestExpvarMetrics(t *testing.T) {
+	name := fmt.Sprintf("tachyon_test_%d", expvarRuns.Add(1))
+	m := NewExpvarMetrics(name)
+	SetMetricsCollector(m)
+	defer SetMetricsCollector(nil)
+
+	HashWithDomain(make([]byte, 100), DomainFileChecksum)
+	Hash(make([]byte, 20), WithDomain(77))
+
+	var vars struct {
+		Bytes       int64            `json:"bytes"`
+		Calls       map[string]int64 `json:"calls"`
+		DomainBytes map[string]int64 `json:"domain_bytes"`
+		LatencyUs   map[string]int64 `json:"latency_us"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &vars); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if vars.Bytes != 120 || vars.Calls[OpHash] != 2 {
+		t.Errorf("bytes = %d, hash calls = %d, want 120 and 2", vars.Bytes, vars.Calls[OpHash])
+	}
+	if vars.DomainBytes["file_checksum"] != 100 || vars.DomainBytes["77"] != 20 {
+		t.Errorf("domain_bytes = %v", vars.DomainBytes)
+	}
+	total := int64(0)
+	for _, n := range vars.LatencyUs {
+		total += n
+	}
+	if total != 2 || len(vars.LatencyUs) != len(latencyBuckets) {
+		t.Errorf("latency_us = %v, want 2 calls over all buckets", vars.LatencyUs)
+	}
+}
+
+// benchMetrics is shared across benchmark runs: expvar names are global.
+var benchMetrics = sync.OnceValue(func() *ExpvarMetrics { return NewExpvarMetrics("tachyon_bench") })
+
+func BenchmarkHashMetrics(b *testing.B) {
+	data := make([]byte, 64)
+	b.Run("Off", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Sum(data)
+		}
+	})
+	b.Run("Expvar", func(b *testing.B) {
+		SetMetricsCollector(benchMetrics())
+		defer SetMetricsCollector(nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Sum(data)
+		}
+	})
+}
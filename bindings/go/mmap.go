@@ -0,0 +1,82 @@
+package tachyon
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ============================================================================
+// MEMORY-MAPPED INPUT
+// ============================================================================
+
+// ErrMappingFault is returned by HashMmap when the mapped region becomes
+// inaccessible while it is being read, typically because the underlying file
+// was truncated (SIGBUS) or unmapped.
+var ErrMappingFault = errors.New("tachyon: fault while reading memory-mapped region")
+
+// MmapOptions configures HashMmap.
+type MmapOptions struct {
+	// BlockSize is the number of bytes copied out of the mapping per step.
+	// Defaults to 1 MB; larger blocks hash faster, smaller blocks bound the
+	// extra memory used.
+	BlockSize int
+
+	// Domain is the hash domain (DomainGeneric by default), at most
+	// MaxDomain.
+	Domain uint64
+}
+
+// HashMmap hashes a memory-mapped region without risking a crash if the
+// backing file shrinks concurrently.
+//
+// Touching a page beyond the end of a truncated file raises SIGBUS, which
+// normally kills the process (and always does inside native code). HashMmap
+// therefore never hands the mapping to the native library: it copies the
+// region block by block in Go with debug.SetPanicOnFault enabled, turning a
+// fault into ErrMappingFault, and streams the copies into a native hasher.
+//
+// The result equals HashWithDomain(data, opts.Domain) for an intact mapping.
+func HashMmap(data []byte, opts MmapOptions) (hash []byte, err error) {
+	blockSize := opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = 1024 * 1024
+	}
+
+	if err := checkDomain(opts.Domain); err != nil {
+		return nil, err
+	}
+	hasher := NewHasherWithDomain(opts.Domain)
+	if hasher == nil {
+		return nil, errors.New("tachyon: could not create hasher")
+	}
+	defer hasher.Close()
+
+	buf := make([]byte, min(blockSize, len(data)))
+	for off := 0; off < len(data); off += blockSize {
+		end := min(off+blockSize, len(data))
+		if err := guardedCopy(buf[:end-off], data[off:end]); err != nil {
+			return nil, fmt.Errorf("%w at offset %d", err, off)
+		}
+		if err := hasher.Update(buf[:end-off]); err != nil {
+			return nil, err
+		}
+	}
+	return hasher.Finalize()
+}
+
+// guardedCopy copies src into dst, converting memory faults into ErrMappingFault.
+func guardedCopy(dst, src []byte) (err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(interface{ Addr() uintptr }); ok {
+				err = ErrMappingFault
+				return
+			}
+			panic(r)
+		}
+	}()
+	copy(dst, src)
+	return nil
+}
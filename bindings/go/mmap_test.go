@@ -0,0 +1,62 @@
+//go:build unix
+
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func mapFile(t *testing.T, content []byte) (*os.File, []byte) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mapped")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, len(content), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		t.Fatalf("Mmap failed: %v", err)
+	}
+	t.Cleanup(func() {
+		syscall.Munmap(data)
+		f.Close()
+	})
+	return f, data
+}
+
+func TestHashMmap(t *testing.T) {
+	content := bytes.Repeat([]byte("mapped!"), 100000)
+	_, data := mapFile(t, content)
+
+	got, err := HashMmap(data, MmapOptions{BlockSize: 64 * 1024, Domain: DomainFileChecksum})
+	if err != nil {
+		t.Fatalf("HashMmap failed: %v", err)
+	}
+	want, _ := HashWithDomain(content, DomainFileChecksum)
+	if !bytes.Equal(got, want) {
+		t.Error("HashMmap should match HashWithDomain")
+	}
+}
+
+func TestHashMmapTruncated(t *testing.T) {
+	content := bytes.Repeat([]byte{0x42}, 16*os.Getpagesize())
+	f, data := mapFile(t, content)
+
+	// Shrink the file under the mapping; pages past the end now SIGBUS
+	if err := f.Truncate(int64(os.Getpagesize())); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+
+	_, err := HashMmap(data, MmapOptions{BlockSize: os.Getpagesize()})
+	if !errors.Is(err, ErrMappingFault) {
+		t.Errorf("HashMmap on truncated mapping = %v, want ErrMappingFault", err)
+	}
+}
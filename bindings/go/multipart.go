@@ -0,0 +1,150 @@
+package tachyon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// MULTIPART DIGESTS
+// ============================================================================
+
+// DefaultPartSize is the part size HashMultipart uses by default (8 MB).
+const DefaultPartSize = 8 << 20
+
+// multipartPrefix starts the input of every composite digest.
+const multipartPrefix = "tachyon multipart v1\x00"
+
+// Multipart is the composite digest of a file hashed in parts, comparable to
+// an S3 multipart ETag.
+type Multipart struct {
+	Digest Digest   // Composite digest over all parts
+	Parts  []Digest // Part digests, in file order
+}
+
+// String returns the ETag-style form "<hex composite>-<number of parts>".
+func (m Multipart) String() string {
+	return m.Digest.String() + "-" + strconv.Itoa(len(m.Parts))
+}
+
+// ParseMultipart parses the String form of a Multipart into its composite
+// digest and part count.
+func ParseMultipart(s string) (Digest, int, error) {
+	hexDigest, count, ok := strings.Cut(s, "-")
+	if !ok {
+		return Digest{}, 0, errors.New("tachyon: multipart digest must be <hex>-<parts>")
+	}
+	d, err := ParseDigest(hexDigest)
+	if err != nil {
+		return Digest{}, 0, err
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 || count != strconv.Itoa(n) {
+		return Digest{}, 0, fmt.Errorf("tachyon: invalid multipart part count %q", count)
+	}
+	return d, n, nil
+}
+
+// HashPart computes the digest of one part, e.g. on an upload worker.
+//
+// It equals HashWithDomain(part, DomainFileChecksum).
+func HashPart(part []byte) (Digest, error) {
+	return hashDigest(part, DomainFileChecksum, 0, nil)
+}
+
+// CombineParts computes the composite digest of part digests in file order.
+//
+// The format is specified so other implementations can reproduce it:
+//
+//	part      = HashWithDomain(part bytes, DomainFileChecksum)
+//	input     = "tachyon multipart v1" || 0x00 || part_0 || ... || part_n-1
+//	composite = HashWithDomain(input, DomainFileChecksum)
+//
+// Each part digest commits to its part's length, so splitting the same file
+// differently gives a different composite, as with S3 ETags.
+func CombineParts(parts []Digest) (Multipart, error) {
+	if len(parts) == 0 {
+		return Multipart{}, errors.New("tachyon: multipart digest needs at least one part")
+	}
+	input := make([]byte, 0, len(multipartPrefix)+len(parts)*DigestSize)
+	input = append(input, multipartPrefix...)
+	for i := range parts {
+		input = append(input, parts[i][:]...)
+	}
+	d, err := hashDigest(input, DomainFileChecksum, 0, nil)
+	if err != nil {
+		return Multipart{}, err
+	}
+	return Multipart{Digest: d, Parts: append([]Digest(nil), parts...)}, nil
+}
+
+// MultipartOptions configures HashMultipart.
+type MultipartOptions struct {
+	// PartSize is the size of every part but the last. Defaults to
+	// DefaultPartSize.
+	PartSize int64
+
+	// Workers is the number of parts hashed concurrently. Defaults to
+	// GOMAXPROCS.
+	Workers int
+}
+
+// HashMultipart hashes the size bytes of r in parts of opts.PartSize,
+// concurrently, and combines them with CombineParts. An empty input is a
+// single empty part.
+//
+// Each worker holds one part in memory at a time.
+func HashMultipart(r io.ReaderAt, size int64, opts MultipartOptions) (Multipart, error) {
+	if size < 0 {
+		return Multipart{}, errors.New("tachyon: negative size")
+	}
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	count := max(1, int((size+partSize-1)/partSize))
+	workers = min(workers, count)
+	parts := make([]Digest, count)
+	errs := make([]error, count)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, min(partSize, size))
+			for i := range jobs {
+				off := int64(i) * partSize
+				part := buf[:min(partSize, size-off)]
+				if n, err := r.ReadAt(part, off); n < len(part) {
+					errs[i] = fmt.Errorf("tachyon: reading part %d: %w", i, err)
+					continue
+				}
+				parts[i], errs[i] = HashPart(part)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Multipart{}, err
+		}
+	}
+	return CombineParts(parts)
+}
@@ -0,0 +1,80 @@
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestHashMultipart(t *testing.T) {
+	data := make([]byte, 5*1000+123)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	m, err := HashMultipart(bytes.NewReader(data), int64(len(data)), MultipartOptions{PartSize: 1000, Workers: 3})
+	if err != nil {
+		t.Fatalf("HashMultipart failed: %v", err)
+	}
+	if len(m.Parts) != 6 {
+		t.Fatalf("got %d parts, want 6", len(m.Parts))
+	}
+
+	// Workers hashing parts independently reconcile to the same digest
+	var parts []Digest
+	for off := 0; off < len(data); off += 1000 {
+		d, err := HashPart(data[off:min(off+1000, len(data))])
+		if err != nil {
+			t.Fatalf("HashPart failed: %v", err)
+		}
+		want, _ := HashWithDomain(data[off:min(off+1000, len(data))], DomainFileChecksum)
+		if !bytes.Equal(d[:], want) {
+			t.Error("HashPart should equal HashWithDomain(DomainFileChecksum)")
+		}
+		parts = append(parts, d)
+	}
+	combined, err := CombineParts(parts)
+	if err != nil {
+		t.Fatalf("CombineParts failed: %v", err)
+	}
+	if combined.Digest != m.Digest {
+		t.Error("CombineParts should equal HashMultipart")
+	}
+
+	other, _ := HashMultipart(bytes.NewReader(data), int64(len(data)), MultipartOptions{PartSize: 2000})
+	if other.Digest == m.Digest {
+		t.Error("Different part sizes should give different composites")
+	}
+
+	d, n, err := ParseMultipart(m.String())
+	if err != nil || d != m.Digest || n != 6 {
+		t.Errorf("ParseMultipart(%q) = %v, %d, %v", m.String(), d, n, err)
+	}
+	for _, bad := range []string{m.Digest.String(), m.Digest.String() + "-0", m.Digest.String() + "-+6", "zz-1"} {
+		if _, _, err := ParseMultipart(bad); err == nil {
+			t.Errorf("ParseMultipart(%q) should return error", bad)
+		}
+	}
+}
+
+func TestHashMultipartEdgeCases(t *testing.T) {
+	empty, err := HashMultipart(bytes.NewReader(nil), 0, MultipartOptions{})
+	if err != nil {
+		t.Fatalf("HashMultipart failed: %v", err)
+	}
+	part, _ := HashPart(nil)
+	if want, _ := CombineParts([]Digest{part}); len(empty.Parts) != 1 || empty.Digest != want.Digest {
+		t.Error("Empty input should be a single empty part")
+	}
+
+	if _, err := CombineParts(nil); err == nil {
+		t.Error("CombineParts without parts should return error")
+	}
+
+	// Reading past the end of a short reader is an error
+	_, err = HashMultipart(bytes.NewReader([]byte("short")), 100, MultipartOptions{PartSize: 30})
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v, want io.EOF", err)
+	}
+}
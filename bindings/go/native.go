@@ -0,0 +1,52 @@
+//go:build !tachyon_rustlib
+
+package tachyon
+
+// ============================================================================
+// VENDORED NATIVE BACKEND
+// ============================================================================
+
+// By default the binding compiles the C reference implementation vendored in
+// native/ as part of the cgo build, so `go get` and `go build` work without
+// building the Rust library first. The C backend selects AVX-512, AES-NI,
+// NEON (ARM64 with the crypto extensions, e.g. Apple Silicon and Graviton)
+// or the portable kernel at runtime, and produces output identical to the
+// Rust library.
+//
+// Build with -tags tachyon_portable to pin the portable kernel at compile
+// time, or use SetImplementation to switch kernels at run time. Without cgo
+// (GOOS=js, GOOS=wasip1, CGO_ENABLED=0) the pure Go engine in
+// engine_purego.go is used instead.
+//
+// Build with -tags tachyon_rustlib to link the Rust library from
+// ../../target/release instead.
+//
+// tachyon.h is a copy of bindings/c/tachyon.h and native/ a copy of
+// algorithms/tachyon/c-reference; refresh both with `go generate` after
+// changing the native API.
+
+/*
+#cgo CFLAGS: -O3
+
+int tachyon_go_select_backend(int feature);
+*/
+import "C"
+
+//go:generate sh -c "cp ../c/tachyon.h . && cp ../../algorithms/tachyon/c-reference/tachyon*.[ch] native/"
+
+// Dispatcher kernel IDs (cpu_feature_t in tachyon_dispatcher.c).
+var nativeFeatures = map[Implementation]C.int{
+	ImplAuto:     0,
+	ImplPortable: 1,
+	ImplAESNI:    2,
+	ImplAVX512:   3,
+	ImplNEON:     4,
+}
+
+// selectNative pins the vendored dispatcher to impl.
+func selectNative(impl Implementation) error {
+	if C.tachyon_go_select_backend(nativeFeatures[impl]) != 0 {
+		return errUnavailable(impl)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+//go:build cgo && !tachyon_rustlib
+
+#include "native/tachyon_avx512.c"
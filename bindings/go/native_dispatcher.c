@@ -0,0 +1,27 @@
+//go:build cgo && !tachyon_rustlib
+
+#include "native/tachyon_dispatcher.c"
+
+// Pin the dispatcher to feature (a cpu_feature_t), or restore the detected
+// kernel for CPU_UNKNOWN. Returns -1 if the kernel is not available on this
+// CPU or in this build. Called with the binding's selection lock held.
+int tachyon_go_select_backend(int feature) {
+    static cpu_feature_t detected = CPU_UNKNOWN;
+    if (detected == CPU_UNKNOWN) {
+        if (g_cpu_feature == CPU_UNKNOWN) detect_cpu();
+        detected = g_cpu_feature;
+    }
+
+    int ok;
+    switch (feature) {
+        case CPU_UNKNOWN:  feature = detected; ok = 1; break;
+        case CPU_PORTABLE: ok = 1; break;
+        case CPU_AESNI:    ok = detected == CPU_AESNI || detected == CPU_AVX512; break;
+        default:           ok = (cpu_feature_t)feature == detected; break;
+    }
+    if (!ok) {
+        return -1;
+    }
+    g_cpu_feature = (cpu_feature_t)feature;
+    return 0;
+}
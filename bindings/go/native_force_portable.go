@@ -0,0 +1,11 @@
+//go:build tachyon_portable && !tachyon_rustlib
+
+package tachyon
+
+// Pin the vendored C backend to the portable kernel, e.g. to rule out a SIMD
+// path when debugging.
+
+/*
+#cgo CFLAGS: -DFORCE_PORTABLE
+*/
+import "C"
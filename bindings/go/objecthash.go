@@ -0,0 +1,295 @@
+package tachyon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// CANONICAL OBJECT HASHING
+// ============================================================================
+
+// objectPersonalization separates object digests from all other hashes.
+const objectPersonalization = "tachyon object v1"
+
+// maxSafeInteger bounds the integers float64 represents without gaps.
+const maxSafeInteger = 1 << 53
+
+var (
+	objectKeyOnce sync.Once
+	objectKey     []byte
+	objectKeyErr  error
+)
+
+// HashJSON returns the canonical object digest of v's JSON encoding, as
+// produced by encoding/json. Struct tags therefore apply, and the result
+// equals HashJSONDocument(json.Marshal(v)).
+func HashJSON(v any) (Digest, error) {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return Digest{}, err
+	}
+	return HashJSONDocument(doc)
+}
+
+// HashJSONDocument returns the canonical object digest of a JSON document,
+// so documents differing only in whitespace, key order or number spelling
+// (1, 1.0, 1e0) hash the same. Duplicate object keys are an error.
+func HashJSONDocument(doc []byte) (Digest, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	v, err := decodeStrict(dec)
+	if err != nil {
+		return Digest{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Digest{}, errors.New("tachyon: trailing data after JSON value")
+	}
+	return HashCanonical(v)
+}
+
+// HashCanonical returns the canonical object digest of a tree of maps with
+// string keys, slices, arrays and scalars: nil, bool, string, []byte, Go
+// integer and float types and json.Number. Pointers and interfaces are
+// followed; nil ones are null. Structs are not accepted; use HashJSON.
+//
+// The digest is specified so other implementations can reproduce it. With
+//
+//	H(x) = Hash(x, WithPersonalization("tachyon object v1"))
+//
+// every value hashes to H of a one-byte type tag and a payload:
+//
+//	null    H("n")
+//	bool    H("b" || 0x00 or 0x01)
+//	number  H("f" || LE64(IEEE 754 bits of the float64 value)), -0 as +0
+//	string  H("s" || UTF-8 bytes)
+//	bytes   H("r" || bytes)
+//	list    H("l" || H(e_0) || H(e_1) || ...)
+//	object  H("d" || H(k_0) || H(v_0) || ...), pairs sorted by H(k_i)
+//
+// Numbers are IEEE doubles, as in I-JSON: 1 and 1.0 are the same number.
+// NaN, infinities and integers a double cannot hold exactly (possible
+// beyond ±2^53) are rejected rather than silently rounded. Strings must be
+// valid UTF-8 and are not Unicode-normalized. Nil slices and maps are null,
+// as in encoding/json.
+func HashCanonical(v any) (Digest, error) {
+	objectKeyOnce.Do(func() {
+		_, _, objectKey, _, objectKeyErr = resolve([]Option{WithPersonalization(objectPersonalization)})
+	})
+	if objectKeyErr != nil {
+		return Digest{}, objectKeyErr
+	}
+	return hashObject(reflect.ValueOf(v))
+}
+
+// hashNode hashes one tagged node.
+func hashNode(tag byte, payload []byte) (Digest, error) {
+	return hashDigest(append([]byte{tag}, payload...), DomainGeneric, 0, objectKey)
+}
+
+func hashObject(v reflect.Value) (Digest, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return hashNode('n', nil)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return hashNode('n', nil)
+	}
+
+	if n, ok := v.Interface().(json.Number); ok {
+		return hashJSONNumber(n)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b := byte(0)
+		if v.Bool() {
+			b = 1
+		}
+		return hashNode('b', []byte{b})
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if !exactInt(i) {
+			return Digest{}, fmt.Errorf("tachyon: integer %d is not exactly representable", i)
+		}
+		return hashNumber(float64(i))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if !exactUint(u) {
+			return Digest{}, fmt.Errorf("tachyon: integer %d is not exactly representable", u)
+		}
+		return hashNumber(float64(u))
+
+	case reflect.Float32, reflect.Float64:
+		return hashNumber(v.Float())
+
+	case reflect.String:
+		s := v.String()
+		if !utf8.ValidString(s) {
+			return Digest{}, errors.New("tachyon: string is not valid UTF-8")
+		}
+		return hashNode('s', []byte(s))
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				return hashNode('n', nil)
+			}
+			return hashNode('r', bytesOf(v))
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return hashNode('n', nil)
+		}
+		payload := make([]byte, 0, v.Len()*DigestSize)
+		for i := 0; i < v.Len(); i++ {
+			d, err := hashObject(v.Index(i))
+			if err != nil {
+				return Digest{}, err
+			}
+			payload = append(payload, d[:]...)
+		}
+		return hashNode('l', payload)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return Digest{}, fmt.Errorf("tachyon: map key type %s is not a string", v.Type().Key())
+		}
+		if v.IsNil() {
+			return hashNode('n', nil)
+		}
+		pairs := make([][2]Digest, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k, err := hashObject(iter.Key())
+			if err != nil {
+				return Digest{}, err
+			}
+			val, err := hashObject(iter.Value())
+			if err != nil {
+				return Digest{}, err
+			}
+			pairs = append(pairs, [2]Digest{k, val})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return bytes.Compare(pairs[i][0][:], pairs[j][0][:]) < 0 })
+		payload := make([]byte, 0, len(pairs)*2*DigestSize)
+		for _, p := range pairs {
+			payload = append(append(payload, p[0][:]...), p[1][:]...)
+		}
+		return hashNode('d', payload)
+	}
+	return Digest{}, fmt.Errorf("tachyon: cannot hash %s canonically", v.Type())
+}
+
+// bytesOf returns the contents of a byte slice or array.
+func bytesOf(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+func hashNumber(f float64) (Digest, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Digest{}, errors.New("tachyon: NaN and infinities have no canonical form")
+	}
+	if f == 0 {
+		f = 0 // Fold -0 into +0
+	}
+	return hashNode('f', binary.LittleEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+// hashJSONNumber hashes a JSON number literal, rejecting integer literals a
+// double would round.
+func hashJSONNumber(n json.Number) (Digest, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return Digest{}, fmt.Errorf("tachyon: invalid number %q", n)
+	}
+	if math.Abs(f) >= maxSafeInteger && !strings.ContainsAny(string(n), ".eE") {
+		// Compare the literal with the double in full, as it may exceed int64
+		i, ok := new(big.Int).SetString(string(n), 10)
+		if !ok || i.Cmp(exactFloat(f)) != 0 {
+			return Digest{}, fmt.Errorf("tachyon: integer %s is not exactly representable", n)
+		}
+	}
+	return hashNumber(f)
+}
+
+// exactInt reports whether float64 holds i exactly.
+func exactInt(i int64) bool {
+	f := float64(i)
+	return f < math.MaxInt64 && int64(f) == i // 2^63 itself does not convert back
+}
+
+// exactFloat returns the integer value of a finite, integral float.
+func exactFloat(f float64) *big.Int {
+	i, _ := big.NewFloat(f).Int(nil)
+	return i
+}
+
+// exactUint reports whether float64 holds u exactly.
+func exactUint(u uint64) bool {
+	f := float64(u)
+	return f < math.MaxUint64 && uint64(f) == u
+}
+
+// decodeStrict decodes one JSON value, rejecting duplicate object keys.
+func decodeStrict(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			list := []any{}
+			for dec.More() {
+				v, err := decodeStrict(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			_, err := dec.Token() // ']'
+			return list, err
+		case '{':
+			obj := map[string]any{}
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := tok.(string) // The decoder only yields string keys
+				if _, dup := obj[key]; dup {
+					return nil, fmt.Errorf("tachyon: duplicate JSON key %q", key)
+				}
+				if obj[key], err = decodeStrict(dec); err != nil {
+					return nil, err
+				}
+			}
+			_, err := dec.Token() // '}'
+			return obj, err
+		}
+		return nil, fmt.Errorf("tachyon: unexpected JSON delimiter %v", t)
+	}
+	return tok, nil
+}
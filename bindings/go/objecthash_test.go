@@ -0,0 +1,160 @@
+package tachyon
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestHashJSONDocumentCanonical(t *testing.T) {
+	a, err := HashJSONDocument([]byte(`{"b": [1, 2.0, {"x": null}], "a": "héllo", "c": true}`))
+	if err != nil {
+		t.Fatalf("HashJSONDocument failed: %v", err)
+	}
+	b, err := HashJSONDocument([]byte("{\"c\":true,\n\"a\":\"h\\u00e9llo\",\"b\":[1e0,2,{\"x\":null}]}"))
+	if err != nil {
+		t.Fatalf("HashJSONDocument failed: %v", err)
+	}
+	if a != b {
+		t.Error("Semantically equal documents should hash equally")
+	}
+
+	different := []string{
+		`{"b": [2, 1, {"x": null}], "a": "héllo", "c": true}`,
+		`{"b": [1, 2, {"x": null}], "a": "héllo", "c": 1}`,
+		`{"b": [1, 2, {"x": ""}], "a": "héllo", "c": true}`,
+		`{"b": [1, 2, {"x": null}], "a": "héllo"}`,
+	}
+	for _, doc := range different {
+		d, err := HashJSONDocument([]byte(doc))
+		if err != nil {
+			t.Fatalf("HashJSONDocument failed: %v", err)
+		}
+		if d == a {
+			t.Errorf("%s should hash differently", doc)
+		}
+	}
+}
+
+func TestHashCanonicalMatchesJSON(t *testing.T) {
+	type record struct {
+		Name  string            `json:"name"`
+		Tags  []string          `json:"tags"`
+		Attrs map[string]uint16 `json:"attrs"`
+		Score float32           `json:"score"`
+	}
+	fromStruct, err := HashJSON(record{"svc", []string{"a", "b"}, map[string]uint16{"z": 1, "y": 2}, 0.5})
+	if err != nil {
+		t.Fatalf("HashJSON failed: %v", err)
+	}
+	fromTree, err := HashCanonical(map[string]any{
+		"attrs": map[string]int{"y": 2, "z": 1},
+		"name":  "svc",
+		"score": 0.5,
+		"tags":  []any{"a"},
+	})
+	if err != nil {
+		t.Fatalf("HashCanonical failed: %v", err)
+	}
+	if fromStruct == fromTree {
+		t.Error("Different tags should hash differently")
+	}
+
+	fromTree, _ = HashCanonical(map[string]any{
+		"attrs": map[string]int{"y": 2, "z": 1},
+		"name":  "svc",
+		"score": 0.5,
+		"tags":  [2]string{"a", "b"},
+	})
+	if fromStruct != fromTree {
+		t.Error("HashJSON and HashCanonical should agree on equal structure")
+	}
+}
+
+func TestHashCanonicalScalars(t *testing.T) {
+	one, _ := HashCanonical(1)
+	for _, v := range []any{1.0, uint8(1), json.Number("1.0"), json.Number("10e-1")} {
+		if d, err := HashCanonical(v); err != nil || d != one {
+			t.Errorf("%T %v should hash like 1 (err %v)", v, v, err)
+		}
+	}
+
+	zero, _ := HashCanonical(0.0)
+	if negZero, _ := HashCanonical(math.Copysign(0, -1)); negZero != zero {
+		t.Error("-0 should hash like +0")
+	}
+
+	null, _ := HashCanonical(nil)
+	empty, _ := HashCanonical("")
+	bytesEmpty, _ := HashCanonical([]byte{})
+	if null == empty || empty == bytesEmpty || null == bytesEmpty {
+		t.Error("null, empty string and empty bytes should differ")
+	}
+	if d, _ := HashCanonical([]string(nil)); d != null {
+		t.Error("Nil slice should hash as null")
+	}
+
+	exact := []any{int64(1) << 60, json.Number("1152921504606846976")}
+	for _, v := range exact {
+		if _, err := HashCanonical(v); err != nil {
+			t.Errorf("%v is exactly representable: %v", v, err)
+		}
+	}
+	rejected := []any{
+		math.NaN(), math.Inf(1), int64(1)<<53 + 1, uint64(math.MaxUint64),
+		json.Number("9007199254740993"), json.Number("1e999"), "\xff",
+		map[int]string{1: "a"}, struct{}{}, make(chan int),
+	}
+	for _, v := range rejected {
+		if _, err := HashCanonical(v); err == nil {
+			t.Errorf("HashCanonical(%#v) should return error", v)
+		}
+	}
+}
+
+func TestHashJSONLargeIntegers(t *testing.T) {
+	// Integers beyond int64 that a double holds exactly hash like the double
+	cases := []struct {
+		value any
+		doc   string
+	}{
+		{map[string]any{"x": 1e20}, `{"x":1e20}`},
+		{uint64(1) << 63, `9223372036854775808`},
+		{-math.Pow(2, 70), `-1180591620717411303424`},
+	}
+	for _, c := range cases {
+		got, err := HashJSON(c.value)
+		if err != nil {
+			t.Errorf("HashJSON(%v) failed: %v", c.value, err)
+			continue
+		}
+		canonical, err := HashCanonical(c.value)
+		if err != nil {
+			t.Fatalf("HashCanonical(%v) failed: %v", c.value, err)
+		}
+		doc, err := HashJSONDocument([]byte(c.doc))
+		if err != nil {
+			t.Fatalf("HashJSONDocument(%s) failed: %v", c.doc, err)
+		}
+		if got != canonical || got != doc {
+			t.Errorf("HashJSON(%v) should match HashCanonical and %s", c.value, c.doc)
+		}
+	}
+
+	for _, doc := range []string{`9007199254740993`, `100000000000000000001`} {
+		if _, err := HashJSONDocument([]byte(doc)); err == nil {
+			t.Errorf("Inexact integer %s should return error", doc)
+		}
+	}
+}
+
+func TestHashJSONDocumentErrors(t *testing.T) {
+	for _, doc := range []string{`{"a": 1, "a": 2}`, `[1, 2`, `{"a": 1} {}`, ``, `{"a" 1}`} {
+		if _, err := HashJSONDocument([]byte(doc)); err == nil {
+			t.Errorf("HashJSONDocument(%q) should return error", doc)
+		}
+	}
+	if _, err := HashJSON(map[string]any{"f": func() {}}); err == nil {
+		t.Error("Unmarshalable value should return error")
+	}
+}
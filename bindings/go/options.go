@@ -0,0 +1,127 @@
+package tachyon
+
+import "errors"
+
+// ============================================================================
+// OPTIONS API
+// ============================================================================
+
+// Option configures Hash and New. Options compose freely, e.g. a keyed hash
+// under a custom domain with a 16-byte output:
+//
+//	mac, err := tachyon.Hash(data,
+//	    tachyon.WithKey(key),
+//	    tachyon.WithDomain(tachyon.DomainDatabaseIndex),
+//	    tachyon.WithOutputSize(tachyon.Size128))
+type Option func(*options)
+
+type options struct {
+	domain          uint64
+	domainSet       bool
+	seed            uint64
+	key             []byte
+	size            int
+	personalization string
+	progress        func(int64) // HashReaderContext only
+}
+
+// WithSeed sets the 64-bit seed (default 0).
+func WithSeed(seed uint64) Option {
+	return func(o *options) { o.seed = seed }
+}
+
+// WithKey makes the hash a MAC under the 32-byte key.
+//
+// Unless WithDomain is also given, the domain defaults to DomainMessageAuth,
+// so Hash(data, WithKey(k)) equals HashKeyed(data, k) for non-empty data.
+// Unlike HashKeyed, Hash also accepts empty data.
+func WithKey(key []byte) Option {
+	return func(o *options) { o.key = key }
+}
+
+// WithDomain sets the domain (default DomainGeneric), from 0 to MaxDomain.
+// Hash and New return an error for larger, reserved domains.
+func WithDomain(domain uint64) Option {
+	return func(o *options) {
+		o.domain = domain
+		o.domainSet = true
+	}
+}
+
+// WithOutputSize sets the output size: Size128, Size192, Size256 (default)
+// or Size512. Each size is domain-separated from the others.
+func WithOutputSize(size int) Option {
+	return func(o *options) { o.size = size }
+}
+
+// WithPersonalization binds the hash to an application-specific string, so
+// two applications hashing the same data never get the same output.
+//
+// The personalization is folded into the key: the hash is keyed with
+// DeriveKey("tachyon personalization v1: "+p, key), using 32 zero bytes as
+// key material when no key is set. It must be valid UTF-8.
+func WithPersonalization(p string) Option {
+	return func(o *options) { o.personalization = p }
+}
+
+// resolve applies opts and returns the effective domain, seed, key and size.
+func resolve(opts []Option) (domain, seed uint64, key []byte, size int, err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.size == 0 {
+		o.size = Size256
+	}
+	if !validOutputSize(o.size) {
+		return 0, 0, nil, 0, errors.New("tachyon: output size must be 16, 24, 32 or 64 bytes")
+	}
+	if err := checkDomain(o.domain); err != nil {
+		return 0, 0, nil, 0, err
+	}
+	if o.key != nil && len(o.key) != 32 {
+		return 0, 0, nil, 0, errors.New("tachyon: key must be 32 bytes")
+	}
+
+	key = o.key
+	if !o.domainSet && key != nil {
+		o.domain = DomainMessageAuth
+	}
+	if o.personalization != "" {
+		material := key
+		if material == nil {
+			material = make([]byte, 32)
+		}
+		key, err = DeriveKey("tachyon personalization v1: "+o.personalization, material)
+		if err != nil {
+			return 0, 0, nil, 0, err
+		}
+	}
+	return o.domain, o.seed, key, o.size, nil
+}
+
+func hashOptions(data []byte, opts []Option) ([]byte, error) {
+	domain, seed, key, size, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	return hashSized(data, domain, seed, key, size)
+}
+
+// New creates a streaming hasher configured by opts.
+//
+// Finalize returns the same result as Hash(data, opts...) over all data
+// written.
+func New(opts ...Option) (*Hasher, error) {
+	domain, seed, key, size, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	h := startHasher(outputDomain(domain, size), seed, key, size)
+	if h == nil {
+		return nil, errors.New("tachyon: could not create hasher")
+	}
+	return h, nil
+}
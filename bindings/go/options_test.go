@@ -0,0 +1,103 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashOptionsMatchLegacyAPI(t *testing.T) {
+	data := []byte("options")
+	key := bytes.Repeat([]byte{0x11}, 32)
+
+	cases := []struct {
+		name string
+		opts []Option
+		want func() ([]byte, error)
+	}{
+		{"seed", []Option{WithSeed(42)}, func() ([]byte, error) { return HashSeeded(data, 42) }},
+		{"domain", []Option{WithDomain(DomainFileChecksum)}, func() ([]byte, error) { return HashWithDomain(data, DomainFileChecksum) }},
+		{"key", []Option{WithKey(key)}, func() ([]byte, error) { return HashKeyed(data, key) }},
+		{"size", []Option{WithOutputSize(Size128)}, func() ([]byte, error) { return Hash128(data) }},
+	}
+	for _, c := range cases {
+		got, err := Hash(data, c.opts...)
+		if err != nil {
+			t.Fatalf("%s: Hash failed: %v", c.name, err)
+		}
+		want, _ := c.want()
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: options result differs from the dedicated function", c.name)
+		}
+	}
+}
+
+func TestKeyedEmptyInput(t *testing.T) {
+	key := bytes.Repeat([]byte{0x11}, 32)
+	if _, err := HashKeyed(nil, key); err == nil {
+		t.Error("HashKeyed should reject empty input")
+	}
+	if out, err := Hash(nil, WithKey(key)); err != nil || len(out) != DigestSize {
+		t.Errorf("Hash of empty input with WithKey = %x, %v", out, err)
+	}
+}
+
+func TestHashOptionsCompose(t *testing.T) {
+	data := []byte("compose")
+	key := bytes.Repeat([]byte{0x22}, 32)
+
+	variants := [][]Option{
+		{WithKey(key)},
+		{WithKey(key), WithDomain(DomainDatabaseIndex)},
+		{WithKey(key), WithDomain(DomainDatabaseIndex), WithSeed(1)},
+		{WithKey(key), WithPersonalization("app-a")},
+		{WithKey(key), WithPersonalization("app-b")},
+		{WithPersonalization("app-a")},
+		{WithKey(key), WithOutputSize(Size512)},
+	}
+	seen := map[string]int{}
+	for i, opts := range variants {
+		out, err := Hash(data, opts...)
+		if err != nil {
+			t.Fatalf("variant %d: Hash failed: %v", i, err)
+		}
+		if j, dup := seen[string(out[:16])]; dup {
+			t.Errorf("variants %d and %d collide", j, i)
+		}
+		seen[string(out[:16])] = i
+	}
+
+	if _, err := Hash(data, WithKey([]byte("short"))); err == nil {
+		t.Error("Invalid key should be rejected")
+	}
+	if _, err := Hash(data, WithOutputSize(7)); err == nil {
+		t.Error("Invalid output size should be rejected")
+	}
+}
+
+func TestNewMatchesHash(t *testing.T) {
+	data := bytes.Repeat([]byte("streaming options "), 40000) // Spans several chunks
+	key := bytes.Repeat([]byte{0x33}, 32)
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithSeed(9), WithDomain(DomainFileChecksum)},
+		{WithKey(key), WithPersonalization("backup")},
+		{WithOutputSize(Size192)},
+		{WithKey(key), WithOutputSize(Size512)},
+	} {
+		h, err := New(opts...)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		h.Update(data[:1000])
+		h.Update(data[1000:])
+		got, err := h.Finalize()
+		if err != nil {
+			t.Fatalf("Finalize failed: %v", err)
+		}
+		want, _ := Hash(data, opts...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("New(%d options) differs from Hash", len(opts))
+		}
+	}
+}
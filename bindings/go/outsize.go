@@ -0,0 +1,104 @@
+package tachyon
+
+import "errors"
+
+// ============================================================================
+// OUTPUT SIZES
+// ============================================================================
+
+// Supported digest sizes in bytes.
+const (
+	Size128 = 16
+	Size192 = 24
+	Size256 = DigestSize
+	Size512 = 64
+)
+
+// outputDomain returns the domain used for size-byte outputs.
+//
+// The output size is folded into the top byte of the domain, so every size
+// hashes under its own domain and a short digest is never a prefix of a
+// longer one. 32-byte outputs use the domain unchanged and equal the
+// regular API. The top byte of the domain is therefore reserved.
+func outputDomain(domain uint64, size int) uint64 {
+	if size == Size256 {
+		return domain
+	}
+	return domain ^ uint64(size)<<56
+}
+
+// MaxDomain is the largest domain Hash, New and HashMmap accept: domains
+// range from 0 to 2^56-1. Larger values are reserved, the top byte for
+// output-size separation, which also covers the leaf and node domains of the
+// Merkle tree (0xFFFFFFFF00000000 and 0xFFFFFFFF00000001).
+//
+// This narrows the domains of earlier releases and of the other bindings,
+// which accept any uint64; NewHasherWithDomain still does.
+const MaxDomain = 1<<56 - 1
+
+// checkDomain rejects reserved domains. Accepting them would let a one-shot
+// hash equal another size's digest, or a leaf or node of the tree.
+func checkDomain(domain uint64) error {
+	if domain > MaxDomain {
+		return errors.New("tachyon: domain top byte is reserved")
+	}
+	return nil
+}
+
+func validOutputSize(size int) bool {
+	switch size {
+	case Size128, Size192, Size256, Size512:
+		return true
+	}
+	return false
+}
+
+// hashSized computes a size-byte digest under domain, seed and optional key.
+func hashSized(data []byte, domain, seed uint64, key []byte, size int) ([]byte, error) {
+	if !validOutputSize(size) {
+		return nil, errors.New("tachyon: output size must be 16, 24, 32 or 64 bytes")
+	}
+	if key != nil && len(key) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
+	}
+
+	out := make([]byte, size)
+	if err := hashSizedInto(data, outputDomain(domain, size), seed, key, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// resizeDigest turns a root digest computed under outputDomain(_, size) into
+// a size-byte output, matching hashSizedInto.
+func resizeDigest(root []byte, size int) ([]byte, error) {
+	if size <= Size256 {
+		return root[:size], nil
+	}
+	out := make([]byte, size)
+	if err := expandInto((*Digest)(root), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Hash128 computes a 16-byte digest, e.g. for compact index keys.
+//
+// It is domain-separated from the other sizes rather than a truncation of
+// Hash. Collision resistance is 64 bits.
+func Hash128(data []byte) ([]byte, error) {
+	return hashSized(data, DomainGeneric, 0, nil, Size128)
+}
+
+// Hash192 computes a 24-byte digest, domain-separated from the other sizes.
+func Hash192(data []byte) ([]byte, error) {
+	return hashSized(data, DomainGeneric, 0, nil, Size192)
+}
+
+// Hash512 computes a 64-byte digest for formats that require one.
+//
+// The output expands a 256-bit internal hash, so security does not exceed
+// that of Hash; it is domain-separated from the other sizes.
+func Hash512(data []byte) ([]byte, error) {
+	return hashSized(data, DomainGeneric, 0, nil, Size512)
+}
@@ -0,0 +1,126 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputSizes(t *testing.T) {
+	data := []byte("Tachyon")
+	full, _ := Hash(data)
+
+	outputs := map[int][]byte{}
+	for size, fn := range map[int]func([]byte) ([]byte, error){
+		Size128: Hash128,
+		Size192: Hash192,
+		Size512: Hash512,
+	} {
+		out, err := fn(data)
+		if err != nil {
+			t.Fatalf("%d-byte hash failed: %v", size, err)
+		}
+		if len(out) != size {
+			t.Errorf("%d-byte hash returned %d bytes", size, len(out))
+		}
+		again, _ := fn(data)
+		if !bytes.Equal(out, again) {
+			t.Errorf("%d-byte hash should be deterministic", size)
+		}
+		outputs[size] = out
+	}
+
+	// No size is a prefix of another
+	outputs[Size256] = full
+	for a, outA := range outputs {
+		for b, outB := range outputs {
+			if a < b && bytes.Equal(outA, outB[:a]) {
+				t.Errorf("%d-byte digest is a prefix of the %d-byte digest", a, b)
+			}
+		}
+	}
+
+	// 32 bytes is the regular hash
+	same, err := hashSized(data, DomainGeneric, 0, nil, Size256)
+	if err != nil || !bytes.Equal(same, full) {
+		t.Error("32-byte output should equal Hash")
+	}
+
+	if _, err := hashSized(data, DomainGeneric, 0, nil, 20); err == nil {
+		t.Error("Unsupported size should be rejected")
+	}
+	if out, err := Hash128(nil); err != nil || len(out) != Size128 {
+		t.Errorf("Hash128 of empty input = %x, %v", out, err)
+	}
+}
+
+func TestReservedDomainTopByte(t *testing.T) {
+	data := []byte("domain collision")
+	short, _ := Hash128(data)
+
+	// Without the check, Hash under 16<<56 would start with Hash128
+	for _, domain := range []uint64{Size128 << 56, Size512 << 56, 1 << 63} {
+		if _, err := Hash(data, WithDomain(domain)); err == nil {
+			t.Errorf("Hash with domain %#x should be rejected", domain)
+		}
+		if _, err := New(WithDomain(domain)); err == nil {
+			t.Errorf("New with domain %#x should be rejected", domain)
+		}
+		if _, err := HashMmap(data, MmapOptions{Domain: domain}); err == nil {
+			t.Errorf("HashMmap with domain %#x should be rejected", domain)
+		}
+	}
+
+	// The largest usable domain still works and stays separated
+	full, err := Hash(data, WithDomain(1<<56-1))
+	if err != nil {
+		t.Fatalf("Hash with domain 2^56-1 failed: %v", err)
+	}
+	if bytes.Equal(full[:Size128], short) {
+		t.Error("Domain 2^56-1 should not collide with Hash128")
+	}
+}
+
+func TestReservedTreeDomains(t *testing.T) {
+	chunk := make([]byte, nativeChunkSize)
+
+	// A one-shot hash under the leaf domain would equal the first leaf of a
+	// larger input
+	var leaf Digest
+	goKernel(chunk, goDomainLeaf, 0, nil, &leaf)
+	for _, domain := range []uint64{goDomainLeaf, goDomainNode} {
+		out, err := Hash(chunk[:64], WithDomain(domain))
+		if err == nil {
+			t.Errorf("Hash with tree domain %#x should be rejected", domain)
+		}
+		if out != nil && bytes.Equal(out, leaf[:]) {
+			t.Error("Hash should never reproduce a leaf")
+		}
+	}
+	if err := checkDomain(MaxDomain); err != nil {
+		t.Errorf("MaxDomain should be usable: %v", err)
+	}
+	if checkDomain(MaxDomain+1) == nil {
+		t.Error("MaxDomain+1 should be reserved")
+	}
+}
+
+func TestNewHasherWithDomainAcceptsAnyDomain(t *testing.T) {
+	// The streaming constructor keeps accepting the full uint64 range
+	data := []byte("legacy domain")
+	for _, domain := range []uint64{MaxDomain + 1, goDomainLeaf, ^uint64(0)} {
+		h := NewHasherWithDomain(domain)
+		if h == nil {
+			t.Fatalf("NewHasherWithDomain(%#x) returned nil", domain)
+		}
+		h.Update(data)
+		got, err := h.Finalize()
+		h.Close()
+		if err != nil {
+			t.Fatalf("Finalize failed: %v", err)
+		}
+		var want Digest
+		if err := hashFull(data, domain, 0, nil, &want); err != nil || !bytes.Equal(got, want[:]) {
+			t.Errorf("Domain %#x should hash like the one-shot engine", domain)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tachyon
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ============================================================================
+// 64-BIT OUTPUT
+// ============================================================================
+
+// Hash64 returns the first 8 bytes of Hash(data) as a little-endian uint64.
+//
+// This mapping is part of the stable API: it will not change across library
+// versions, so values may be persisted.
+func Hash64(data []byte) (uint64, error) {
+	hash, err := Hash(data)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(hash[:8]), nil
+}
+
+// ============================================================================
+// DETERMINISTIC PLACEMENT
+// ============================================================================
+
+// Shard maps data to one of n shards.
+//
+// The shard is JumpHash applied to the first 8 bytes (little-endian) of the
+// DomainDatabaseIndex digest of data. Like Hash64, the result is stable
+// across library versions. Growing n moves only about 1/n of the keys, and
+// only to the new shards.
+func Shard(data []byte, n int) (int, error) {
+	if n <= 0 {
+		return 0, errors.New("tachyon: shard count must be positive")
+	}
+	hash, err := HashWithDomain(data, DomainDatabaseIndex)
+	if err != nil {
+		return 0, err
+	}
+	return JumpHash(binary.LittleEndian.Uint64(hash[:8]), n), nil
+}
+
+// JumpHash implements Lamping and Veach's jump consistent hash.
+//
+// It maps key to a bucket in [0, buckets). When buckets grows from n to n+1,
+// only keys moving to the new bucket change. Returns -1 if buckets is not
+// positive. Callers should pass well-mixed keys such as Hash64 output.
+func JumpHash(key uint64, buckets int) int {
+	if buckets <= 0 {
+		return -1
+	}
+
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
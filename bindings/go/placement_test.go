@@ -0,0 +1,64 @@
+package tachyon
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Golden values pin the placement functions; they must never change.
+func TestPlacementStability(t *testing.T) {
+	h, err := Hash64([]byte("Tachyon"))
+	if err != nil {
+		t.Fatalf("Hash64 failed: %v", err)
+	}
+	if h != 0x2abf01857e880b12 {
+		t.Errorf("Hash64(\"Tachyon\") = %#x, want 0x2abf01857e880b12", h)
+	}
+
+	shards := map[int]int{1: 0, 7: 4, 64: 32, 1000: 32}
+	for n, want := range shards {
+		got, err := Shard([]byte("row:42"), n)
+		if err != nil {
+			t.Fatalf("Shard failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Shard(\"row:42\", %d) = %d, want %d", n, got, want)
+		}
+	}
+
+	jumps := map[uint64]int{0: 0, 1: 549, 0xdeadbeef: 285, 1 << 63: 453}
+	for key, want := range jumps {
+		if got := JumpHash(key, 1000); got != want {
+			t.Errorf("JumpHash(%#x, 1000) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestShardGrowth(t *testing.T) {
+	moved := 0
+	for i := 0; i < 5000; i++ {
+		key := []byte(fmt.Sprintf("row:%d", i))
+		before, _ := Shard(key, 10)
+		after, _ := Shard(key, 11)
+		if before != after {
+			if after != 10 {
+				t.Fatalf("key moved from %d to existing shard %d", before, after)
+			}
+			moved++
+		}
+	}
+
+	// About 1/11 of the keys should move to the new shard
+	if moved < 300 || moved > 620 {
+		t.Errorf("%d of 5000 keys moved, want ~455", moved)
+	}
+}
+
+func TestPlacementErrors(t *testing.T) {
+	if _, err := Shard([]byte("x"), 0); err == nil {
+		t.Error("Zero shards should return error")
+	}
+	if JumpHash(1, 0) != -1 {
+		t.Error("JumpHash with zero buckets should return -1")
+	}
+}
@@ -0,0 +1,92 @@
+package tachyon
+
+import "errors"
+
+// ============================================================================
+// PRF / KEYSTREAM
+// ============================================================================
+
+// prfContext separates PRF keys from the caller's key, so keystream blocks
+// never equal HashKeyed MACs computed with the same key.
+const prfContext = "tachyon prf keystream v1"
+
+// prfBatch is the number of blocks generated per native call.
+const prfBatch = 64
+
+// MaxPRFNonceSize is the largest nonce accepted by NewPRFWithNonce.
+const MaxPRFNonceSize = 32
+
+// PRF expands a key (and optional nonce) into a deterministic pseudorandom
+// stream.
+//
+// Block i of the stream is HashKeyed(LE64(i) || nonce, k), where k is derived
+// from the caller's key with DeriveKey; the stream is the concatenation of
+// all blocks. Blocks are produced in batches of 64 per native call.
+//
+// The same (key, nonce) always yields the same stream, so never reuse a pair
+// when the stream is used as a mask. A PRF is not safe for concurrent use.
+type PRF struct {
+	key     [32]byte
+	nonce   []byte
+	counter uint64 // Next block to generate
+	buf     [prfBatch * 32]byte
+	pos     int // Read position in buf
+	end     int // Valid bytes in buf
+}
+
+// NewPRF creates a PRF from a 32-byte key with an empty nonce.
+func NewPRF(key []byte) (*PRF, error) {
+	return NewPRFWithNonce(key, nil)
+}
+
+// NewPRFWithNonce creates a PRF from a 32-byte key and a nonce of up to
+// MaxPRFNonceSize bytes. Different nonces yield independent streams.
+func NewPRFWithNonce(key, nonce []byte) (*PRF, error) {
+	if len(key) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
+	}
+	if len(nonce) > MaxPRFNonceSize {
+		return nil, errors.New("tachyon: nonce must be at most 32 bytes")
+	}
+	derived, err := DeriveKey(prfContext, key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PRF{nonce: append([]byte(nil), nonce...)}
+	copy(p.key[:], derived)
+	return p, nil
+}
+
+// Read fills b with the next len(b) bytes of the stream. It always returns
+// len(b), nil unless the native library fails.
+func (p *PRF) Read(b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		if p.pos == p.end {
+			if err := p.refill(); err != nil {
+				return n, err
+			}
+		}
+		c := copy(b[n:], p.buf[p.pos:p.end])
+		p.pos += c
+		n += c
+	}
+	return n, nil
+}
+
+// SeekBlock repositions the stream at the start of block i (byte offset 32*i).
+func (p *PRF) SeekBlock(i uint64) {
+	p.counter = i
+	p.pos, p.end = 0, 0
+}
+
+// refill generates the next batch of blocks.
+func (p *PRF) refill() error {
+	if err := keystream(&p.key, p.nonce, p.counter, p.buf[:]); err != nil {
+		return err
+	}
+	p.counter += prfBatch
+	p.pos, p.end = 0, len(p.buf)
+	return nil
+}
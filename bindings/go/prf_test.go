@@ -0,0 +1,80 @@
+package tachyon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestPRFDeterministic(t *testing.T) {
+	key := bytes.Repeat([]byte("p"), 32)
+
+	a, err := NewPRF(key)
+	if err != nil {
+		t.Fatalf("NewPRF failed: %v", err)
+	}
+	b, _ := NewPRF(key)
+
+	// Same stream regardless of read sizes
+	out1 := make([]byte, 5000)
+	a.Read(out1)
+
+	out2 := make([]byte, 0, 5000)
+	chunk := make([]byte, 7)
+	for len(out2) < 5000 {
+		n := min(len(chunk), 5000-len(out2))
+		b.Read(chunk[:n])
+		out2 = append(out2, chunk[:n]...)
+	}
+	if !bytes.Equal(out1, out2) {
+		t.Error("Stream should not depend on read sizes")
+	}
+
+	// Block layout: block i = HashKeyed(LE64(i), DeriveKey(prfContext, key))
+	derived, _ := DeriveKey(prfContext, key)
+	var counter [8]byte
+	binary.LittleEndian.PutUint64(counter[:], 100)
+	block, _ := HashKeyed(counter[:], derived)
+	if !bytes.Equal(out1[3200:3232], block) {
+		t.Error("Block 100 should match the documented construction")
+	}
+
+	// SeekBlock jumps straight to a block
+	a.SeekBlock(100)
+	seeked := make([]byte, 32)
+	a.Read(seeked)
+	if !bytes.Equal(seeked, block) {
+		t.Error("SeekBlock(100) should continue at block 100")
+	}
+}
+
+func TestPRFSeparation(t *testing.T) {
+	key := bytes.Repeat([]byte("p"), 32)
+	plain, _ := NewPRF(key)
+	nonced, _ := NewPRFWithNonce(key, []byte("nonce-1"))
+	otherKey, _ := NewPRF(bytes.Repeat([]byte("q"), 32))
+
+	read := func(p *PRF) []byte {
+		out := make([]byte, 64)
+		p.Read(out)
+		return out
+	}
+	a, b, c := read(plain), read(nonced), read(otherKey)
+	if bytes.Equal(a, b) || bytes.Equal(a, c) || bytes.Equal(b, c) {
+		t.Error("Different keys or nonces should produce different streams")
+	}
+
+	// Keystream never equals a MAC under the caller's key
+	var counter [8]byte
+	mac, _ := HashKeyed(counter[:], key)
+	if bytes.Equal(a[:32], mac) {
+		t.Error("PRF blocks should be separated from HashKeyed")
+	}
+
+	if _, err := NewPRF([]byte("short")); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+	if _, err := NewPRFWithNonce(key, make([]byte, 33)); err == nil {
+		t.Error("Oversized nonce should return error")
+	}
+}
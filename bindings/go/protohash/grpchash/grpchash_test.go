@@ -0,0 +1,95 @@
+package grpchash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"tachyon"
+	"tachyon/protohash"
+)
+
+// transportStream records the trailer a server handler sets.
+type transportStream struct {
+	trailer metadata.MD
+}
+
+func (s *transportStream) Method() string               { return "/test.Service/Call" }
+func (s *transportStream) SetHeader(metadata.MD) error  { return nil }
+func (s *transportStream) SendHeader(metadata.MD) error { return nil }
+func (s *transportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func newMessage(t *testing.T) *structpb.Struct {
+	msg, err := structpb.NewStruct(map[string]any{"a": 1, "b": "two", "c": true, "d": []any{1, 2}})
+	if err != nil {
+		t.Fatalf("NewStruct failed: %v", err)
+	}
+	return msg
+}
+
+func TestHashProtoDeterministic(t *testing.T) {
+	msg := newMessage(t)
+	want, err := HashProto(msg, tachyon.DomainContentAddressed)
+	if err != nil {
+		t.Fatalf("HashProto failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		got, _ := HashProto(proto.Clone(msg), tachyon.DomainContentAddressed)
+		if got != want {
+			t.Fatal("Map order should not change the digest")
+		}
+	}
+	other, _ := HashProto(msg, tachyon.DomainGeneric)
+	if other == want {
+		t.Error("Different domains should give different digests")
+	}
+}
+
+func TestInterceptorsRoundTrip(t *testing.T) {
+	msg := newMessage(t)
+	stream := &transportStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	handler := func(ctx context.Context, req any) (any, error) { return msg, nil }
+	if _, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("Server interceptor failed: %v", err)
+	}
+	if len(stream.trailer.Get(protohash.TrailerKey)) != 1 {
+		t.Fatal("Server interceptor should set the integrity trailer")
+	}
+
+	// The invoker plays the transport, delivering the reply and trailer
+	invoker := func(trailer metadata.MD, reply *structpb.Struct) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, out any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			proto.Merge(out.(proto.Message), reply)
+			for _, o := range opts {
+				if tr, ok := o.(grpc.TrailerCallOption); ok {
+					*tr.TrailerAddr = trailer
+				}
+			}
+			return nil
+		}
+	}
+	call := func(trailer metadata.MD, reply *structpb.Struct) error {
+		return UnaryClientInterceptor()(context.Background(), "/test.Service/Call", nil, &structpb.Struct{}, nil, invoker(trailer, reply))
+	}
+
+	if err := call(stream.trailer, msg); err != nil {
+		t.Errorf("Intact reply failed: %v", err)
+	}
+	tampered := proto.Clone(msg).(*structpb.Struct)
+	tampered.Fields["a"] = structpb.NewNumberValue(2)
+	if err := call(stream.trailer, tampered); !errors.Is(err, protohash.ErrMismatch) {
+		t.Errorf("Tampered reply = %v, want ErrMismatch", err)
+	}
+	if err := call(nil, msg); !errors.Is(err, protohash.ErrMissingTrailer) {
+		t.Errorf("Reply without trailer = %v, want ErrMissingTrailer", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package protohash hashes protobuf messages reproducibly and carries the
+// digest as an RPC integrity trailer.
+//
+// Default protobuf marshaling does not fix map entry order, so the same
+// message can encode to different bytes and hash differently. A Hasher
+// marshals with a deterministic marshaler supplied by the caller, which keeps
+// this package free of protobuf and gRPC dependencies. Deterministic
+// marshaling is stable for one protobuf library version; services that hash
+// the same message in different languages should agree on the library too.
+//
+// Example:
+//
+//	ph := protohash.New(func(m any) ([]byte, error) {
+//	    return proto.MarshalOptions{Deterministic: true}.Marshal(m.(proto.Message))
+//	})
+//	d, err := ph.HashProto(msg, tachyon.DomainContentAddressed)
+//
+// The trailer helpers Seal and Check carry the digest on RPCs. Ready-made
+// gRPC interceptors, and HashProto for proto.Message, are in the separate
+// module tachyon/protohash/grpchash, which marshals deterministically itself.
+package protohash
+
+import (
+	"errors"
+
+	"tachyon"
+)
+
+// ============================================================================
+// MESSAGE HASHING
+// ============================================================================
+
+// MarshalFunc marshals a message deterministically, e.g. with
+// proto.MarshalOptions{Deterministic: true}.
+type MarshalFunc func(msg any) ([]byte, error)
+
+// Hasher hashes messages marshaled by a deterministic MarshalFunc. A Hasher
+// is safe for concurrent use if its MarshalFunc is.
+type Hasher struct {
+	marshal MarshalFunc
+}
+
+// New creates a Hasher using marshal.
+func New(marshal MarshalFunc) *Hasher {
+	return &Hasher{marshal: marshal}
+}
+
+// HashProto returns the digest of msg's deterministic encoding:
+//
+//	HashWithDomain(marshal(msg), domain)
+func (h *Hasher) HashProto(msg any, domain uint8) (tachyon.Digest, error) {
+	if h.marshal == nil {
+		return tachyon.Digest{}, errors.New("protohash: no marshal function")
+	}
+	data, err := h.marshal(msg)
+	if err != nil {
+		return tachyon.Digest{}, err
+	}
+	sum, err := tachyon.HashWithDomain(data, domain)
+	if err != nil {
+		return tachyon.Digest{}, err
+	}
+	return tachyon.Digest(sum), nil
+}
+
+// ============================================================================
+// INTEGRITY TRAILER
+// ============================================================================
+
+// TrailerKey is the metadata key of the integrity trailer. The "-bin" suffix
+// tells gRPC to carry the raw digest bytes base64-encoded on the wire.
+const TrailerKey = "tachyon-digest-bin"
+
+var (
+	// ErrMissingTrailer is returned by Check when a response carries no
+	// integrity trailer.
+	ErrMissingTrailer = errors.New("protohash: missing integrity trailer")
+
+	// ErrMismatch is returned by Check when a message does not match its
+	// integrity trailer.
+	ErrMismatch = errors.New("protohash: message does not match integrity trailer")
+)
+
+// Seal returns the integrity trailer value for msg: the raw 32-byte digest
+// HashProto(msg, DomainGeneric).
+func (h *Hasher) Seal(msg any) (string, error) {
+	d, err := h.HashProto(msg, tachyon.DomainGeneric)
+	if err != nil {
+		return "", err
+	}
+	return string(d[:]), nil
+}
+
+// Check verifies msg against the trailer values received with it (the
+// metadata values under TrailerKey). Exactly one value is expected.
+func (h *Hasher) Check(msg any, values []string) error {
+	if len(values) == 0 {
+		return ErrMissingTrailer
+	}
+	if len(values) > 1 {
+		return errors.New("protohash: multiple integrity trailers")
+	}
+	want, err := tachyon.DigestFromBytes([]byte(values[0]))
+	if err != nil {
+		return err
+	}
+	got, err := h.HashProto(msg, tachyon.DomainGeneric)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package protohash
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"tachyon"
+)
+
+// labels stands in for a message with a map field.
+type labels struct {
+	Name   string
+	Labels map[string]string
+}
+
+// marshalJSON is deterministic: encoding/json sorts map keys.
+func marshalJSON(m any) ([]byte, error) { return json.Marshal(m) }
+
+func newLabels() *labels {
+	m := &labels{Name: "svc", Labels: map[string]string{}}
+	for _, k := range []string{"zone", "app", "tier", "env", "team", "owner"} {
+		m.Labels[k] = k + "-value"
+	}
+	return m
+}
+
+func TestHashProto(t *testing.T) {
+	ph := New(marshalJSON)
+
+	first, err := ph.HashProto(newLabels(), tachyon.DomainContentAddressed)
+	if err != nil {
+		t.Fatalf("HashProto failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		d, _ := ph.HashProto(newLabels(), tachyon.DomainContentAddressed)
+		if d != first {
+			t.Fatal("Equal messages should hash equally")
+		}
+	}
+
+	data, _ := marshalJSON(newLabels())
+	want, _ := tachyon.HashWithDomain(data, tachyon.DomainContentAddressed)
+	if first != tachyon.Digest(want) {
+		t.Error("HashProto should equal HashWithDomain of the encoding")
+	}
+	if other, _ := ph.HashProto(newLabels(), tachyon.DomainGeneric); other == first {
+		t.Error("Domain should change the digest")
+	}
+
+	if _, err := New(nil).HashProto(newLabels(), 0); err == nil {
+		t.Error("Missing marshal function should return error")
+	}
+	failing := New(func(any) ([]byte, error) { return nil, errors.New("boom") })
+	if _, err := failing.HashProto(newLabels(), 0); err == nil {
+		t.Error("Marshal error should be returned")
+	}
+}
+
+func TestTrailer(t *testing.T) {
+	ph := New(marshalJSON)
+	msg := newLabels()
+
+	v, err := ph.Seal(msg)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if err := ph.Check(msg, []string{v}); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+
+	msg.Labels["env"] = "prod"
+	if err := ph.Check(msg, []string{v}); !errors.Is(err, ErrMismatch) {
+		t.Errorf("err = %v, want ErrMismatch", err)
+	}
+	if err := ph.Check(msg, nil); !errors.Is(err, ErrMissingTrailer) {
+		t.Errorf("err = %v, want ErrMissingTrailer", err)
+	}
+	if ph.Check(msg, []string{v, v}) == nil || ph.Check(msg, []string{"short"}) == nil {
+		t.Error("Malformed trailers should return error")
+	}
+}
@@ -0,0 +1,509 @@
+package tachyon
+
+import "encoding/binary"
+
+// ============================================================================
+// PURE GO KERNEL
+// ============================================================================
+
+// A line-by-line port of the portable C kernel
+// (algorithms/tachyon/c-reference/tachyon_portable.c), used where cgo is not
+// available (js/wasm, wasip1, CGO_ENABLED=0). It produces the same output as
+// the native backends. Like the portable C kernel it uses table lookups for
+// AES and is therefore not constant-time.
+
+// Kernel constants, see tachyon_impl.h.
+const (
+	goRounds        = 10
+	goBlockSize     = 512
+	goRemainderSize = 64
+	goNumLanes      = 8
+	goLaneStride    = 4
+
+	goldenRatio   = 0x9E3779B97F4A7C15
+	goC0          = 0xB17217F7D1CF79AB // ln(2)
+	goC1          = 0x193EA7AAD030A976 // ln(3)
+	goC2          = 0x9C041F7ED8D336AF // ln(5)
+	goC3          = 0xF2272AE325A57546 // ln(7)
+	goC4          = goldenRatio
+	goC5          = 0x65DC76EFE6E976F7 // ln(11)
+	goC6          = 0x90A08566318A1FD0 // ln(13)
+	goC7          = 0xD54D783F4FEF39DF // ln(17)
+	goChaosBase   = goldenRatio
+	goClmulConst  = 0x6F19C912256B3E22 // ln(31)
+	goClmulConst2 = 0x433FAA0A53988000 // ln(193)
+	goWhitening0  = 0xF1C6C0C096658E40 // ln(19)
+	goWhitening1  = 0x22AFBFBA367E0122 // ln(23)
+)
+
+var goLaneOffsets = [32]uint64{
+	0x9C651DC758F7A6F2, // ln(37)
+	0xB6ACA8B1D589B575, // ln(41)
+	0xC2DE02C29D8222CB, // ln(43)
+	0xD9A345F21E16CB31, // ln(47)
+	0xF8650D044795568F, // ln(53)
+	0x13D97E71CA5E2DA9, // ln(59)
+	0x1C623AC49B03386C, // ln(61)
+	0x3466BC4A044B5829, // ln(67)
+	0x433EFD0935B23D6B, // ln(71)
+	0x4A5B8CC88BF98CD3, // ln(73)
+	0x5E94226BEC5CBFB8, // ln(79)
+	0x6B392358B9206784, // ln(83)
+	0x7D1745EBA2BD8E2D, // ln(89)
+	0x9320423952FE003B, // ln(97)
+	0x9D7889C6EE8C2F8E, // ln(101)
+	0xA27D995644FAF994, // ln(103)
+	0xAC3E82AFD1D6DC79, // ln(107)
+	0xB0FC2CC0554191F5, // ln(109)
+	0xBA36168CE0D6EE1D, // ln(113)
+	0xD81CA5180B90858D, // ln(127)
+	0xE00CEE88B2189A5C, // ln(131)
+	0xEB83DEB56027349A, // ln(137)
+	0xEF39AF05C2C4931B, // ln(139)
+	0x0102A006F9CB3C2A, // ln(149)
+	0x046C738E0014C2F8, // ln(151)
+	0x0E662006821719E4, // ln(157)
+	0x1800035E755EC056, // ln(163)
+	0x1E34D7AD75D7A815, // ln(167)
+	0x273E1E311EA1A70B, // ln(173)
+	0x2FF88423D2160504, // ln(179)
+	0x32D0B391A3CAA870, // ln(181)
+	0x4094FDCB1C2E7EE1, // ln(191)
+}
+
+// Post-merge state for seed 0 without key (SHORT_INIT).
+var goShortInit = [4]vec{
+	{0x8572268C3E8B949A, 0x55260EB0F6D08B28},
+	{0x7B6B869404C510F3, 0x58153672FF7257BB},
+	{0x23AE5234151A861E, 0x436D91128FA3A475},
+	{0x2D3EA94F6D07F7BC, 0x31C028B304D23746},
+}
+
+// AESENC-derived round key chain (RK_CHAIN).
+var goRoundKeys = [goRounds]vec{
+	{0x9E3779B97F4A7C15, 0xFBEB0F5699A30AE2},
+	{0xE0772D418B604247, 0xCB99FBAD212715AA},
+	{0x9943E41C900EA2BD, 0x3391839B4E1DB7D2},
+	{0x3FDD17D01F01E973, 0x4FE62D4E63CB7DB7},
+	{0x7C5B681836BF20E5, 0x20EA7205089674B4},
+	{0x57E52B0B6FD122C4, 0x92E23D97BDB01EAB},
+	{0x9E667CEF92177102, 0x1A1761F6D1C3AAA5},
+	{0x5976F92D468FE2FD, 0xAE3623405BAFD085},
+	{0xCD2AF6F6F29BF341, 0xD310BEDDA16B12D4},
+	{0xD11A12CCD34BBD1B, 0xAC09BEFD5925A5FE},
+}
+
+var aesSbox = [256]byte{
+	0x63, 0x7c, 0x77, 0x7b, 0xf2, 0x6b, 0x6f, 0xc5, 0x30, 0x01, 0x67, 0x2b, 0xfe, 0xd7, 0xab, 0x76,
+	0xca, 0x82, 0xc9, 0x7d, 0xfa, 0x59, 0x47, 0xf0, 0xad, 0xd4, 0xa2, 0xaf, 0x9c, 0xa4, 0x72, 0xc0,
+	0xb7, 0xfd, 0x93, 0x26, 0x36, 0x3f, 0xf7, 0xcc, 0x34, 0xa5, 0xe5, 0xf1, 0x71, 0xd8, 0x31, 0x15,
+	0x04, 0xc7, 0x23, 0xc3, 0x18, 0x96, 0x05, 0x9a, 0x07, 0x12, 0x80, 0xe2, 0xeb, 0x27, 0xb2, 0x75,
+	0x09, 0x83, 0x2c, 0x1a, 0x1b, 0x6e, 0x5a, 0xa0, 0x52, 0x3b, 0xd6, 0xb3, 0x29, 0xe3, 0x2f, 0x84,
+	0x53, 0xd1, 0x00, 0xed, 0x20, 0xfc, 0xb1, 0x5b, 0x6a, 0xcb, 0xbe, 0x39, 0x4a, 0x4c, 0x58, 0xcf,
+	0xd0, 0xef, 0xaa, 0xfb, 0x43, 0x4d, 0x33, 0x85, 0x45, 0xf9, 0x02, 0x7f, 0x50, 0x3c, 0x9f, 0xa8,
+	0x51, 0xa3, 0x40, 0x8f, 0x92, 0x9d, 0x38, 0xf5, 0xbc, 0xb6, 0xda, 0x21, 0x10, 0xff, 0xf3, 0xd2,
+	0xcd, 0x0c, 0x13, 0xec, 0x5f, 0x97, 0x44, 0x17, 0xc4, 0xa7, 0x7e, 0x3d, 0x64, 0x5d, 0x19, 0x73,
+	0x60, 0x81, 0x4f, 0xdc, 0x22, 0x2a, 0x90, 0x88, 0x46, 0xee, 0xb8, 0x14, 0xde, 0x5e, 0x0b, 0xdb,
+	0xe0, 0x32, 0x3a, 0x0a, 0x49, 0x06, 0x24, 0x5c, 0xc2, 0xd3, 0xac, 0x62, 0x91, 0x95, 0xe4, 0x79,
+	0xe7, 0xc8, 0x37, 0x6d, 0x8d, 0xd5, 0x4e, 0xa9, 0x6c, 0x56, 0xf4, 0xea, 0x65, 0x7a, 0xae, 0x08,
+	0xba, 0x78, 0x25, 0x2e, 0x1c, 0xa6, 0xb4, 0xc6, 0xe8, 0xdd, 0x74, 0x1f, 0x4b, 0xbd, 0x8b, 0x8a,
+	0x70, 0x3e, 0xb5, 0x66, 0x48, 0x03, 0xf6, 0x0e, 0x61, 0x35, 0x57, 0xb9, 0x86, 0xc1, 0x1d, 0x9e,
+	0xe1, 0xf8, 0x98, 0x11, 0x69, 0xd9, 0x8e, 0x94, 0x9b, 0x1e, 0x87, 0xe9, 0xce, 0x55, 0x28, 0xdf,
+	0x8c, 0xa1, 0x89, 0x0d, 0xbf, 0xe6, 0x42, 0x68, 0x41, 0x99, 0x2d, 0x0f, 0xb0, 0x54, 0xbb, 0x16,
+}
+
+// ============================================================================
+// 128-BIT PRIMITIVES
+// ============================================================================
+
+// vec is a 128-bit register: lo holds bytes 0-7, hi bytes 8-15 (little-endian).
+type vec struct{ lo, hi uint64 }
+
+func splat(x uint64) vec { return vec{x, x} }
+
+func loadVec(b []byte) vec {
+	return vec{binary.LittleEndian.Uint64(b), binary.LittleEndian.Uint64(b[8:])}
+}
+
+func (v vec) store(b []byte) {
+	binary.LittleEndian.PutUint64(b, v.lo)
+	binary.LittleEndian.PutUint64(b[8:], v.hi)
+}
+
+func xorv(a, b vec) vec { return vec{a.lo ^ b.lo, a.hi ^ b.hi} }
+func addv(a, b vec) vec { return vec{a.lo + b.lo, a.hi + b.hi} }
+
+// aesTables combine SubBytes and MixColumns for one input row each; column
+// words are little-endian (row 0 in the low byte).
+var aesTables = func() (t [4][256]uint32) {
+	for x := 0; x < 256; x++ {
+		s := uint32(aesSbox[x])
+		s2 := uint32(byte(s<<1) ^ byte(s>>7)*0x1b)
+		s3 := s2 ^ s
+		t[0][x] = s2 | s<<8 | s<<16 | s3<<24
+		t[1][x] = s3 | s2<<8 | s<<16 | s<<24
+		t[2][x] = s | s3<<8 | s2<<16 | s<<24
+		t[3][x] = s | s<<8 | s3<<16 | s2<<24
+	}
+	return t
+}()
+
+// aesenc is one AES encryption round (ShiftRows, SubBytes, MixColumns,
+// AddRoundKey), matching _mm_aesenc_si128.
+func aesenc(s, k vec) vec {
+	t := &aesTables
+	c0, c1, c2, c3 := uint32(s.lo), uint32(s.lo>>32), uint32(s.hi), uint32(s.hi>>32)
+	r0 := t[0][byte(c0)] ^ t[1][byte(c1>>8)] ^ t[2][byte(c2>>16)] ^ t[3][byte(c3>>24)]
+	r1 := t[0][byte(c1)] ^ t[1][byte(c2>>8)] ^ t[2][byte(c3>>16)] ^ t[3][byte(c0>>24)]
+	r2 := t[0][byte(c2)] ^ t[1][byte(c3>>8)] ^ t[2][byte(c0>>16)] ^ t[3][byte(c1>>24)]
+	r3 := t[0][byte(c3)] ^ t[1][byte(c0>>8)] ^ t[2][byte(c1>>16)] ^ t[3][byte(c2>>24)]
+	return vec{(uint64(r0) | uint64(r1)<<32) ^ k.lo, (uint64(r2) | uint64(r3)<<32) ^ k.hi}
+}
+
+// clmul is a 64x64 carry-less multiplication with the operand selection of
+// _mm_clmulepi64_si128: bit 0 of imm picks the half of a, bit 4 that of b.
+func clmul(a, b vec, imm int) vec {
+	x, y := a.lo, b.lo
+	if imm&0x01 != 0 {
+		x = a.hi
+	}
+	if imm&0x10 != 0 {
+		y = b.hi
+	}
+	var lo, hi uint64
+	for i := 0; i < 64; i++ {
+		mask := -((y >> i) & 1)
+		lo ^= (x << i) & mask
+		if i > 0 {
+			hi ^= (x >> (64 - i)) & mask
+		}
+	}
+	return vec{lo, hi}
+}
+
+// ============================================================================
+// LINEAR PATH
+// ============================================================================
+
+type goKernelState struct {
+	acc    [32]vec
+	domain uint64
+	seed   uint64
+	key    []byte // nil or 32 bytes
+}
+
+func keyVecs(key []byte) [goLaneStride]vec {
+	k0, k1 := loadVec(key), loadVec(key[16:])
+	gr := splat(goldenRatio)
+	return [goLaneStride]vec{k0, k1, xorv(k0, gr), xorv(k1, gr)}
+}
+
+func (s *goKernelState) init() {
+	cVals := [goNumLanes]uint64{goC0, goC1, goC2, goC3, goC4, goC5, goC6, goC7}
+	for i := range s.acc {
+		base := cVals[i/goLaneStride]
+		offset := uint64(i%goLaneStride) * 2
+		s.acc[i] = vec{base + offset, base + offset + 1}
+	}
+	seedVal := s.seed
+	if seedVal == 0 {
+		seedVal = goC5
+	}
+	seedVec := splat(seedVal)
+	for i := range s.acc {
+		s.acc[i] = aesenc(s.acc[i], seedVec)
+	}
+
+	if s.key != nil {
+		keys := keyVecs(s.key)
+		for i := 0; i < goNumLanes; i++ {
+			lo := splat(goLaneOffsets[i])
+			for j := 0; j < goLaneStride; j++ {
+				idx := i*goLaneStride + j
+				s.acc[idx] = aesenc(s.acc[idx], addv(keys[j], lo))
+				s.acc[idx] = aesenc(s.acc[idx], keys[j])
+			}
+		}
+	}
+}
+
+// roundRobin runs rounds [from, to) of block compression. dataShift selects
+// the data lane offset (0 in phase 1, 4 in phase 2).
+func (s *goKernelState) roundRobin(d *[goNumLanes][goLaneStride]vec, blk vec, from, to, dataShift int) {
+	for r := from; r < to; r++ {
+		rk := goRoundKeys[r]
+		for i := range s.acc {
+			lane := (i/goLaneStride + dataShift) % goNumLanes
+			s.acc[i] = aesenc(s.acc[i], addv(d[lane][i%goLaneStride], addv(rk, addv(splat(goLaneOffsets[i]), blk))))
+		}
+		for i := 0; i < goNumLanes; i++ {
+			src := (i + 3) % goNumLanes // Feedback from lane i+3 (mod 8) for diffusion
+			for j := 0; j < goLaneStride; j++ {
+				d[i][j] = xorv(d[i][j], s.acc[src*goLaneStride+j])
+			}
+		}
+		old := s.acc
+		for i := 0; i < goNumLanes; i++ {
+			copy(s.acc[i*goLaneStride:(i+1)*goLaneStride], old[((i+1)%goNumLanes)*goLaneStride:])
+		}
+	}
+}
+
+// rotateElements rotates the four elements of every lane left by one.
+func (s *goKernelState) rotateElements() {
+	old := s.acc
+	for i := 0; i < goNumLanes; i++ {
+		for j := 0; j < goLaneStride; j++ {
+			s.acc[i*goLaneStride+j] = old[i*goLaneStride+(j+1)%goLaneStride]
+		}
+	}
+}
+
+// midblockMixing breaks lane symmetry between the two compression phases.
+func (s *goKernelState) midblockMixing() {
+	s.rotateElements()
+	at := func(lane, elem int) *vec { return &s.acc[lane*goLaneStride+elem] }
+	for e := 0; e < goLaneStride; e++ {
+		for i := 0; i < 4; i++ {
+			lo, hi := *at(i, e), *at(i+4, e)
+			*at(i, e) = xorv(lo, hi)
+			*at(i+4, e) = addv(hi, lo)
+		}
+	}
+	for e := 0; e < goLaneStride; e++ {
+		for _, p := range [4][2]int{{0, 2}, {1, 3}, {4, 6}, {5, 7}} {
+			a, b := *at(p[0], e), *at(p[1], e)
+			*at(p[0], e) = xorv(a, b)
+			*at(p[1], e) = addv(b, a)
+		}
+	}
+}
+
+func (s *goKernelState) compress(data []byte, blockIdx uint64) {
+	blk := splat(blockIdx)
+	wk := vec{goWhitening0, goWhitening1}
+
+	saves := s.acc // Davies-Meyer feed-forward
+	var d [goNumLanes][goLaneStride]vec
+	for i := 0; i < goNumLanes; i++ {
+		for j := 0; j < goLaneStride; j++ {
+			d[i][j] = aesenc(loadVec(data[(i*goLaneStride+j)*16:]), wk)
+		}
+	}
+
+	s.roundRobin(&d, blk, 0, 5, 0)
+	s.midblockMixing()
+	s.roundRobin(&d, blk, 5, goRounds, 4)
+
+	s.rotateElements()
+	for i := range s.acc {
+		s.acc[i] = xorv(s.acc[i], saves[i])
+	}
+}
+
+// remainderChunks absorbs whole 64-byte chunks of the final partial block
+// and returns the number of bytes consumed.
+func (s *goKernelState) remainderChunks(rem []byte) int {
+	wk := vec{goWhitening0, goWhitening1}
+	off := 0
+	for chunk := 0; len(rem)-off >= goRemainderSize; chunk++ {
+		var d [goLaneStride]vec
+		for j := range d {
+			d[j] = aesenc(loadVec(rem[off+j*16:]), wk)
+		}
+		base := chunk * goLaneStride
+		var saves [goLaneStride]vec
+		copy(saves[:], s.acc[base:])
+		for r := 0; r < goRounds; r++ {
+			rk := goRoundKeys[r]
+			for j := 0; j < goLaneStride; j++ {
+				s.acc[base+j] = aesenc(s.acc[base+j], addv(d[j], addv(rk, splat(goLaneOffsets[base+j]))))
+			}
+			t0, t1, t2, t3 := s.acc[base], s.acc[base+1], s.acc[base+2], s.acc[base+3]
+			d[0], d[1], d[2], d[3] = xorv(d[0], t1), xorv(d[1], t2), xorv(d[2], t3), xorv(d[3], t0)
+			s.acc[base], s.acc[base+1], s.acc[base+2], s.acc[base+3] = t1, t2, t3, t0
+		}
+		for j := 0; j < goLaneStride; j++ {
+			s.acc[base+j] = xorv(s.acc[base+j], saves[j])
+		}
+		off += goRemainderSize
+	}
+	return off
+}
+
+// treeMerge folds 32 lanes into 4: 32 → 16 → 8 → 4.
+func (s *goKernelState) treeMerge() {
+	for level, k := range [3]vec{splat(goC5), splat(goC6), splat(goC7)} {
+		half := 16 >> level
+		for i := 0; i < half; i++ {
+			s.acc[i] = aesenc(s.acc[i], xorv(s.acc[i+half], k))
+			s.acc[i] = aesenc(s.acc[i], xorv(s.acc[i], k))
+		}
+	}
+}
+
+// clmulHardening applies the quadratic CLMUL mixing.
+func (s *goKernelState) clmulHardening() {
+	k := vec{goClmulConst, goClmulConst2}
+	for i := 0; i < goLaneStride; i++ {
+		cl1 := xorv(clmul(s.acc[i], k, 0x00), clmul(s.acc[i], k, 0x11))
+		mid := aesenc(s.acc[i], cl1)
+		cl2 := clmul(mid, mid, 0x01)
+		s.acc[i] = aesenc(s.acc[i], xorv(cl1, cl2))
+	}
+}
+
+// finalBlock injects length and domain and absorbs the padding block.
+func (s *goKernelState) finalBlock(dPad *[goLaneStride]vec, totalLen uint64) {
+	var saveFinal [goLaneStride]vec
+	copy(saveFinal[:], s.acc[:goLaneStride])
+	meta := [goLaneStride]vec{
+		{s.domain ^ totalLen, goChaosBase},
+		{totalLen, s.domain},
+		{goChaosBase, totalLen},
+		{s.domain, goChaosBase},
+	}
+	for i := 0; i < goLaneStride; i++ {
+		s.acc[i] = xorv(xorv(s.acc[i], dPad[i]), meta[i])
+	}
+	for r := 0; r < goRounds; r++ {
+		rk := goRoundKeys[r]
+		for i := 0; i < goLaneStride; i++ {
+			s.acc[i] = aesenc(s.acc[i], addv(dPad[i], rk))
+		}
+		s.acc[0], s.acc[1], s.acc[2], s.acc[3] = s.acc[1], s.acc[2], s.acc[3], s.acc[0]
+		if r%2 == 1 {
+			for i := 0; i < goLaneStride; i++ {
+				dPad[i] = xorv(dPad[i], s.acc[i])
+			}
+		}
+	}
+	for i := 0; i < goLaneStride; i++ {
+		s.acc[i] = xorv(s.acc[i], saveFinal[i])
+	}
+}
+
+// keyReabsorption mixes the key into the final lanes (keyed mode only).
+func (s *goKernelState) keyReabsorption() {
+	if s.key == nil {
+		return
+	}
+	k0, k1 := loadVec(s.key), loadVec(s.key[16:])
+	for _, ks := range [4][4]vec{
+		{k0, k1, k1, k0},
+		{k1, k0, k0, k1},
+		{k0, k1, k0, k1},
+		{k0, k0, k1, k1},
+	} {
+		for i := 0; i < goLaneStride; i++ {
+			s.acc[i] = aesenc(s.acc[i], ks[i])
+		}
+	}
+}
+
+// laneReduction reduces 4 lanes to the 256-bit output.
+func laneReduction(acc *[goLaneStride]vec, out *Digest) {
+	m0, m1, m2 := splat(goC5), splat(goC6), splat(goC7)
+
+	var a [goLaneStride]vec
+	for i := range a {
+		a[i] = aesenc(acc[i], acc[i])
+	}
+	distant := func(x [goLaneStride]vec) [goLaneStride]vec {
+		return [goLaneStride]vec{aesenc(x[0], x[2]), aesenc(x[1], x[3]), aesenc(x[2], x[0]), aesenc(x[3], x[1])}
+	}
+	adjacent := func(x [goLaneStride]vec) [goLaneStride]vec {
+		return [goLaneStride]vec{aesenc(x[0], x[1]), aesenc(x[1], xorv(x[0], m2)), aesenc(x[2], xorv(x[3], m1)), aesenc(x[3], xorv(x[2], m0))}
+	}
+	e := adjacent(distant(adjacent(distant(a))))
+
+	e[0].store(out[:16])
+	e[1].store(out[16:])
+}
+
+func (s *goKernelState) finalize(rem []byte, totalLen uint64, out *Digest) {
+	off := s.remainderChunks(rem)
+
+	var pad [goRemainderSize]byte
+	n := copy(pad[:], rem[off:])
+	pad[n] = 0x80 // Merkle-Damgård padding sentinel
+
+	wk := vec{goWhitening0, goWhitening1}
+	var dPad [goLaneStride]vec
+	for j := range dPad {
+		dPad[j] = aesenc(loadVec(pad[j*16:]), wk)
+	}
+
+	s.treeMerge()
+	s.clmulHardening()
+	s.finalBlock(&dPad, totalLen)
+	s.keyReabsorption()
+
+	var acc [goLaneStride]vec
+	copy(acc[:], s.acc[:goLaneStride])
+	laneReduction(&acc, out)
+}
+
+// ============================================================================
+// SHORT PATH
+// ============================================================================
+
+// hashShort handles inputs under 64 bytes with seed 0 and no key.
+func hashShort(data []byte, domain uint64, out *Digest) {
+	acc := goShortInit
+	wk := vec{goWhitening0, goWhitening1}
+
+	var blk [goRemainderSize]byte
+	copy(blk[:], data)
+	blk[len(data)] = 0x80 // Merkle-Damgård padding sentinel
+
+	var d [goLaneStride]vec
+	for i := range d {
+		d[i] = aesenc(loadVec(blk[i*16:]), wk)
+	}
+
+	saves := acc
+	n := uint64(len(data))
+	meta := [goLaneStride]vec{
+		{domain ^ n, goChaosBase},
+		{n, domain},
+		{goChaosBase, n},
+		{domain, goChaosBase},
+	}
+	for i := range acc {
+		acc[i] = xorv(acc[i], xorv(d[i], meta[i]))
+	}
+	for r := 0; r < goRounds; r++ {
+		rk := goRoundKeys[r]
+		for i := range acc {
+			acc[i] = aesenc(acc[i], addv(d[i], addv(rk, splat(goLaneOffsets[i]))))
+		}
+		if r%2 == 1 {
+			t0, t1, t2, t3 := acc[0], acc[1], acc[2], acc[3]
+			d[0], d[1], d[2], d[3] = xorv(d[0], t1), xorv(d[1], t2), xorv(d[2], t3), xorv(d[3], t0)
+		}
+		acc[0], acc[1], acc[2], acc[3] = acc[1], acc[2], acc[3], acc[0]
+	}
+	for i := range acc {
+		acc[i] = xorv(acc[i], saves[i])
+	}
+	laneReduction(&acc, out)
+}
+
+// goKernel hashes data without the Merkle tree (tachyon_portable_oneshot).
+func goKernel(data []byte, domain, seed uint64, key []byte, out *Digest) {
+	if len(data) < goRemainderSize && seed == 0 && key == nil {
+		hashShort(data, domain, out)
+		return
+	}
+	s := goKernelState{domain: domain, seed: seed, key: key}
+	s.init()
+	total := uint64(len(data))
+	var blockIdx uint64
+	for len(data) >= goBlockSize {
+		s.compress(data[:goBlockSize], blockIdx)
+		data = data[goBlockSize:]
+		blockIdx++
+	}
+	s.finalize(data, total, out)
+}
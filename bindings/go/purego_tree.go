@@ -0,0 +1,240 @@
+package tachyon
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// PURE GO MERKLE TREE
+// ============================================================================
+
+// Port of the Merkle tree engine in tachyon_dispatcher.c: inputs of at least
+// nativeChunkSize bytes are split into chunks hashed as leaves, combined
+// pairwise into nodes, and the root is committed together with domain and
+// length.
+
+const (
+	goDomainLeaf    = 0xFFFFFFFF00000000
+	goDomainNode    = 0xFFFFFFFF00000001
+	goMaxTreeLevels = 64
+)
+
+// goState is the pure Go streaming state.
+type goState struct {
+	buf      []byte // Pending bytes of the current chunk
+	totalLen uint64
+	domain   uint64
+	seed     uint64
+	key      []byte // nil or 32 bytes
+	stack    [goMaxTreeLevels]Digest
+	usage    uint64 // Bit i set: stack[i] holds a subtree root
+
+	// kernel hashes the inputs of sum and push, all shorter than a chunk;
+	// nil means goKernel. parallelHash sets treeKernel to assemble natively
+	// hashed leaves.
+	kernel func(data []byte, domain, seed uint64, key []byte, out *Digest) error
+	err    error // First kernel failure
+}
+
+// run hashes a short input with the state's kernel.
+func (s *goState) run(data []byte, domain uint64, out *Digest) {
+	if s.kernel == nil {
+		goKernel(data, domain, s.seed, s.key, out)
+		return
+	}
+	// A local keeps out from escaping through the indirect call
+	var d Digest
+	if err := s.kernel(data, domain, s.seed, s.key, &d); err != nil && s.err == nil {
+		s.err = err
+	}
+	*out = d
+}
+
+func newGoState(domain, seed uint64, key []byte) *goState {
+	s := &goState{domain: domain, seed: seed}
+	if key != nil {
+		s.key = append([]byte(nil), key...)
+	}
+	return s
+}
+
+// push adds a subtree root at level 0, merging equal-height subtrees.
+func (s *goState) push(h Digest) {
+	var pair [2 * DigestSize]byte
+	for level := 0; level < goMaxTreeLevels; level++ {
+		bit := uint64(1) << level
+		if s.usage&bit == 0 {
+			s.stack[level] = h
+			s.usage |= bit
+			return
+		}
+		copy(pair[:], s.stack[level][:])
+		copy(pair[DigestSize:], h[:])
+		s.run(pair[:], goDomainNode, &h)
+		s.usage &^= bit
+	}
+}
+
+func (s *goState) update(p []byte) {
+	s.totalLen += uint64(len(p))
+	for len(p) > 0 {
+		var h Digest
+		if len(s.buf) == 0 && len(p) >= nativeChunkSize {
+			// Whole chunk: hash in place
+			goKernel(p[:nativeChunkSize], goDomainLeaf, s.seed, s.key, &h)
+			s.push(h)
+			p = p[nativeChunkSize:]
+			continue
+		}
+		n := min(nativeChunkSize-len(s.buf), len(p))
+		s.buf = append(s.buf, p[:n]...)
+		p = p[n:]
+		if len(s.buf) == nativeChunkSize {
+			goKernel(s.buf, goDomainLeaf, s.seed, s.key, &h)
+			s.push(h)
+			s.buf = s.buf[:0]
+		}
+	}
+}
+
+// sum writes the digest of everything written so far; s is not modified
+// beyond recording a kernel failure.
+func (s *goState) sum(out *Digest) {
+	if s.usage == 0 {
+		s.run(s.buf, s.domain, out)
+		return
+	}
+
+	t := goState{seed: s.seed, key: s.key, stack: s.stack, usage: s.usage, kernel: s.kernel}
+	if len(s.buf) > 0 {
+		var h Digest
+		s.run(s.buf, goDomainLeaf, &h)
+		t.push(h)
+	}
+
+	var root Digest
+	var pair [2 * DigestSize]byte
+	first := true
+	for i := 0; i < goMaxTreeLevels; i++ {
+		if t.usage&(1<<i) == 0 {
+			continue
+		}
+		if first {
+			root = t.stack[i]
+			first = false
+			continue
+		}
+		copy(pair[:], t.stack[i][:])
+		copy(pair[DigestSize:], root[:])
+		t.run(pair[:], goDomainNode, &root)
+	}
+	if t.err != nil && s.err == nil {
+		s.err = t.err
+	}
+
+	// Length commitment: prevents length extension attacks
+	var final [DigestSize + 16]byte
+	copy(final[:], root[:])
+	binary.LittleEndian.PutUint64(final[DigestSize:], s.domain)
+	binary.LittleEndian.PutUint64(final[DigestSize+8:], s.totalLen)
+	s.run(final[:], 0, out)
+}
+
+func (s *goState) reset() {
+	s.buf = s.buf[:0]
+	s.totalLen = 0
+	s.usage = 0
+}
+
+func (s *goState) clone() *goState {
+	c := *s
+	c.buf = append([]byte(nil), s.buf...)
+	return &c
+}
+
+// goHashLeaves is the pure Go tachyon_hash_leaves: it hashes len(out) whole
+// chunks of data into Merkle leaves.
+func goHashLeaves(data []byte, seed uint64, key []byte, out []Digest) {
+	for i := range out {
+		goKernel(data[i*nativeChunkSize:(i+1)*nativeChunkSize], goDomainLeaf, seed, key, &out[i])
+	}
+}
+
+// goHashFull is the pure Go tachyon_hash_full.
+func goHashFull(data []byte, domain, seed uint64, key []byte, out *Digest) {
+	if len(data) < nativeChunkSize {
+		goKernel(data, domain, seed, key, out)
+		return
+	}
+	s := goState{domain: domain, seed: seed, key: key}
+	s.update(data)
+	s.sum(out)
+}
+
+// ============================================================================
+// PURE GO PRIMITIVES
+// ============================================================================
+
+// The Go counterparts of the engine primitives, used by engine_purego.go and
+// by engine_cgo.go when SetImplementation(ImplPureGo) is in effect.
+
+func goHashSizedInto(data []byte, domain, seed uint64, key []byte, out []byte) {
+	var root Digest
+	goHashFull(data, domain, seed, key, &root)
+	if len(out) <= DigestSize {
+		copy(out, root[:])
+		return
+	}
+	goExpandInto(&root, out)
+}
+
+func goExpandInto(root *Digest, out []byte) {
+	var counter [8]byte
+	var block Digest
+	for i := 0; i*DigestSize < len(out); i++ {
+		binary.LittleEndian.PutUint64(counter[:], uint64(i))
+		goHashFull(counter[:], DomainMessageAuth, 0, root[:], &block)
+		copy(out[i*DigestSize:], block[:])
+	}
+}
+
+func goHashExpand(data []byte, seed uint64, out []byte) {
+	var root Digest
+	goHashFull(data, 0, seed, nil, &root)
+	goExpandInto(&root, out)
+}
+
+func goHashSeededMulti(data []byte, seeds []uint64, out []Digest) {
+	for i, seed := range seeds {
+		goHashFull(data, 0, seed, nil, &out[i])
+	}
+}
+
+func goHashKeyedMulti(inputs [][]byte, key []byte, out []Digest) {
+	for i, in := range inputs {
+		goHashFull(in, DomainMessageAuth, 0, key, &out[i])
+	}
+}
+
+func goKeystream(key *[32]byte, nonce []byte, counter uint64, out []byte) error {
+	if len(nonce) > 32 {
+		return errInternal
+	}
+	input := make([]byte, 8+len(nonce))
+	copy(input[8:], nonce)
+	for i := 0; i*DigestSize < len(out); i++ {
+		binary.LittleEndian.PutUint64(input, counter+uint64(i))
+		goHashFull(input, DomainMessageAuth, 0, key[:], (*Digest)(out[i*DigestSize:]))
+	}
+	return nil
+}
+
+func goDeriveKey(context string, material []byte, out *Digest) error {
+	if !utf8.ValidString(context) {
+		return errors.New("tachyon: internal error or invalid UTF-8")
+	}
+	goHashFull([]byte(context), DomainKeyDerivation, 0, material, out)
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package rangedigest computes per-range digests over ordered key/value data.
+//
+// It is the core primitive for verifying backfills and migrations: digest the
+// same key ranges on the source and destination stores, then Compare the
+// results to find the ranges that need to be re-copied.
+//
+// Example:
+//
+//	bounds := []rangedigest.Range{{Start: nil, End: []byte("m")}, {Start: []byte("m")}}
+//	src, _ := rangedigest.RangeDigest(srcIter, bounds)
+//	dst, _ := rangedigest.RangeDigest(dstIter, bounds)
+//	report := rangedigest.Compare(src, dst)
+//	for _, m := range report.Mismatches {
+//	    fmt.Println("re-copy", m.Range)
+//	}
+package rangedigest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"tachyon"
+)
+
+// ============================================================================
+// TYPES
+// ============================================================================
+
+// Iterator yields key/value pairs in strictly ascending key order
+// (as defined by bytes.Compare).
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Err() error
+}
+
+// Range is the half-open key interval [Start, End).
+//
+// A nil Start is unbounded below and a nil End is unbounded above.
+type Range struct {
+	Start []byte
+	End   []byte
+}
+
+// Contains reports whether key lies within the range.
+func (r Range) Contains(key []byte) bool {
+	if r.Start != nil && bytes.Compare(key, r.Start) < 0 {
+		return false
+	}
+	return r.End == nil || bytes.Compare(key, r.End) < 0
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("[%q, %q)", r.Start, r.End)
+}
+
+// Result is the digest of all pairs within one range.
+type Result struct {
+	Range  Range
+	Count  int64          // Number of pairs in the range
+	Digest tachyon.Digest // DomainDatabaseIndex digest of the pairs
+}
+
+// ============================================================================
+// DIGESTING
+// ============================================================================
+
+// RangeDigest consumes it and returns one Result per range in bounds.
+//
+// bounds must be sorted and non-overlapping. Pairs outside every range are
+// skipped. Each pair is absorbed as len(key) | key | len(value) | value
+// (lengths as little-endian uint64), so the digest of a range is independent
+// of how the store batches its iteration.
+func RangeDigest(it Iterator, bounds []Range) ([]Result, error) {
+	if err := validate(bounds); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(bounds))
+	hashers := make([]*tachyon.Hasher, len(bounds))
+	defer func() {
+		for _, h := range hashers {
+			if h != nil {
+				h.Close()
+			}
+		}
+	}()
+	for i := range bounds {
+		results[i].Range = bounds[i]
+		hashers[i] = tachyon.NewHasherWithDomain(tachyon.DomainDatabaseIndex)
+		if hashers[i] == nil {
+			return nil, errors.New("rangedigest: could not create hasher")
+		}
+	}
+
+	var prev []byte
+	var seen bool
+	var lenBuf [8]byte
+	idx := 0
+	for it.Next() {
+		key := it.Key()
+		if seen && bytes.Compare(key, prev) <= 0 {
+			return nil, fmt.Errorf("rangedigest: iterator keys out of order at %q", key)
+		}
+		prev, seen = append(prev[:0], key...), true
+
+		// Advance past ranges that end at or before key
+		for idx < len(bounds) && bounds[idx].End != nil && bytes.Compare(key, bounds[idx].End) >= 0 {
+			idx++
+		}
+		if idx == len(bounds) {
+			break
+		}
+		if !bounds[idx].Contains(key) {
+			continue
+		}
+
+		h := hashers[idx]
+		value := it.Value()
+		binary.LittleEndian.PutUint64(lenBuf[:], uint64(len(key)))
+		h.Update(lenBuf[:])
+		h.Update(key)
+		binary.LittleEndian.PutUint64(lenBuf[:], uint64(len(value)))
+		h.Update(lenBuf[:])
+		h.Update(value)
+		results[idx].Count++
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, h := range hashers {
+		sum, err := h.Finalize()
+		if err != nil {
+			return nil, err
+		}
+		copy(results[i].Digest[:], sum)
+	}
+	return results, nil
+}
+
+// validate checks that bounds are well-formed, sorted and non-overlapping.
+func validate(bounds []Range) error {
+	for i, r := range bounds {
+		if r.Start != nil && r.End != nil && bytes.Compare(r.Start, r.End) >= 0 {
+			return fmt.Errorf("rangedigest: empty or inverted range %v", r)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := bounds[i-1]
+		if prev.End == nil || r.Start == nil || bytes.Compare(r.Start, prev.End) < 0 {
+			return fmt.Errorf("rangedigest: ranges %v and %v overlap or are unsorted", prev, r)
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// COMPARISON
+// ============================================================================
+
+// Mismatch describes a range whose digests differ between two stores.
+type Mismatch struct {
+	Range    Range
+	SrcCount int64
+	DstCount int64
+}
+
+// Report summarizes the comparison of source and destination results.
+type Report struct {
+	Matched    int        // Ranges with identical digests
+	Mismatches []Mismatch // Ranges that differ
+}
+
+// OK reports whether every range matched.
+func (r Report) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// Compare matches src and dst results by position and reports differing ranges.
+//
+// Both slices must come from RangeDigest calls with the same bounds. Ranges
+// present on only one side are reported as mismatches.
+func Compare(src, dst []Result) Report {
+	var report Report
+	for i := 0; i < max(len(src), len(dst)); i++ {
+		var m Mismatch
+		switch {
+		case i >= len(src):
+			m = Mismatch{Range: dst[i].Range, DstCount: dst[i].Count}
+		case i >= len(dst):
+			m = Mismatch{Range: src[i].Range, SrcCount: src[i].Count}
+		case src[i].Digest == dst[i].Digest:
+			report.Matched++
+			continue
+		default:
+			m = Mismatch{Range: src[i].Range, SrcCount: src[i].Count, DstCount: dst[i].Count}
+		}
+		report.Mismatches = append(report.Mismatches, m)
+	}
+	return report
+}
@@ -0,0 +1,104 @@
+package rangedigest
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// mapIterator iterates a map in sorted key order.
+type mapIterator struct {
+	keys []string
+	data map[string]string
+	pos  int
+}
+
+func newMapIterator(data map[string]string) *mapIterator {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &mapIterator{keys: keys, data: data, pos: -1}
+}
+
+func (m *mapIterator) Next() bool    { m.pos++; return m.pos < len(m.keys) }
+func (m *mapIterator) Key() []byte   { return []byte(m.keys[m.pos]) }
+func (m *mapIterator) Value() []byte { return []byte(m.data[m.keys[m.pos]]) }
+func (m *mapIterator) Err() error    { return nil }
+
+func store() map[string]string {
+	data := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		data[fmt.Sprintf("key-%03d", i)] = fmt.Sprintf("value-%d", i)
+	}
+	return data
+}
+
+var bounds = []Range{
+	{End: []byte("key-100")},
+	{Start: []byte("key-100"), End: []byte("key-200")},
+	{Start: []byte("key-200")},
+}
+
+func TestIdenticalStores(t *testing.T) {
+	src, err := RangeDigest(newMapIterator(store()), bounds)
+	if err != nil {
+		t.Fatalf("RangeDigest failed: %v", err)
+	}
+	dst, _ := RangeDigest(newMapIterator(store()), bounds)
+
+	for i, r := range src {
+		if r.Count != 100 {
+			t.Errorf("range %d: Count = %d, want 100", i, r.Count)
+		}
+	}
+	if src[0].Digest == src[1].Digest {
+		t.Error("Different ranges should have different digests")
+	}
+
+	report := Compare(src, dst)
+	if !report.OK() || report.Matched != 3 {
+		t.Errorf("Report = %+v, want 3 matched", report)
+	}
+}
+
+func TestDetectsDivergence(t *testing.T) {
+	dstData := store()
+	dstData["key-150"] = "corrupted"
+	delete(dstData, "key-250")
+
+	src, _ := RangeDigest(newMapIterator(store()), bounds)
+	dst, _ := RangeDigest(newMapIterator(dstData), bounds)
+
+	report := Compare(src, dst)
+	if report.Matched != 1 || len(report.Mismatches) != 2 {
+		t.Fatalf("Report = %+v, want 1 matched and 2 mismatches", report)
+	}
+	if m := report.Mismatches[1]; m.SrcCount != 100 || m.DstCount != 99 {
+		t.Errorf("Missing row: counts = %d/%d, want 100/99", m.SrcCount, m.DstCount)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	overlap := []Range{
+		{End: []byte("m")},
+		{Start: []byte("a")},
+	}
+	if _, err := RangeDigest(newMapIterator(store()), overlap); err == nil {
+		t.Error("Overlapping ranges should return error")
+	}
+
+	inverted := []Range{{Start: []byte("z"), End: []byte("a")}}
+	if _, err := RangeDigest(newMapIterator(store()), inverted); err == nil {
+		t.Error("Inverted range should return error")
+	}
+
+	// An empty first key still takes part in the order check
+	for _, keys := range [][]string{{"", ""}, {"", "a", "a"}} {
+		it := &mapIterator{keys: keys, data: map[string]string{}, pos: -1}
+		if _, err := RangeDigest(it, []Range{{}}); err == nil {
+			t.Errorf("Duplicate keys %q should return error", keys)
+		}
+	}
+}
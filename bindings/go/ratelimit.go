@@ -0,0 +1,36 @@
+package tachyon
+
+import "encoding/binary"
+
+// ============================================================================
+// RATE LIMITER KEYS
+// ============================================================================
+
+// rateLimitLabel separates limiter keys from other uses of HashSeeded.
+const rateLimitLabel = "tachyon ratelimit v1"
+
+// RateLimitKey returns the 64-bit limiter bucket key for route within tenant.
+//
+// Storing this key instead of the raw strings keeps per-bucket memory fixed
+// at 8 bytes, and every instance sharing the salt computes the same key. The
+// derivation is specified so other languages can reproduce it exactly:
+//
+//	input = "tachyon ratelimit v1" || 0x00 || LE32(len(tenant)) || tenant || route
+//	key   = LE64(HashSeeded(input, salt)[0:8])
+//
+// The length prefix keeps ("ab", "c") and ("a", "bc") apart. Pass an empty
+// route for a tenant-wide bucket. Rotate salt to reshuffle all buckets.
+func RateLimitKey(tenant, route string, salt uint64) (uint64, error) {
+	input := make([]byte, 0, len(rateLimitLabel)+1+4+len(tenant)+len(route))
+	input = append(input, rateLimitLabel...)
+	input = append(input, 0)
+	input = binary.LittleEndian.AppendUint32(input, uint32(len(tenant)))
+	input = append(input, tenant...)
+	input = append(input, route...)
+
+	hash, err := HashSeeded(input, salt)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(hash[:8]), nil
+}
@@ -0,0 +1,64 @@
+package tachyon
+
+import (
+	"context"
+	"io"
+)
+
+// ============================================================================
+// READER HASHING
+// ============================================================================
+
+// readerBufferSize is the update size of HashReaderContext: 16 native chunks
+// hashed between cancellation checks.
+const readerBufferSize = 16 * nativeChunkSize
+
+// WithProgress reports progress to fn after each chunk HashReaderContext
+// hashes, with the total number of bytes processed so far. fn runs on the
+// hashing goroutine and should return quickly. Hash and New ignore it.
+func WithProgress(fn func(processed int64)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// HashReaderContext hashes everything read from r, checking ctx between
+// chunks so long inputs (e.g. a multi-GB file) can be abandoned.
+//
+// opts are those of Hash, plus WithProgress. The result equals Hash of the
+// same data with the same options. If ctx is done before r is exhausted,
+// HashReaderContext returns ctx.Err().
+func HashReaderContext(ctx context.Context, r io.Reader, opts ...Option) ([]byte, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	buf := make([]byte, readerBufferSize)
+	var processed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if uerr := h.Update(buf[:n]); uerr != nil {
+				return nil, uerr
+			}
+			processed += int64(n)
+			if o.progress != nil {
+				o.progress(processed)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return h.Finalize()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tachyon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestHashReaderContext(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3*readerBufferSize/16+1000)
+	key := bytes.Repeat([]byte("k"), 32)
+
+	var reports []int64
+	got, err := HashReaderContext(context.Background(), bytes.NewReader(data),
+		WithKey(key), WithProgress(func(n int64) { reports = append(reports, n) }))
+	if err != nil {
+		t.Fatalf("HashReaderContext failed: %v", err)
+	}
+	want, _ := Hash(data, WithKey(key))
+	if !bytes.Equal(got, want) {
+		t.Error("HashReaderContext should equal Hash")
+	}
+
+	if len(reports) != 4 || reports[len(reports)-1] != int64(len(data)) {
+		t.Errorf("progress reports = %v, want 4 ending at %d", reports, len(data))
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i] <= reports[i-1] {
+			t.Error("Progress should increase")
+		}
+	}
+
+	empty, err := HashReaderContext(context.Background(), bytes.NewReader(nil))
+	if err != nil {
+		t.Fatalf("HashReaderContext failed: %v", err)
+	}
+	if want, _ := Hash(nil); !bytes.Equal(empty, want) {
+		t.Error("Empty reader should equal Hash(nil)")
+	}
+}
+
+func TestHashReaderContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data := make([]byte, 4*readerBufferSize)
+	calls := 0
+	_, err := HashReaderContext(ctx, bytes.NewReader(data), WithProgress(func(int64) {
+		calls++
+		cancel()
+	}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("hashed %d chunks after cancel, want 1", calls)
+	}
+}
+
+func TestHashReaderContextErrors(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(bytes.NewReader([]byte("partial")), &failingReader{boom})
+	if _, err := HashReaderContext(context.Background(), r); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+	if _, err := HashReaderContext(context.Background(), bytes.NewReader(nil), WithKey([]byte("short"))); err == nil {
+		t.Error("Invalid options should return error")
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
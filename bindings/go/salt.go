@@ -0,0 +1,67 @@
+package tachyon
+
+import "errors"
+
+// ============================================================================
+// SALTED HASHING
+// ============================================================================
+
+// saltContext separates salt-derived keys from the caller's keys and from
+// personalization keys.
+const saltContext = "tachyon salt v1"
+
+// HashSalted computes the 32-byte hash of data under a salt of any non-zero
+// length, e.g. a per-tenant salt for hashing identifiers so the same
+// identifier hashes differently in each tenant.
+//
+// A salt is public input, not a secret key. It is absorbed as
+//
+//	s = Hash(salt, WithDomain(DomainKeyDerivation))
+//	k = DeriveKey("tachyon salt v1", s)
+//	HashSalted(data, salt) = Hash(data, WithKey(k), WithDomain(DomainGeneric))
+//
+// so a salted hash never equals HashKeyed or a WithPersonalization hash
+// computed with the same bytes.
+func HashSalted(data, salt []byte) ([]byte, error) {
+	key, err := saltKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key[:])
+	var hash Digest
+	if err := hashFull(data, DomainGeneric, 0, key[:], &hash); err != nil {
+		return nil, err
+	}
+	return hash[:], nil
+}
+
+// NewHasherSalted creates a streaming hasher whose Finalize returns
+// HashSalted of all data written.
+func NewHasherSalted(salt []byte) (*Hasher, error) {
+	key, err := saltKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key[:])
+	h := startHasher(DomainGeneric, 0, key[:], 0)
+	if h == nil {
+		return nil, errors.New("tachyon: could not create hasher")
+	}
+	return h, nil
+}
+
+// saltKey derives the hashing key of a salt.
+func saltKey(salt []byte) (Digest, error) {
+	if len(salt) == 0 {
+		return Digest{}, errors.New("tachyon: salt cannot be empty")
+	}
+	material, err := hashDigest(salt, DomainKeyDerivation, 0, nil)
+	if err != nil {
+		return Digest{}, err
+	}
+	var key Digest
+	if err := deriveKey(saltContext, material[:], &key); err != nil {
+		return Digest{}, err
+	}
+	return key, nil
+}
@@ -0,0 +1,101 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashSalted(t *testing.T) {
+	data := []byte("user-4711@example.com")
+	salt := bytes.Repeat([]byte{0x5a}, 16)
+
+	first, err := HashSalted(data, salt)
+	if err != nil {
+		t.Fatalf("HashSalted failed: %v", err)
+	}
+	if len(first) != DigestSize {
+		t.Fatalf("len = %d, want %d", len(first), DigestSize)
+	}
+	if again, _ := HashSalted(data, salt); !bytes.Equal(again, first) {
+		t.Error("HashSalted should be deterministic")
+	}
+
+	// The documented scheme
+	material, _ := HashWithDomain(salt, DomainKeyDerivation)
+	key, _ := DeriveKey(saltContext, material)
+	want, _ := Hash(data, WithKey(key), WithDomain(DomainGeneric))
+	if !bytes.Equal(first, want) {
+		t.Error("HashSalted should follow the documented scheme")
+	}
+
+	// Every salt length works, and each salt gives a different hash
+	seen := map[string]bool{string(first): true}
+	for _, s := range [][]byte{{1}, bytes.Repeat([]byte{0x5a}, 15), bytes.Repeat([]byte{0x5a}, 17), make([]byte, 32), make([]byte, 1000)} {
+		h, err := HashSalted(data, s)
+		if err != nil {
+			t.Fatalf("HashSalted with %d-byte salt failed: %v", len(s), err)
+		}
+		if seen[string(h)] {
+			t.Errorf("%d-byte salt collides with another salt", len(s))
+		}
+		seen[string(h)] = true
+	}
+
+	if _, err := HashSalted(data, nil); err == nil {
+		t.Error("Empty salt should return error")
+	}
+}
+
+func TestHashSaltedDistinctFromKeyed(t *testing.T) {
+	data := []byte("identifier")
+	salt := bytes.Repeat([]byte{3}, 32)
+
+	salted, _ := HashSalted(data, salt)
+	keyed, _ := HashKeyed(data, salt)
+	generic, _ := Hash(data, WithKey(salt), WithDomain(DomainGeneric))
+	personalized, _ := Hash(data, WithPersonalization(string(salt)))
+	for name, other := range map[string][]byte{"HashKeyed": keyed, "generic keyed": generic, "personalized": personalized} {
+		if bytes.Equal(salted, other) {
+			t.Errorf("Salted hash should differ from %s hash with the same bytes", name)
+		}
+	}
+}
+
+func TestNewHasherSalted(t *testing.T) {
+	salt := []byte("tenant-42-salt")
+	data := bytes.Repeat([]byte("record "), 50000) // Spans two chunks
+
+	h, err := NewHasherSalted(salt)
+	if err != nil {
+		t.Fatalf("NewHasherSalted failed: %v", err)
+	}
+	h.Write(data[:1000])
+	h.Write(data[1000:])
+	got, err := h.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	want, _ := HashSalted(data, salt)
+	if !bytes.Equal(got, want) {
+		t.Error("Streaming should equal HashSalted")
+	}
+
+	h.Reset()
+	h.Write([]byte("x"))
+	if got, want := h.Sum(nil), mustHashSalted(t, []byte("x"), salt); !bytes.Equal(got, want) {
+		t.Error("Reset should keep the salt")
+	}
+
+	if _, err := NewHasherSalted([]byte{}); err == nil {
+		t.Error("Empty salt should return error")
+	}
+}
+
+func mustHashSalted(t *testing.T, data, salt []byte) []byte {
+	t.Helper()
+	h, err := HashSalted(data, salt)
+	if err != nil {
+		t.Fatalf("HashSalted failed: %v", err)
+	}
+	return h
+}
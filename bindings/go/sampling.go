@@ -0,0 +1,82 @@
+package tachyon
+
+import "encoding/binary"
+
+// ============================================================================
+// DETERMINISTIC SAMPLING
+// ============================================================================
+
+// unitInterval maps a digest to a float64 in [0, 1).
+//
+// Uses the top 53 bits of the first 8 bytes (little-endian), so every
+// representable value is equally likely.
+func unitInterval(d []byte) float64 {
+	return float64(binary.LittleEndian.Uint64(d[:8])>>11) / (1 << 53)
+}
+
+// ShedDecision reports whether the request identified by requestKey should be
+// shed when shedding the given fraction of traffic.
+//
+// The decision is a pure function of (requestKey, shedFraction, salt): retries
+// of the same request always get the same answer, and raising shedFraction
+// only ever adds requests to the shed set. Change salt to reshuffle which
+// requests are affected.
+func ShedDecision(requestKey []byte, shedFraction float64, salt uint64) (bool, error) {
+	if shedFraction <= 0 {
+		return false, nil
+	}
+	if shedFraction >= 1 {
+		return true, nil
+	}
+
+	hash, err := HashSeeded(requestKey, salt)
+	if err != nil {
+		return false, err
+	}
+	return unitInterval(hash) < shedFraction, nil
+}
+
+// ============================================================================
+// FEATURE ROLLOUTS
+// ============================================================================
+
+// RolloutBucket returns the stable bucket in [0, 100) of unitKey for flagName.
+//
+// The bucketing is specified so other languages can reproduce it exactly:
+//
+//	input  = flagName || 0x00 || unitKey
+//	hash   = HashSeeded(input, salt)              (Rust: tachyon::hash_seeded)
+//	bucket = (LE64(hash[0:8]) >> 11) / 2^53 * 100
+//
+// Including flagName keeps the rollouts of different flags independent.
+func RolloutBucket(unitKey []byte, flagName string, salt uint64) (float64, error) {
+	input := make([]byte, 0, len(flagName)+1+len(unitKey))
+	input = append(input, flagName...)
+	input = append(input, 0)
+	input = append(input, unitKey...)
+
+	hash, err := HashSeeded(input, salt)
+	if err != nil {
+		return 0, err
+	}
+	return unitInterval(hash) * 100, nil
+}
+
+// InRollout reports whether unitKey (a user, tenant, device, ...) falls into
+// the first percent (0 to 100) of the rollout of flagName.
+//
+// Units only ever enter a rollout as percent grows, and a unit's membership
+// is identical in every service sharing the flag name and salt.
+func InRollout(unitKey []byte, flagName string, percent float64, salt uint64) (bool, error) {
+	if percent <= 0 {
+		return false, nil
+	}
+	if percent >= 100 {
+		return true, nil
+	}
+	bucket, err := RolloutBucket(unitKey, flagName, salt)
+	if err != nil {
+		return false, err
+	}
+	return bucket < percent, nil
+}
@@ -0,0 +1,102 @@
+package tachyon
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShedDecision(t *testing.T) {
+	key := []byte("GET /api/orders?id=42")
+
+	// Stable across retries
+	first, err := ShedDecision(key, 0.5, 7)
+	if err != nil {
+		t.Fatalf("ShedDecision failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, _ := ShedDecision(key, 0.5, 7)
+		if again != first {
+			t.Fatal("Same key should always get the same decision")
+		}
+	}
+
+	// Bounds
+	if shed, _ := ShedDecision(key, 0, 7); shed {
+		t.Error("Fraction 0 should never shed")
+	}
+	if shed, _ := ShedDecision(key, 1, 7); !shed {
+		t.Error("Fraction 1 should always shed")
+	}
+}
+
+func TestShedDecisionFraction(t *testing.T) {
+	const n = 10000
+	shed := 0
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("request-%d", i))
+		low, _ := ShedDecision(key, 0.1, 1)
+		high, _ := ShedDecision(key, 0.3, 1)
+		if low && !high {
+			t.Fatal("Raising the fraction should only add requests to the shed set")
+		}
+		if high {
+			shed++
+		}
+	}
+
+	if rate := float64(shed) / n; rate < 0.27 || rate > 0.33 {
+		t.Errorf("Shed rate %.3f, want ~0.3", rate)
+	}
+}
+
+func TestRolloutBucketStable(t *testing.T) {
+	// Pinned value: other implementations must reproduce it bit for bit
+	bucket, err := RolloutBucket([]byte("user-1"), "new-checkout", 0)
+	if err != nil {
+		t.Fatalf("RolloutBucket failed: %v", err)
+	}
+	if bucket != 73.265674269113873 {
+		t.Errorf("RolloutBucket = %.17g, want 73.265674269113873", bucket)
+	}
+}
+
+func TestInRollout(t *testing.T) {
+	const n = 10000
+	in10, in50, otherFlag := 0, 0, 0
+	for i := 0; i < n; i++ {
+		user := []byte(fmt.Sprintf("user-%d", i))
+		a, err := InRollout(user, "new-checkout", 10, 0)
+		if err != nil {
+			t.Fatalf("InRollout failed: %v", err)
+		}
+		b, _ := InRollout(user, "new-checkout", 50, 0)
+		c, _ := InRollout(user, "dark-mode", 10, 0)
+		if a && !b {
+			t.Fatal("Units should stay in the rollout as percent grows")
+		}
+		if a {
+			in10++
+		}
+		if b {
+			in50++
+		}
+		if a && c {
+			otherFlag++
+		}
+	}
+
+	if in10 < 900 || in10 > 1100 || in50 < 4800 || in50 > 5200 {
+		t.Errorf("Rollout sizes %d/%d, want ~1000/5000", in10, in50)
+	}
+	// Independent flags overlap like independent 10% samples (~1%)
+	if otherFlag > 200 {
+		t.Errorf("%d units in both 10%% rollouts, flags should be independent", otherFlag)
+	}
+
+	if in, _ := InRollout([]byte("u"), "f", 0, 0); in {
+		t.Error("0% rollout should include nobody")
+	}
+	if in, _ := InRollout([]byte("u"), "f", 100, 0); !in {
+		t.Error("100% rollout should include everybody")
+	}
+}
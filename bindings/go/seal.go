@@ -0,0 +1,220 @@
+package tachyon
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// ============================================================================
+// ENCRYPT-THEN-MAC
+// ============================================================================
+
+const (
+	// SealNonceSize is the nonce size of a Sealer using the Tachyon
+	// keystream. At 24 bytes, random nonces are safe.
+	SealNonceSize = 24
+
+	// SealOverhead is the size of the tag Seal appends.
+	SealOverhead = DigestSize
+)
+
+// Contexts separating the encryption and MAC keys of a Sealer.
+const (
+	sealEncryptionContext = "tachyon seal v1 encryption"
+	sealMACContext        = "tachyon seal v1 authentication"
+)
+
+// ErrOpen is returned by Sealer.Open when the ciphertext, nonce or
+// additional data fails authentication.
+var ErrOpen = errors.New("tachyon: message authentication failed")
+
+// StreamCipher creates the cipher stream encrypting one message under a
+// 32-byte key and a nonce, e.g. AES-256-CTR:
+//
+//	func(key, nonce []byte) (cipher.Stream, error) {
+//	    block, err := aes.NewCipher(key)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return cipher.NewCTR(block, nonce), nil
+//	}
+type StreamCipher func(key, nonce []byte) (cipher.Stream, error)
+
+// Sealer is an Encrypt-then-MAC AEAD combining a stream cipher with a
+// Tachyon MAC; it implements crypto/cipher.AEAD. Rather than gluing
+// HashKeyed onto encryption, use it as:
+//
+//	aead, _ := tachyon.NewSealer(key)
+//	nonce := make([]byte, aead.NonceSize())
+//	rand.Read(nonce)
+//	sealed := aead.Seal(nil, nonce, plaintext, header)
+//	plaintext, err := aead.Open(nil, nonce, sealed, header)
+//
+// From the 32-byte key, two independent keys are derived:
+//
+//	ke = DeriveKey("tachyon seal v1 encryption", key)
+//	km = DeriveKey("tachyon seal v1 authentication", key)
+//
+// and a message is sealed as
+//
+//	ct  = plaintext XOR stream(ke, nonce)
+//	tag = HashKeyed(LE64(len(nonce)) || LE64(len(ad)) || LE64(len(ct)) || nonce || ad || ct, km)
+//	Seal(nonce, plaintext, ad) = ct || tag
+//
+// Open checks the tag in constant time before decrypting anything. With
+// NewSealer, stream(ke, nonce) is the PRF keystream of NewPRFWithNonce(ke,
+// nonce). Never seal two messages under the same key and nonce. A Sealer is
+// safe for concurrent use.
+type Sealer struct {
+	encKey    []byte
+	macKey    []byte
+	stream    StreamCipher
+	nonceSize int
+}
+
+var _ cipher.AEAD = (*Sealer)(nil)
+
+// NewSealer creates a Sealer encrypting with the Tachyon keystream, with
+// SealNonceSize-byte nonces. key must be 32 bytes.
+func NewSealer(key []byte) (*Sealer, error) {
+	return NewSealerWithStream(key, SealNonceSize, keystreamCipher)
+}
+
+// NewSealerWithStream creates a Sealer encrypting with stream and nonces of
+// nonceSize bytes, as stream expects. key must be 32 bytes.
+func NewSealerWithStream(key []byte, nonceSize int, stream StreamCipher) (*Sealer, error) {
+	if len(key) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
+	}
+	if stream == nil {
+		return nil, errors.New("tachyon: missing stream cipher")
+	}
+	if nonceSize < 0 {
+		return nil, errors.New("tachyon: invalid nonce size")
+	}
+	encKey, err := DeriveKey(sealEncryptionContext, key)
+	if err != nil {
+		return nil, err
+	}
+	macKey, err := DeriveKey(sealMACContext, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{encKey: encKey, macKey: macKey, stream: stream, nonceSize: nonceSize}, nil
+}
+
+// NonceSize returns the nonce size Seal and Open require.
+func (s *Sealer) NonceSize() int { return s.nonceSize }
+
+// Overhead returns SealOverhead.
+func (s *Sealer) Overhead() int { return SealOverhead }
+
+// Seal encrypts and authenticates plaintext and additionalData, appending
+// the ciphertext and tag to dst. Like crypto/cipher.AEAD, it panics on a
+// wrong nonce size, or if the stream cipher cannot be created. To reuse
+// plaintext's storage, use plaintext[:0] as dst.
+func (s *Sealer) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != s.nonceSize {
+		panic("tachyon: incorrect nonce length given to Sealer")
+	}
+	stream, err := s.stream(s.encKey, nonce)
+	if err != nil {
+		panic(err)
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+SealOverhead)
+	ct := out[:len(plaintext)]
+	stream.XORKeyStream(ct, plaintext)
+	tag, err := s.tag(nonce, additionalData, ct)
+	if err != nil {
+		panic(err)
+	}
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+// Open authenticates ciphertext, nonce and additionalData and, if they are
+// intact, appends the decrypted plaintext to dst. It returns ErrOpen on
+// failure. To reuse ciphertext's storage, use ciphertext[:0] as dst.
+func (s *Sealer) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != s.nonceSize || len(ciphertext) < SealOverhead {
+		return nil, ErrOpen
+	}
+	n := len(ciphertext) - SealOverhead
+	ct, tag := ciphertext[:n], ciphertext[n:]
+
+	want, err := s.tag(nonce, additionalData, ct)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(want[:], tag) != 1 {
+		return nil, ErrOpen
+	}
+
+	stream, err := s.stream(s.encKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	ret, out := sliceForAppend(dst, n)
+	stream.XORKeyStream(out, ct)
+	return ret, nil
+}
+
+// tag computes the MAC over the length-prefixed nonce, additional data and
+// ciphertext.
+func (s *Sealer) tag(nonce, ad, ct []byte) (Digest, error) {
+	input := make([]byte, 24, 24+len(nonce)+len(ad)+len(ct))
+	binary.LittleEndian.PutUint64(input[0:], uint64(len(nonce)))
+	binary.LittleEndian.PutUint64(input[8:], uint64(len(ad)))
+	binary.LittleEndian.PutUint64(input[16:], uint64(len(ct)))
+	input = append(append(append(input, nonce...), ad...), ct...)
+	return hashDigest(input, DomainMessageAuth, 0, s.macKey)
+}
+
+// sliceForAppend extends in by n bytes, returning the whole slice and the
+// new tail, as in crypto/cipher.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return head, tail
+}
+
+// ============================================================================
+// KEYSTREAM CIPHER
+// ============================================================================
+
+// keystreamCipher is the StreamCipher of NewSealer: the PRF keystream.
+func keystreamCipher(key, nonce []byte) (cipher.Stream, error) {
+	prf, err := NewPRFWithNonce(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &prfStream{prf: prf}, nil
+}
+
+// prfStream adapts a PRF to cipher.Stream.
+type prfStream struct {
+	prf *PRF
+	buf [prfBatch * 32]byte
+}
+
+func (s *prfStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("tachyon: output smaller than input")
+	}
+	for len(src) > 0 {
+		n := min(len(src), len(s.buf))
+		if _, err := s.prf.Read(s.buf[:n]); err != nil {
+			panic(err)
+		}
+		subtle.XORBytes(dst, src[:n], s.buf[:n])
+		dst, src = dst[n:], src[n:]
+	}
+}
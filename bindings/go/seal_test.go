@@ -0,0 +1,139 @@
+package tachyon
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func aesCTR(key, nonce []byte) (cipher.Stream, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(block, nonce), nil
+}
+
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	keystream, err := NewSealer(key)
+	if err != nil {
+		t.Fatalf("NewSealer failed: %v", err)
+	}
+	ctr, err := NewSealerWithStream(key, aes.BlockSize, aesCTR)
+	if err != nil {
+		t.Fatalf("NewSealerWithStream failed: %v", err)
+	}
+
+	for name, aead := range map[string]cipher.AEAD{"keystream": keystream, "aes-ctr": ctr} {
+		nonce := bytes.Repeat([]byte{1}, aead.NonceSize())
+		for _, size := range []int{0, 1, 31, 32, 33, 5000} {
+			plaintext := bytes.Repeat([]byte{0xab}, size)
+			ad := []byte("header")
+
+			sealed := aead.Seal([]byte("prefix"), nonce, plaintext, ad)
+			if !bytes.HasPrefix(sealed, []byte("prefix")) || len(sealed) != 6+size+aead.Overhead() {
+				t.Fatalf("%s: Seal should append ciphertext and tag to dst", name)
+			}
+			sealed = sealed[6:]
+			if size > 0 && bytes.Contains(sealed, plaintext) {
+				t.Errorf("%s: ciphertext should not contain the plaintext", name)
+			}
+
+			opened, err := aead.Open(nil, nonce, sealed, ad)
+			if err != nil || !bytes.Equal(opened, plaintext) {
+				t.Fatalf("%s, %d bytes: Open failed: %v", name, size, err)
+			}
+
+			// Any modification fails authentication
+			otherNonce := bytes.Repeat([]byte{2}, aead.NonceSize())
+			if _, err := aead.Open(nil, otherNonce, sealed, ad); !errors.Is(err, ErrOpen) {
+				t.Errorf("%s: wrong nonce: err = %v, want ErrOpen", name, err)
+			}
+			if _, err := aead.Open(nil, nonce, sealed, []byte("Header")); !errors.Is(err, ErrOpen) {
+				t.Errorf("%s: wrong additional data: err = %v, want ErrOpen", name, err)
+			}
+			for i := range sealed {
+				tampered := bytes.Clone(sealed)
+				tampered[i] ^= 1
+				if _, err := aead.Open(nil, nonce, tampered, ad); !errors.Is(err, ErrOpen) {
+					t.Fatalf("%s: flipped byte %d: err = %v, want ErrOpen", name, i, err)
+				}
+			}
+			if _, err := aead.Open(nil, nonce, sealed[:len(sealed)-1], ad); !errors.Is(err, ErrOpen) {
+				t.Errorf("%s: truncated ciphertext should fail", name)
+			}
+		}
+	}
+
+	// In-place sealing and opening
+	msg := []byte("in place message")
+	nonce := make([]byte, SealNonceSize)
+	buf := append(bytes.Clone(msg), make([]byte, SealOverhead)...)[:len(msg)]
+	sealed := keystream.Seal(buf[:0], nonce, buf, nil)
+	opened, err := keystream.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil || !bytes.Equal(opened, msg) {
+		t.Errorf("In-place Open = %q, %v", opened, err)
+	}
+
+	other, _ := NewSealer(bytes.Repeat([]byte{0x43}, 32))
+	if _, err := other.Open(nil, nonce, sealed, nil); !errors.Is(err, ErrOpen) {
+		t.Error("Wrong key should fail authentication")
+	}
+}
+
+func TestSealConstruction(t *testing.T) {
+	key := bytes.Repeat([]byte{9}, 32)
+	aead, _ := NewSealer(key)
+	nonce := bytes.Repeat([]byte{7}, SealNonceSize)
+	plaintext := []byte("attack at dawn")
+	ad := []byte("ad")
+	sealed := aead.Seal(nil, nonce, plaintext, ad)
+
+	// The documented recipe, from public primitives
+	ke, _ := DeriveKey("tachyon seal v1 encryption", key)
+	km, _ := DeriveKey("tachyon seal v1 authentication", key)
+	prf, _ := NewPRFWithNonce(ke, nonce)
+	ks := make([]byte, len(plaintext))
+	prf.Read(ks)
+	ct := make([]byte, len(plaintext))
+	for i := range ct {
+		ct[i] = plaintext[i] ^ ks[i]
+	}
+	input := binary.LittleEndian.AppendUint64(nil, uint64(len(nonce)))
+	input = binary.LittleEndian.AppendUint64(input, uint64(len(ad)))
+	input = binary.LittleEndian.AppendUint64(input, uint64(len(ct)))
+	input = append(append(append(input, nonce...), ad...), ct...)
+	tag, _ := HashKeyed(input, km)
+	if !bytes.Equal(sealed, append(ct, tag...)) {
+		t.Error("Seal should follow the documented construction")
+	}
+
+	// Key separation: neither key is the caller's key
+	if bytes.Equal(ke, key) || bytes.Equal(km, key) || bytes.Equal(ke, km) {
+		t.Error("Encryption and MAC keys should be derived separately")
+	}
+}
+
+func TestSealerErrors(t *testing.T) {
+	if _, err := NewSealer(make([]byte, 16)); err == nil {
+		t.Error("Short key should return error")
+	}
+	if _, err := NewSealerWithStream(make([]byte, 32), 12, nil); err == nil {
+		t.Error("Missing stream cipher should return error")
+	}
+
+	aead, _ := NewSealer(make([]byte, 32))
+	if _, err := aead.Open(nil, make([]byte, 12), make([]byte, 40), nil); !errors.Is(err, ErrOpen) {
+		t.Error("Wrong nonce size should fail Open")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Seal with a wrong nonce size should panic")
+		}
+	}()
+	aead.Seal(nil, make([]byte, 12), []byte("x"), nil)
+}
@@ -0,0 +1,127 @@
+package tachyon
+
+import (
+	"crypto/rand"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ============================================================================
+// SECRET KEYS
+// ============================================================================
+
+var errKeyClosed = errors.New("tachyon: secret key is closed")
+
+// SecretKey holds a 32-byte key outside the Go heap.
+//
+// With cgo the key lives in C memory that is locked in RAM (no swap) and
+// excluded from core dumps where the platform allows, and is wiped on Close.
+// Unlike a []byte key, it is never copied around by the garbage collector.
+// Without cgo it is an ordinary heap allocation, still wiped on Close.
+//
+// A SecretKey that is garbage collected without Close is wiped by a
+// finalizer, but Close it explicitly to bound the key's lifetime. Streaming
+// states created by NewHasher hold a copy of the key until finalized.
+type SecretKey struct {
+	mu  sync.RWMutex
+	key []byte // nil once closed
+}
+
+// NewSecretKey copies 32 bytes of key material into a SecretKey. Wipe
+// material afterwards.
+func NewSecretKey(material []byte) (*SecretKey, error) {
+	if len(material) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
+	}
+	k, err := newSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	copy(k.key, material)
+	return k, nil
+}
+
+// GenerateSecretKey creates a random SecretKey, read from crypto/rand
+// directly into protected memory.
+func GenerateSecretKey() (*SecretKey, error) {
+	k, err := newSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(k.key); err != nil {
+		k.Close()
+		return nil, err
+	}
+	return k, nil
+}
+
+func newSecretKey() (*SecretKey, error) {
+	b := secretAlloc(32)
+	if b == nil {
+		return nil, errors.New("tachyon: could not allocate secret memory")
+	}
+	k := &SecretKey{key: b}
+	runtime.SetFinalizer(k, (*SecretKey).Close)
+	return k, nil
+}
+
+// Close wipes and releases the key. It is safe to call more than once.
+func (k *SecretKey) Close() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.key != nil {
+		secretFree(k.key)
+		k.key = nil
+		runtime.SetFinalizer(k, nil)
+	}
+}
+
+// HashKeyed computes HashKeyed(data, key).
+func (k *SecretKey) HashKeyed(data []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.key == nil {
+		return nil, errKeyClosed
+	}
+	return HashKeyed(data, k.key)
+}
+
+// VerifyMAC computes VerifyMAC(data, key, expectedMAC).
+func (k *SecretKey) VerifyMAC(data, expectedMAC []byte) (bool, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.key == nil {
+		return false, errKeyClosed
+	}
+	return VerifyMAC(data, k.key, expectedMAC)
+}
+
+// DeriveKey computes DeriveKey(context, key) into a new SecretKey, so the
+// derived key never touches the Go heap either.
+func (k *SecretKey) DeriveKey(context string) (*SecretKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.key == nil {
+		return nil, errKeyClosed
+	}
+	derived, err := newSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := deriveKey(context, k.key, (*Digest)(derived.key)); err != nil {
+		derived.Close()
+		return nil, err
+	}
+	return derived, nil
+}
+
+// NewHasher creates a streaming keyed hasher, equal to NewHasherKeyed(key).
+func (k *SecretKey) NewHasher() (*Hasher, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.key == nil {
+		return nil, errKeyClosed
+	}
+	return NewHasherKeyed(k.key)
+}
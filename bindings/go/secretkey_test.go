@@ -0,0 +1,95 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSecretKey(t *testing.T) {
+	material := bytes.Repeat([]byte("s"), 32)
+	data := []byte("keep the key off the heap")
+
+	k, err := NewSecretKey(material)
+	if err != nil {
+		t.Fatalf("NewSecretKey failed: %v", err)
+	}
+	defer k.Close()
+
+	mac, err := k.HashKeyed(data)
+	if err != nil {
+		t.Fatalf("HashKeyed failed: %v", err)
+	}
+	want, _ := HashKeyed(data, material)
+	if !bytes.Equal(mac, want) {
+		t.Error("SecretKey.HashKeyed should equal HashKeyed")
+	}
+	if ok, err := k.VerifyMAC(data, mac); err != nil || !ok {
+		t.Error("SecretKey.VerifyMAC should verify its own MAC")
+	}
+
+	derived, err := k.DeriveKey("app-v1")
+	if err != nil {
+		t.Fatalf("DeriveKey failed: %v", err)
+	}
+	defer derived.Close()
+	wantKey, _ := DeriveKey("app-v1", material)
+	dmac, _ := derived.HashKeyed(data)
+	wantMAC, _ := HashKeyed(data, wantKey)
+	if !bytes.Equal(dmac, wantMAC) {
+		t.Error("SecretKey.DeriveKey should equal DeriveKey")
+	}
+
+	h, err := k.NewHasher()
+	if err != nil {
+		t.Fatalf("NewHasher failed: %v", err)
+	}
+	h.Update(data[:5])
+	h.Update(data[5:])
+	if sum, _ := h.Finalize(); !bytes.Equal(sum, want) {
+		t.Error("Keyed streaming hasher should equal HashKeyed")
+	}
+
+	k.Close()
+	k.Close()
+	if _, err := k.HashKeyed(data); err == nil {
+		t.Error("Closed key should return error")
+	}
+	if _, err := k.DeriveKey("x"); err == nil {
+		t.Error("Closed key should return error")
+	}
+	if _, err := NewSecretKey([]byte("short")); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+}
+
+func TestGenerateSecretKey(t *testing.T) {
+	a, err := GenerateSecretKey()
+	if err != nil {
+		t.Fatalf("GenerateSecretKey failed: %v", err)
+	}
+	defer a.Close()
+	b, _ := GenerateSecretKey()
+	defer b.Close()
+
+	ma, _ := a.HashKeyed([]byte("x"))
+	mb, _ := b.HashKeyed([]byte("x"))
+	if bytes.Equal(ma, mb) {
+		t.Error("Generated keys should differ")
+	}
+}
+
+func TestNewHasherKeyed(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	h, err := NewHasherKeyed(key)
+	if err != nil {
+		t.Fatalf("NewHasherKeyed failed: %v", err)
+	}
+	h.Update([]byte("message"))
+	got, _ := h.Finalize()
+	if want, _ := HashKeyed([]byte("message"), key); !bytes.Equal(got, want) {
+		t.Error("NewHasherKeyed should equal HashKeyed")
+	}
+	if _, err := NewHasherKeyed([]byte("short")); err == nil {
+		t.Error("Wrong key size should return error")
+	}
+}
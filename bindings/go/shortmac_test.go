@@ -0,0 +1,96 @@
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpenShort(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	packet := []byte("telemetry frame")
+
+	tag, err := SealShort(key, 7, packet)
+	if err != nil {
+		t.Fatalf("SealShort failed: %v", err)
+	}
+	if err := OpenShort(key, 7, packet, tag, nil); err != nil {
+		t.Fatalf("OpenShort failed: %v", err)
+	}
+
+	// The tag is bound to the sequence number, the packet and the key
+	if err := OpenShort(key, 8, packet, tag, nil); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("OpenShort with other seq = %v, want ErrInvalidTag", err)
+	}
+	if err := OpenShort(key, 7, []byte("telemetry frame!"), tag, nil); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("OpenShort with other packet = %v, want ErrInvalidTag", err)
+	}
+	otherKey := bytes.Repeat([]byte{0x43}, 32)
+	if err := OpenShort(otherKey, 7, packet, tag, nil); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("OpenShort with other key = %v, want ErrInvalidTag", err)
+	}
+
+	// Not a truncation of the plain MAC under the same key
+	full, _ := HashKeyed(append([]byte{7, 0, 0, 0, 0, 0, 0, 0}, packet...), key)
+	if bytes.Equal(full[:ShortTagSize], tag[:]) {
+		t.Error("Short tags should use a derived key")
+	}
+
+	// Empty packets are fine: the sequence number is always hashed
+	if _, err := SealShort(key, 0, nil); err != nil {
+		t.Errorf("SealShort of empty packet failed: %v", err)
+	}
+}
+
+func TestOpenShortReplayWindow(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	window := NewReplayWindow(64)
+	open := func(seq uint64) error {
+		tag, _ := SealShort(key, seq, []byte("p"))
+		return OpenShort(key, seq, []byte("p"), tag, window)
+	}
+
+	for _, seq := range []uint64{1, 3, 2, 100} {
+		if err := open(seq); err != nil {
+			t.Fatalf("OpenShort(seq %d) failed: %v", seq, err)
+		}
+	}
+	if err := open(3); !errors.Is(err, ErrReplay) {
+		t.Errorf("Duplicate seq = %v, want ErrReplay", err)
+	}
+	if err := open(20); !errors.Is(err, ErrReplay) {
+		t.Errorf("Seq older than window = %v, want ErrReplay", err)
+	}
+	if err := open(99); err != nil {
+		t.Errorf("Reordered seq inside window failed: %v", err)
+	}
+
+	// A forged packet must not consume its sequence number
+	if err := OpenShort(key, 101, []byte("p"), ShortTag{}, window); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("Forged tag = %v, want ErrInvalidTag", err)
+	}
+	if err := open(101); err != nil {
+		t.Errorf("Genuine packet after forgery failed: %v", err)
+	}
+}
+
+func TestReplayWindowSlide(t *testing.T) {
+	w := NewReplayWindow(100)
+	if w.Size() != 128 {
+		t.Errorf("Size() = %d, want 128", w.Size())
+	}
+
+	if !w.Accept(0) || w.Accept(0) {
+		t.Error("Seq 0 should be accepted exactly once")
+	}
+	// Slots reused after sliding must not remember old numbers
+	if !w.Accept(128) || !w.Accept(129) {
+		t.Error("Sliding forward should accept new numbers")
+	}
+	if w.Accept(1) {
+		t.Error("Seq 1 is out of the window after seq 129")
+	}
+	if !w.Accept(1000) || !w.Check(999) || w.Check(1000) {
+		t.Error("Large jump should reset the window")
+	}
+}
@@ -0,0 +1,99 @@
+//go:build tachyon_slowmode
+
+package tachyon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// SLOW MODE
+// ============================================================================
+
+// Built with -tags tachyon_slowmode, the streaming and parallel paths sleep
+// at every chunk boundary. This widens race windows so concurrency bugs in
+// BatchHasher, VerifierPipeline and concurrent Hasher use reproduce under
+// -race:
+//
+//	TACHYON_SLOWMODE="max=2ms,seed=42" go test -race -tags tachyon_slowmode ./...
+//
+// The n-th delay (from 0) at a site is deterministic:
+//
+//	delay = LE64(HashSeeded(site || LE64(n), seed)[0:8]) mod (max + 1ns)
+//
+// max defaults to 1ms and seed to 0. Never ship a build with this tag.
+
+type slowConfig struct {
+	max  time.Duration
+	seed uint64
+}
+
+var (
+	slow         = mustParseSlowMode(os.Getenv("TACHYON_SLOWMODE"))
+	slowCounters sync.Map // site → *atomic.Uint64
+)
+
+func mustParseSlowMode(s string) slowConfig {
+	cfg, err := parseSlowMode(s)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// parseSlowMode parses a comma-separated list of max=<duration> and
+// seed=<uint64>.
+func parseSlowMode(s string) (slowConfig, error) {
+	cfg := slowConfig{max: time.Millisecond}
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(field, "=")
+		var err error
+		switch name {
+		case "max":
+			cfg.max, err = time.ParseDuration(value)
+			if err == nil && cfg.max < 0 {
+				err = fmt.Errorf("negative duration %s", value)
+			}
+		case "seed":
+			cfg.seed, err = strconv.ParseUint(value, 0, 64)
+		default:
+			err = fmt.Errorf("unknown setting %q", name)
+		}
+		if err != nil {
+			return slowConfig{}, fmt.Errorf("tachyon: TACHYON_SLOWMODE: %v", err)
+		}
+	}
+	return cfg, nil
+}
+
+// slowDelay returns the n-th delay at site.
+func (c slowConfig) slowDelay(site string, n uint64) time.Duration {
+	if c.max == 0 {
+		return 0
+	}
+	var buf [64]byte
+	input := binary.LittleEndian.AppendUint64(append(buf[:0], site...), n)
+	var hash Digest
+	goHashFull(input, 0, c.seed, nil, &hash) // Pure Go: input stays on the stack
+	return time.Duration(binary.LittleEndian.Uint64(hash[:8]) % uint64(c.max+1))
+}
+
+// chunkBoundary marks a chunk boundary in a streaming or parallel path.
+func chunkBoundary(site string) {
+	v, ok := slowCounters.Load(site)
+	if !ok {
+		v, _ = slowCounters.LoadOrStore(site, new(atomic.Uint64))
+	}
+	n := v.(*atomic.Uint64).Add(1) - 1
+	time.Sleep(slow.slowDelay(site, n))
+}
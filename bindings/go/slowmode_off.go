@@ -0,0 +1,7 @@
+//go:build !tachyon_slowmode
+
+package tachyon
+
+// chunkBoundary marks a chunk boundary in a streaming or parallel path. It
+// only does something in tachyon_slowmode builds; see slowmode.go.
+func chunkBoundary(site string) {}
@@ -0,0 +1,48 @@
+//go:build tachyon_slowmode
+
+package tachyon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSlowMode(t *testing.T) {
+	cfg, err := parseSlowMode("")
+	if err != nil || cfg.max != time.Millisecond || cfg.seed != 0 {
+		t.Errorf("parseSlowMode(\"\") = %+v, %v", cfg, err)
+	}
+	cfg, err = parseSlowMode("max=250us, seed=0x2a")
+	if err != nil || cfg.max != 250*time.Microsecond || cfg.seed != 42 {
+		t.Errorf("parseSlowMode = %+v, %v", cfg, err)
+	}
+	for _, bad := range []string{"max=fast", "max=-1ms", "seed=x", "speed=1"} {
+		if _, err := parseSlowMode(bad); err == nil {
+			t.Errorf("parseSlowMode(%q) should return error", bad)
+		}
+	}
+}
+
+func TestSlowDelayDeterministic(t *testing.T) {
+	a := slowConfig{max: time.Millisecond, seed: 1}
+	b := slowConfig{max: time.Millisecond, seed: 2}
+	differs := false
+	for n := uint64(0); n < 32; n++ {
+		d := a.slowDelay("update", n)
+		if d < 0 || d > a.max {
+			t.Fatalf("delay %v out of [0, %v]", d, a.max)
+		}
+		if d != a.slowDelay("update", n) {
+			t.Fatal("Delays should be deterministic")
+		}
+		if d != b.slowDelay("update", n) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("Different seeds should give different delays")
+	}
+	if (slowConfig{}).slowDelay("update", 0) != 0 {
+		t.Error("max=0 should disable delays")
+	}
+}
@@ -0,0 +1,251 @@
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// ADAPTIVE HASHING
+// ============================================================================
+
+// nativeChunkSize mirrors the native Merkle chunk size (256 KB). Inputs of at
+// least two chunks are hashed as a tree whose leaves are independent, so they
+// can be hashed in parallel.
+const nativeChunkSize = 256 * 1024
+
+// SmartPlan holds the thresholds SmartHash uses to pick a strategy.
+type SmartPlan struct {
+	// OneShotMax is the largest size hint that is buffered and hashed with a
+	// single one-shot call.
+	OneShotMax int64
+
+	// ParallelMin is the smallest size hint that is read in
+	// ParallelBuffer-sized blocks whose Merkle leaves are hashed on up to
+	// GOMAXPROCS goroutines.
+	ParallelMin int64
+
+	// StreamBuffer is the update size for streaming unknown or medium inputs.
+	StreamBuffer int
+
+	// ParallelBuffer is the block size for huge inputs, rounded up to a
+	// multiple of 256 KB. Each block is split between the goroutines.
+	ParallelBuffer int
+}
+
+// DefaultSmartPlan returns the built-in thresholds.
+func DefaultSmartPlan() SmartPlan {
+	return SmartPlan{
+		OneShotMax:     64 * 1024,
+		ParallelMin:    8 * nativeChunkSize,
+		StreamBuffer:   nativeChunkSize,
+		ParallelBuffer: 32 * nativeChunkSize,
+	}
+}
+
+var (
+	smartMu   sync.RWMutex
+	smartPlan = DefaultSmartPlan()
+)
+
+// SetSmartPlan replaces the thresholds used by SmartHash, e.g. with the
+// result of CalibrateSmartPlan.
+func SetSmartPlan(p SmartPlan) error {
+	if p.OneShotMax < 0 || p.ParallelMin < p.OneShotMax || p.StreamBuffer <= 0 || p.ParallelBuffer <= 0 {
+		return errors.New("tachyon: invalid smart plan")
+	}
+	smartMu.Lock()
+	smartPlan = p
+	smartMu.Unlock()
+	return nil
+}
+
+// SmartHash hashes everything read from r, choosing the fastest strategy.
+//
+// sizeHint is the expected input length, or negative if unknown:
+//   - up to OneShotMax: the input is buffered and hashed in one call
+//   - unknown or medium: the input is streamed in StreamBuffer-sized updates
+//   - ParallelMin and above: the input is read in ParallelBuffer-sized
+//     blocks and the chunks of each block are hashed in parallel
+//
+// All strategies produce the same digest as Hash; a wrong hint only costs
+// performance. If more data than a small hint arrives, SmartHash falls back
+// to streaming.
+func SmartHash(r io.Reader, sizeHint int64) ([]byte, error) {
+	smartMu.RLock()
+	plan := smartPlan
+	smartMu.RUnlock()
+	return smartHash(r, sizeHint, plan)
+}
+
+func smartHash(r io.Reader, sizeHint int64, plan SmartPlan) ([]byte, error) {
+	switch {
+	case sizeHint >= 0 && sizeHint <= plan.OneShotMax:
+		// Read one byte past the hint to detect undersized hints
+		buf := make([]byte, sizeHint+1)
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return Hash(buf[:n])
+		}
+		if err != nil {
+			return nil, err
+		}
+		return streamHash(io.MultiReader(bytes.NewReader(buf), r), plan.StreamBuffer)
+	case sizeHint >= plan.ParallelMin:
+		return parallelHash(r, plan.ParallelBuffer, runtime.GOMAXPROCS(0))
+	default:
+		return streamHash(r, plan.StreamBuffer)
+	}
+}
+
+// streamHash feeds r to a streaming hasher in bufSize-sized updates.
+func streamHash(r io.Reader, bufSize int) ([]byte, error) {
+	hasher := NewHasher()
+	if hasher == nil {
+		return nil, errors.New("tachyon: could not create hasher")
+	}
+	defer hasher.Close()
+
+	buf := make([]byte, bufSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if uerr := hasher.Update(buf[:n]); uerr != nil {
+				return nil, uerr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return hasher.Finalize()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parallelHash reads r in blocks of bufSize bytes, hashes the whole chunks of
+// each block as Merkle leaves on up to workers goroutines, and assembles the
+// tree in order with the rules of the engine.
+func parallelHash(r io.Reader, bufSize, workers int) ([]byte, error) {
+	chunks := max((bufSize+nativeChunkSize-1)/nativeChunkSize, 1)
+	buf := make([]byte, chunks*nativeChunkSize)
+	leaves := make([]Digest, chunks)
+	tree := goState{kernel: treeKernel}
+
+	for {
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		if tree.totalLen == 0 && n < nativeChunkSize {
+			return Hash(buf[:n]) // No tree below one chunk
+		}
+
+		full := n / nativeChunkSize
+		if err := hashLeavesParallel(buf[:full*nativeChunkSize], leaves[:full], workers); err != nil {
+			return nil, err
+		}
+		for _, leaf := range leaves[:full] {
+			tree.push(leaf)
+		}
+		tree.totalLen += uint64(n)
+		if n == len(buf) {
+			continue
+		}
+
+		// End of input: the remainder is the last leaf
+		if rest := buf[full*nativeChunkSize : n]; len(rest) > 0 {
+			var leaf Digest
+			tree.run(rest, goDomainLeaf, &leaf)
+			tree.push(leaf)
+		}
+		var out Digest
+		tree.sum(&out)
+		if tree.err != nil {
+			return nil, tree.err
+		}
+		return out[:], nil
+	}
+}
+
+// hashLeavesParallel splits the chunks of data between up to workers
+// goroutines, writing leaf i to out[i].
+func hashLeavesParallel(data []byte, out []Digest, workers int) error {
+	if workers <= 1 || len(out) <= 1 {
+		return hashLeaves(data, 0, nil, out)
+	}
+	per := (len(out) + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w, lo := 0, 0; lo < len(out); w, lo = w+1, lo+per {
+		hi := min(lo+per, len(out))
+		wg.Add(1)
+		go func(w, lo, hi int) {
+			defer wg.Done()
+			errs[w] = hashLeaves(data[lo*nativeChunkSize:hi*nativeChunkSize], 0, nil, out[lo:hi])
+		}(w, lo, hi)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ============================================================================
+// CALIBRATION
+// ============================================================================
+
+// CalibrateSmartPlan measures one-shot versus streaming hashing on this host
+// and returns a plan with OneShotMax set to the largest size (1 KB to 1 MB)
+// at which buffering plus one-shot hashing was still faster.
+//
+// Calibration takes a few tens of milliseconds. The result can be passed to
+// SetSmartPlan.
+func CalibrateSmartPlan() (SmartPlan, error) {
+	plan := DefaultSmartPlan()
+	data := make([]byte, 1024*1024)
+
+	plan.OneShotMax = 0
+	for size := 1024; size <= len(data); size *= 2 {
+		input := data[:size]
+		rounds := max(1, (4*1024*1024)/size)
+
+		oneShot, err := timeRounds(rounds, func() error {
+			_, err := smartHash(bytes.NewReader(input), int64(size), SmartPlan{
+				OneShotMax: int64(size), ParallelMin: int64(size), StreamBuffer: plan.StreamBuffer, ParallelBuffer: plan.ParallelBuffer,
+			})
+			return err
+		})
+		if err != nil {
+			return plan, err
+		}
+		streamed, err := timeRounds(rounds, func() error {
+			_, err := streamHash(bytes.NewReader(input), plan.StreamBuffer)
+			return err
+		})
+		if err != nil {
+			return plan, err
+		}
+
+		if oneShot > streamed {
+			break
+		}
+		plan.OneShotMax = int64(size)
+	}
+
+	plan.ParallelMin = max(plan.ParallelMin, plan.OneShotMax)
+	return plan, nil
+}
+
+// timeRounds runs fn rounds times and returns the elapsed time.
+func timeRounds(rounds int, fn func() error) (time.Duration, error) {
+	start := time.Now()
+	for i := 0; i < rounds; i++ {
+		if err := fn(); err != nil {
+			return 0, err
+		}
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,82 @@
+package tachyon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSmartHashStrategiesAgree(t *testing.T) {
+	sizes := []int{0, 100, 64 * 1024, 300 * 1024, 3 * 1024 * 1024}
+	for _, size := range sizes {
+		data := bytes.Repeat([]byte{0x5a}, size)
+		want, err := Hash(data)
+		if err != nil {
+			t.Fatalf("Hash failed: %v", err)
+		}
+
+		// Exact, unknown, too small and too large hints
+		hints := []int64{int64(size), -1, 10, 1 << 40}
+		for _, hint := range hints {
+			got, err := SmartHash(bytes.NewReader(data), hint)
+			if err != nil {
+				t.Fatalf("SmartHash(size %d, hint %d) failed: %v", size, hint, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("SmartHash(size %d, hint %d) differs from Hash", size, hint)
+			}
+		}
+	}
+}
+
+func TestParallelHashMatchesHash(t *testing.T) {
+	const cs = nativeChunkSize
+	sizes := []int{0, 1, cs - 1, cs, cs + 1, 2 * cs, 4 * cs, 5*cs + 17}
+	blocks := []int{2 * cs, 3*cs - 5, 1} // Whole chunks, rounded up, minimum
+	for _, impl := range []Implementation{ImplAuto, ImplPureGo} {
+		if err := SetImplementation(impl); err != nil {
+			t.Fatalf("SetImplementation(%s) failed: %v", impl, err)
+		}
+		for _, size := range sizes {
+			data := make([]byte, size)
+			for i := range data {
+				data[i] = byte(i * 7)
+			}
+			want, err := Hash(data)
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+			for _, block := range blocks {
+				for _, workers := range []int{1, 3} {
+					got, err := parallelHash(bytes.NewReader(data), block, workers)
+					if err != nil {
+						t.Fatalf("parallelHash(%s, size %d, block %d) failed: %v", impl, size, block, err)
+					}
+					if !bytes.Equal(got, want) {
+						t.Errorf("parallelHash(%s, size %d, block %d, %d workers) differs from Hash",
+							impl, size, block, workers)
+					}
+				}
+			}
+		}
+	}
+	SetImplementation(ImplAuto)
+}
+
+func TestSmartPlan(t *testing.T) {
+	plan, err := CalibrateSmartPlan()
+	if err != nil {
+		t.Fatalf("CalibrateSmartPlan failed: %v", err)
+	}
+	if plan.OneShotMax > 1024*1024 || plan.ParallelMin < plan.OneShotMax {
+		t.Errorf("Calibrated plan out of range: %+v", plan)
+	}
+
+	if err := SetSmartPlan(plan); err != nil {
+		t.Fatalf("SetSmartPlan failed: %v", err)
+	}
+	defer SetSmartPlan(DefaultSmartPlan())
+
+	if err := SetSmartPlan(SmartPlan{OneShotMax: 10, ParallelMin: 5, StreamBuffer: 1, ParallelBuffer: 1}); err == nil {
+		t.Error("Inconsistent plan should return error")
+	}
+}
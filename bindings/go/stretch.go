@@ -0,0 +1,118 @@
+package tachyon
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ============================================================================
+// KEY STRETCHING
+// ============================================================================
+
+// stretchBlockSize is the size of one block of StretchKey's memory.
+const stretchBlockSize = 1024
+
+// stretchLabel separates StretchKey from all other hashes.
+const stretchLabel = "tachyon stretch v1"
+
+// StretchParams sets the cost of StretchKey.
+type StretchParams struct {
+	// Memory is the memory filled and mixed, in bytes. It is rounded down to
+	// a multiple of 1 KB and must be at least 8 KB.
+	Memory int
+
+	// Passes is the number of mixing passes over the memory (at least 1).
+	Passes int
+}
+
+// DefaultStretchParams returns 64 MB and 3 passes, around a second of work
+// on a desktop CPU.
+func DefaultStretchParams() StretchParams {
+	return StretchParams{Memory: 64 << 20, Passes: 3}
+}
+
+// StretchKey derives a 32-byte key from a password with a memory-hard
+// function, for settings such as disk encryption where guessing passwords
+// with a fast hash would be cheap.
+//
+// It is a balloon-style construction with data-independent memory access,
+// so its timing does not depend on the password. With n = Memory/1024
+// blocks and H(x) = the 1024-byte expansion of
+// Hash(x, WithDomain(DomainKeyDerivation)) (block j = HashKeyed(LE64(j), root)):
+//
+//	B[0] = H("tachyon stretch v1" || LE32(n) || LE32(passes) ||
+//	         LE32(len(password)) || password || LE32(len(salt)) || salt)
+//	B[i] = H(B[i-1])                                           for 0 < i < n
+//	for each pass p and block i in order:
+//	    j0, j1, j2 = the first three LE64 words of the expansion of
+//	                 Hash(LE32(p) || LE32(i) || salt), each mod n
+//	    B[i] = H(B[i-1 mod n] || B[i] || B[j0] || B[j1] || B[j2])
+//	key  = Hash(B[n-1], WithDomain(DomainKeyDerivation))
+//
+// The salt must be at least 8 bytes and should be random per password.
+func StretchKey(password, salt []byte, params StretchParams) ([]byte, error) {
+	n := params.Memory / stretchBlockSize
+	if n < 8 {
+		return nil, errors.New("tachyon: stretch memory must be at least 8 KB")
+	}
+	if params.Passes < 1 {
+		return nil, errors.New("tachyon: stretch needs at least one pass")
+	}
+	if len(salt) < 8 {
+		return nil, errors.New("tachyon: salt must be at least 8 bytes")
+	}
+
+	mem := make([]byte, n*stretchBlockSize)
+	block := func(i int) []byte { return mem[i*stretchBlockSize : (i+1)*stretchBlockSize] }
+
+	input := make([]byte, 0, len(stretchLabel)+16+len(password)+len(salt))
+	input = append(input, stretchLabel...)
+	input = binary.LittleEndian.AppendUint32(input, uint32(n))
+	input = binary.LittleEndian.AppendUint32(input, uint32(params.Passes))
+	input = binary.LittleEndian.AppendUint32(input, uint32(len(password)))
+	input = append(input, password...)
+	input = binary.LittleEndian.AppendUint32(input, uint32(len(salt)))
+	input = append(input, salt...)
+	defer clear(input)
+	defer clear(mem)
+
+	if err := hashSizedInto(input, DomainKeyDerivation, 0, nil, block(0)); err != nil {
+		return nil, err
+	}
+	for i := 1; i < n; i++ {
+		if err := hashSizedInto(block(i-1), DomainKeyDerivation, 0, nil, block(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	mix := make([]byte, 5*stretchBlockSize)
+	index := make([]byte, 0, 8+len(salt))
+	var words [24]byte
+	for p := 0; p < params.Passes; p++ {
+		for i := 0; i < n; i++ {
+			index = binary.LittleEndian.AppendUint32(index[:0], uint32(p))
+			index = binary.LittleEndian.AppendUint32(index, uint32(i))
+			index = append(index, salt...)
+			if err := hashExpand(index, 0, words[:]); err != nil {
+				return nil, err
+			}
+
+			copy(mix, block((i+n-1)%n))
+			copy(mix[stretchBlockSize:], block(i))
+			for k := 0; k < 3; k++ {
+				j := binary.LittleEndian.Uint64(words[8*k:]) % uint64(n)
+				copy(mix[(2+k)*stretchBlockSize:], block(int(j)))
+			}
+			if err := hashSizedInto(mix, DomainKeyDerivation, 0, nil, block(i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	clear(mix)
+
+	var key Digest
+	if err := hashFull(block(n-1), DomainKeyDerivation, 0, nil, &key); err != nil {
+		return nil, err
+	}
+	return key[:], nil
+}
@@ -0,0 +1,55 @@
+package tachyon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestStretchKey(t *testing.T) {
+	params := StretchParams{Memory: 64 << 10, Passes: 2}
+	salt := []byte("0123456789abcdef")
+
+	key, err := StretchKey([]byte("password"), salt, params)
+	if err != nil {
+		t.Fatalf("StretchKey failed: %v", err)
+	}
+	// Pinned so the construction cannot change silently
+	if got := hex.EncodeToString(key); got != "168b11f23e13f4cc8e908bde3c80edbe9a64c5a3d1acd5c165245803bfc3a200" {
+		t.Errorf("StretchKey = %s", got)
+	}
+
+	variants := []struct {
+		name     string
+		password string
+		salt     []byte
+		params   StretchParams
+	}{
+		{"password", "passwore", salt, params},
+		{"salt", "password", []byte("0123456789abcdeF"), params},
+		{"memory", "password", salt, StretchParams{Memory: 65 << 10, Passes: 2}},
+		{"passes", "password", salt, StretchParams{Memory: 64 << 10, Passes: 3}},
+	}
+	for _, v := range variants {
+		other, err := StretchKey([]byte(v.password), v.salt, v.params)
+		if err != nil {
+			t.Fatalf("StretchKey failed: %v", err)
+		}
+		if bytes.Equal(other, key) {
+			t.Errorf("Different %s should give a different key", v.name)
+		}
+	}
+}
+
+func TestStretchKeyErrors(t *testing.T) {
+	salt := []byte("saltsalt")
+	if _, err := StretchKey([]byte("pw"), salt, StretchParams{Memory: 4 << 10, Passes: 1}); err == nil {
+		t.Error("Too little memory should return error")
+	}
+	if _, err := StretchKey([]byte("pw"), salt, StretchParams{Memory: 8 << 10}); err == nil {
+		t.Error("Zero passes should return error")
+	}
+	if _, err := StretchKey([]byte("pw"), []byte("short"), StretchParams{Memory: 8 << 10, Passes: 1}); err == nil {
+		t.Error("Short salt should return error")
+	}
+}
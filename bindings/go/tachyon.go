@@ -17,16 +17,11 @@
 //	result := hasher.Finalize()
 package tachyon
 
-/*
-#cgo LDFLAGS: -L../../target/release -ltachyon
-#include "../c/tachyon.h"
-#include <stdlib.h>
-*/
-import "C"
 import (
+	"crypto/subtle"
 	"errors"
+	"hash"
 	"sync"
-	"unsafe"
 )
 
 // ============================================================================
@@ -48,50 +43,44 @@ const (
 
 // Hash computes the Tachyon hash of the input data.
 //
-// Returns a 32-byte hash or an error if the operation fails.
-func Hash(data []byte) ([]byte, error) {
-	hash := make([]byte, 32)
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&hash[0]))
-
-	var inputPtr *C.uint8_t
-	if len(data) > 0 {
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	} else {
-		var dummy byte
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&dummy))
+// Options (WithSeed, WithKey, WithDomain, WithOutputSize,
+// WithPersonalization) select any combination of the hashing modes.
+//
+// Returns a 32-byte hash (or the WithOutputSize size) or an error if the
+// operation fails.
+//
+// Hash and the other one-shot functions are safe for concurrent use and
+// scale with cores: each call hashes on its own thread with stack-local
+// state, sharing no locks or buffers with other calls.
+func Hash(data []byte, opts ...Option) ([]byte, error) {
+	if len(opts) > 0 {
+		return hashOptions(data, opts)
 	}
-	inputLen := C.size_t(len(data))
 
-	res := C.tachyon_hash(inputPtr, inputLen, outputPtr)
-	if res != 0 {
-		return nil, errors.New("tachyon: internal error")
+	var hash Digest
+	if err := hashFull(data, 0, 0, nil, &hash); err != nil {
+		return nil, err
 	}
+	return hash[:], nil
+}
 
-	return hash, nil
+// Sum computes the 32-byte Tachyon hash of data as a Digest.
+//
+// It equals Hash(data) but does not allocate, for callers hashing at high
+// frequency.
+func Sum(data []byte) (Digest, error) {
+	return hashDigest(data, 0, 0, nil)
 }
 
 // HashSeeded computes the Tachyon hash of the input data with a seed.
 //
 // Returns a 32-byte hash or an error if the operation fails.
 func HashSeeded(data []byte, seed uint64) ([]byte, error) {
-	hash := make([]byte, 32)
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&hash[0]))
-
-	var inputPtr *C.uint8_t
-	if len(data) > 0 {
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	} else {
-		var dummy byte
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&dummy))
-	}
-	inputLen := C.size_t(len(data))
-
-	res := C.tachyon_hash_seeded(inputPtr, inputLen, C.uint64_t(seed), outputPtr)
-	if res != 0 {
-		return nil, errors.New("tachyon: internal error")
+	var hash Digest
+	if err := hashFull(data, 0, seed, nil, &hash); err != nil {
+		return nil, err
 	}
-
-	return hash, nil
+	return hash[:], nil
 }
 
 // Verify checks if data matches the expected hash in constant time.
@@ -102,26 +91,11 @@ func Verify(data []byte, expectedHash []byte) (bool, error) {
 	if len(expectedHash) != 32 {
 		return false, errors.New("tachyon: expected hash must be 32 bytes")
 	}
-	var inputPtr *C.uint8_t
-	if len(data) > 0 {
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	} else {
-		var dummy byte
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&dummy))
-	}
-	inputLen := C.size_t(len(data))
-	hashPtr := (*C.uint8_t)(unsafe.Pointer(&expectedHash[0]))
-
-	res := C.tachyon_verify(inputPtr, inputLen, hashPtr)
-
-	switch res {
-	case 1:
-		return true, nil
-	case 0:
-		return false, nil
-	default:
-		return false, errors.New("tachyon: internal error")
+	var hash Digest
+	if err := hashFull(data, 0, 0, nil, &hash); err != nil {
+		return false, err
 	}
+	return subtle.ConstantTimeCompare(hash[:], expectedHash) == 1, nil
 }
 
 // HashWithDomain computes hash with domain separation.
@@ -129,21 +103,11 @@ func HashWithDomain(data []byte, domain uint8) ([]byte, error) {
 	if domain > 5 {
 		return nil, errors.New("tachyon: domain must be 0-5")
 	}
-	hash := make([]byte, 32)
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&hash[0]))
-
-	var inputPtr *C.uint8_t
-	if len(data) > 0 {
-		inputPtr = (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	}
-	inputLen := C.size_t(len(data))
-
-	res := C.tachyon_hash_with_domain(inputPtr, inputLen, C.uint64_t(domain), outputPtr)
-	if res != 0 {
-		return nil, errors.New("tachyon: internal error")
+	var hash Digest
+	if err := hashFull(data, uint64(domain), 0, nil, &hash); err != nil {
+		return nil, err
 	}
-
-	return hash, nil
+	return hash[:], nil
 }
 
 // HashKeyed computes keyed hash (MAC).
@@ -155,18 +119,11 @@ func HashKeyed(data []byte, key []byte) ([]byte, error) {
 		return nil, errors.New("tachyon: input cannot be empty")
 	}
 
-	mac := make([]byte, 32)
-	inputPtr := (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	inputLen := C.size_t(len(data))
-	keyPtr := (*C.uint8_t)(unsafe.Pointer(&key[0]))
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&mac[0]))
-
-	res := C.tachyon_hash_keyed(inputPtr, inputLen, keyPtr, outputPtr)
-	if res != 0 {
-		return nil, errors.New("tachyon: internal error")
+	var mac Digest
+	if err := hashFull(data, DomainMessageAuth, 0, key, &mac); err != nil {
+		return nil, err
 	}
-
-	return mac, nil
+	return mac[:], nil
 }
 
 // VerifyMAC verifies keyed hash (MAC) in constant time.
@@ -181,21 +138,11 @@ func VerifyMAC(data []byte, key []byte, expectedMAC []byte) (bool, error) {
 		return false, errors.New("tachyon: input cannot be empty")
 	}
 
-	inputPtr := (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	inputLen := C.size_t(len(data))
-	keyPtr := (*C.uint8_t)(unsafe.Pointer(&key[0]))
-	macPtr := (*C.uint8_t)(unsafe.Pointer(&expectedMAC[0]))
-
-	res := C.tachyon_verify_mac(inputPtr, inputLen, keyPtr, macPtr)
-
-	switch res {
-	case 1:
-		return true, nil
-	case 0:
-		return false, nil
-	default:
-		return false, errors.New("tachyon: internal error")
+	var mac Digest
+	if err := hashFull(data, DomainMessageAuth, 0, key, &mac); err != nil {
+		return false, err
 	}
+	return subtle.ConstantTimeCompare(mac[:], expectedMAC) == 1, nil
 }
 
 // DeriveKey derives cryptographic key from material.
@@ -204,20 +151,11 @@ func DeriveKey(context string, keyMaterial []byte) ([]byte, error) {
 		return nil, errors.New("tachyon: key material must be 32 bytes")
 	}
 
-	contextBytes := []byte(context)
-	derived := make([]byte, 32)
-	
-	contextPtr := (*C.uint8_t)(unsafe.Pointer(&contextBytes[0]))
-	contextLen := C.size_t(len(contextBytes))
-	materialPtr := (*C.uint8_t)(unsafe.Pointer(&keyMaterial[0]))
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&derived[0]))
-
-	res := C.tachyon_derive_key(contextPtr, contextLen, materialPtr, outputPtr)
-	if res != 0 {
-		return nil, errors.New("tachyon: internal error or invalid UTF-8")
+	var derived Digest
+	if err := deriveKey(context, keyMaterial, &derived); err != nil {
+		return nil, err
 	}
-
-	return derived, nil
+	return derived[:], nil
 }
 
 // ============================================================================
@@ -232,39 +170,83 @@ func DeriveKey(context string, keyMaterial []byte) ([]byte, error) {
 //	hasher.Update([]byte("chunk 1"))
 //	hasher.Update([]byte("chunk 2"))
 //	hash := hasher.Finalize()
+//
+// Hasher also implements hash.Hash with the semantics of crypto/sha256: Sum
+// works on a copy of the state, so Write may continue after it, and Reset
+// starts over with the same configuration. Finalize and SumInto end the
+// hasher until Reset; Close ends it for good.
+//
+// A Hasher that is dropped without Finalize or Close has its native state
+// freed by a finalizer; see SetLeakLogger to find such hashers.
 type Hasher struct {
-	state     unsafe.Pointer
+	state     stateHandle
 	finalized bool
+	size      int       // Output size in bytes; 0 means 32
+	n         int64     // Bytes absorbed
+	origin    []uintptr // Creation stack, recorded for SetLeakLogger
 	mu        sync.Mutex
+
+	// Configuration of the state, kept for Reset. Keyed hashers keep a
+	// native copy of their fresh state instead of the key, so the key never
+	// stays on the Go heap.
+	domain, seed uint64
+	template     stateHandle // Fresh keyed state; nil for unkeyed hashers
+	closed       bool
+}
+
+var _ hash.Hash = (*Hasher)(nil)
+
+// startHasher creates a Hasher over a new state, or returns nil if the
+// state could not be created.
+func startHasher(domain, seed uint64, key []byte, size int) *Hasher {
+	state := newState(domain, seed, key)
+	if state == nil {
+		return nil
+	}
+	var template stateHandle
+	if key != nil {
+		if template = stateClone(state); template == nil {
+			stateFree(state)
+			return nil
+		}
+	}
+	h := newHasher(state, size)
+	h.domain, h.seed, h.template = domain, seed, template
+	return h
 }
 
 // NewHasher creates a new streaming hasher.
 //
 // Returns nil if the hasher could not be created (e.g., CPU doesn't support AVX-512).
 func NewHasher() *Hasher {
-	state := C.tachyon_hasher_new()
-	if state == nil {
-		return nil
-	}
-	return &Hasher{state: state}
+	return startHasher(0, 0, nil, 0)
 }
 
 // NewHasherWithDomain creates a new streaming hasher with domain separation.
+//
+// Like the other bindings it accepts any domain, including those above
+// MaxDomain that sized outputs use; New(WithDomain(domain)) rejects them
+// with an error instead.
 func NewHasherWithDomain(domain uint64) *Hasher {
-	state := C.tachyon_hasher_new_with_domain(C.uint64_t(domain))
-	if state == nil {
-		return nil
-	}
-	return &Hasher{state: state}
+	return startHasher(domain, 0, nil, 0)
 }
 
 // NewHasherSeeded creates a new streaming hasher with a seed.
 func NewHasherSeeded(seed uint64) *Hasher {
-	state := C.tachyon_hasher_new_seeded(C.uint64_t(seed))
-	if state == nil {
-		return nil
+	return startHasher(0, seed, nil, 0)
+}
+
+// NewHasherKeyed creates a new streaming keyed hasher (MAC) with a 32-byte
+// key. Finalize returns the same result as HashKeyed over all data written.
+func NewHasherKeyed(key []byte) (*Hasher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("tachyon: key must be 32 bytes")
 	}
-	return &Hasher{state: state}
+	h := startHasher(DomainMessageAuth, 0, key, 0)
+	if h == nil {
+		return nil, errors.New("tachyon: could not create hasher")
+	}
+	return h, nil
 }
 
 // Update adds data to the hasher.
@@ -272,6 +254,7 @@ func NewHasherSeeded(seed uint64) *Hasher {
 // Can be called multiple times before Finalize.
 // Returns an error if the hasher was already finalized.
 func (h *Hasher) Update(data []byte) error {
+	chunkBoundary("update")
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -282,12 +265,41 @@ func (h *Hasher) Update(data []byte) error {
 		return nil // No-op for empty data
 	}
 
-	dataPtr := (*C.uint8_t)(unsafe.Pointer(&data[0]))
-	dataLen := C.size_t(len(data))
-	C.tachyon_hasher_update(h.state, dataPtr, dataLen)
+	stateUpdate(h.state, data)
+	h.n += int64(len(data))
 	return nil
 }
 
+// Len returns the number of bytes absorbed so far.
+func (h *Hasher) Len() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n
+}
+
+// Peek returns the digest of the data absorbed so far without finalizing,
+// e.g. to emit progressive checksums during a long ingest. Update may
+// continue afterwards.
+func (h *Hasher) Peek() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.finalized {
+		return nil, errors.New("tachyon: hasher already finalized")
+	}
+	clone := stateClone(h.state)
+	if clone == nil {
+		return nil, errors.New("tachyon: could not clone hasher state")
+	}
+
+	var hash Digest
+	stateFinalize(clone, &hash)
+	if h.size != 0 {
+		return resizeDigest(hash[:], h.size)
+	}
+	return hash[:], nil
+}
+
 // Finalize returns the final hash and releases resources.
 //
 // The hasher cannot be used after calling Finalize.
@@ -299,15 +311,75 @@ func (h *Hasher) Finalize() ([]byte, error) {
 		return nil, errors.New("tachyon: hasher already finalized")
 	}
 
-	hash := make([]byte, 32)
-	outputPtr := (*C.uint8_t)(unsafe.Pointer(&hash[0]))
-	C.tachyon_hasher_finalize(h.state, outputPtr)
+	var hash Digest
+	stateFinalize(h.state, &hash)
 	h.finalized = true
 	h.state = nil
-	return hash, nil
+	if h.size != 0 {
+		return resizeDigest(hash[:], h.size)
+	}
+	return hash[:], nil
+}
+
+// Clone returns an independent copy of the hasher, e.g. to hash a shared
+// prefix once and then branch into many suffixes:
+//
+//	base := tachyon.NewHasher()
+//	base.Update(header)
+//	for _, rec := range records {
+//	    h := base.Clone()
+//	    h.Update(rec)
+//	    sum, _ := h.Finalize()
+//	}
+//
+// Returns nil if the hasher was already finalized or the state could not be
+// copied.
+func (h *Hasher) Clone() *Hasher {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.finalized {
+		return nil
+	}
+	state := stateClone(h.state)
+	if state == nil {
+		return nil
+	}
+	clone := newHasher(state, h.size)
+	clone.n = h.n
+	clone.domain, clone.seed = h.domain, h.seed
+	if h.template != nil {
+		if clone.template = stateClone(h.template); clone.template == nil {
+			clone.Close()
+			return nil
+		}
+	}
+	return clone
 }
 
-// Close releases resources without finalizing.
+// SumInto finalizes the hasher like Finalize, writing the digest into dst
+// instead of allocating a result.
+//
+// It requires the default 32-byte output size.
+func (h *Hasher) SumInto(dst *Digest) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.finalized {
+		return errors.New("tachyon: hasher already finalized")
+	}
+	if h.size != 0 && h.size != DigestSize {
+		return errors.New("tachyon: SumInto requires a 32-byte output size")
+	}
+
+	stateFinalize(h.state, dst)
+	h.finalized = true
+	h.state = nil
+	return nil
+}
+
+// Close releases resources without finalizing, including the copy of a
+// keyed hasher's initial state kept for Reset.
 //
 // Use this if you need to abort a hash computation.
 func (h *Hasher) Close() {
@@ -315,8 +387,73 @@ func (h *Hasher) Close() {
 	defer h.mu.Unlock()
 
 	if h.state != nil && !h.finalized {
-		C.tachyon_hasher_free(h.state)
+		stateFree(h.state)
 		h.state = nil
 		h.finalized = true
 	}
+	if h.template != nil {
+		stateFree(h.template)
+		h.template = nil
+	}
+	h.closed = true
+}
+
+// ============================================================================
+// HASH.HASH
+// ============================================================================
+
+// Write adds p to the hasher like Update. It implements io.Writer and
+// hash.Hash, and fails only after Finalize, SumInto or Close.
+func (h *Hasher) Write(p []byte) (int, error) {
+	if err := h.Update(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sum appends the digest of the data written so far to b, leaving the
+// hasher unchanged like Peek. It panics after Finalize, SumInto or Close,
+// since hash.Hash has no way to report the error.
+func (h *Hasher) Sum(b []byte) []byte {
+	sum, err := h.Peek()
+	if err != nil {
+		panic(err)
+	}
+	return append(b, sum...)
 }
+
+// Reset discards the data written so far, keeping the domain, seed, key and
+// output size. A finalized hasher becomes usable again; Reset panics after
+// Close.
+func (h *Hasher) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		panic("tachyon: Reset of a closed hasher")
+	}
+	if h.state != nil {
+		stateFree(h.state)
+	}
+	if h.template != nil {
+		h.state = stateClone(h.template)
+	} else {
+		h.state = newState(h.domain, h.seed, nil)
+	}
+	if h.state == nil {
+		panic("tachyon: could not create hasher")
+	}
+	h.finalized = false
+	h.n = 0
+}
+
+// Size returns the number of bytes Sum appends.
+func (h *Hasher) Size() int {
+	if h.size != 0 {
+		return h.size
+	}
+	return DigestSize
+}
+
+// BlockSize returns BlockSize.
+func (h *Hasher) BlockSize() int { return BlockSize }
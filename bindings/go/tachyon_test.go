@@ -2,6 +2,10 @@ package tachyon
 
 import (
 	"bytes"
+	"hash"
+	"io"
+	"runtime"
+	"strconv"
 	"testing"
 )
 
@@ -26,6 +30,31 @@ func TestConstants(t *testing.T) {
 	}
 }
 
+// TestCanonicalVectors checks the active native backend (vendored C or Rust
+// library) against the official vectors in algorithms/tachyon/VECTORS.md.
+func TestCanonicalVectors(t *testing.T) {
+	vectors := []struct {
+		input []byte
+		want  string
+	}{
+		{nil, "7f3485746a9ec855ec3ff1c8287e6c6cfbfa454a8bfa3dd71c3c3e5b39e7c549"},
+		{[]byte("abc"), "3138c10ba15fe7d7fad8c7fc380474a0be7737a4e6296d246304ed767903e85b"},
+		{[]byte("Tachyon"), "120b887e8501bf2a342d397cc46d43b1796502ad75232e7f4c555379cef8c120"},
+		{bytes.Repeat([]byte("A"), 1024), "f14c3aeee98faa9f5c38f08c76f479d425f39da9b277743eff6c576f0470d509"},
+		{bytes.Repeat([]byte("A"), 1024*1024), "7693207f8983d9b991278d951cd4986589a5ffe611c05ee3011426b34dcc4689"},
+		{bytes.Repeat([]byte{0x02}, 63), "9e97ee668990325ac2189a2ce25e1f37d95177546bbf65cbe7b0ad8610978964"},
+	}
+	for _, v := range vectors {
+		hash, err := Hash(v.input)
+		if err != nil {
+			t.Fatalf("Hash failed: %v", err)
+		}
+		if got := Digest(hash).String(); got != v.want {
+			t.Errorf("Hash(%d bytes) = %s, want %s", len(v.input), got, v.want)
+		}
+	}
+}
+
 func TestHashWithDomain(t *testing.T) {
 	data := []byte("test data")
 
@@ -263,3 +292,226 @@ func TestErrorHandling(t *testing.T) {
 		t.Error("Wrong MAC size should return error")
 	}
 }
+
+func TestSum(t *testing.T) {
+	data := []byte("sum without allocating")
+	want, _ := Hash(data)
+	got, err := Sum(data)
+	if err != nil {
+		t.Fatalf("Sum failed: %v", err)
+	}
+	if !bytes.Equal(got[:], want) {
+		t.Error("Sum should equal Hash")
+	}
+
+	h := NewHasher()
+	h.Update(data)
+	var dst Digest
+	if err := h.SumInto(&dst); err != nil {
+		t.Fatalf("SumInto failed: %v", err)
+	}
+	if dst != got {
+		t.Error("SumInto should equal Sum")
+	}
+	if err := h.SumInto(&dst); err == nil {
+		t.Error("SumInto after finalize should return error")
+	}
+
+	h, _ = New(WithOutputSize(Size128))
+	if err := h.SumInto(&dst); err == nil {
+		t.Error("SumInto with a non-32-byte output size should return error")
+	}
+	h.Close()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Sum(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Sum allocates %v times, want 0", allocs)
+	}
+}
+
+func TestHasherLenPeek(t *testing.T) {
+	data := bytes.Repeat([]byte("ingest"), 100000)
+	h, err := New(WithOutputSize(Size512))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for off := 0; off < len(data); off += 150000 {
+		end := min(off+150000, len(data))
+		h.Update(data[off:end])
+		if h.Len() != int64(end) {
+			t.Fatalf("Len() = %d, want %d", h.Len(), end)
+		}
+		peek, err := h.Peek()
+		if err != nil {
+			t.Fatalf("Peek failed: %v", err)
+		}
+		want, _ := Hash(data[:end], WithOutputSize(Size512))
+		if !bytes.Equal(peek, want) {
+			t.Errorf("Peek after %d bytes should equal Hash of the prefix", end)
+		}
+	}
+
+	final, _ := h.Finalize()
+	want, _ := Hash(data, WithOutputSize(Size512))
+	if !bytes.Equal(final, want) {
+		t.Error("Peek should not disturb the final digest")
+	}
+	if _, err := h.Peek(); err == nil {
+		t.Error("Peek after finalize should return error")
+	}
+}
+
+func TestHasherClone(t *testing.T) {
+	header := bytes.Repeat([]byte("H"), 300*1024)
+	base, _ := New(WithSeed(3), WithOutputSize(Size128))
+	base.Update(header)
+
+	for _, rec := range []string{"", "record 1", "record 2"} {
+		h := base.Clone()
+		if h == nil {
+			t.Fatal("Clone returned nil")
+		}
+		h.Update([]byte(rec))
+		if h.Len() != int64(len(header)+len(rec)) {
+			t.Errorf("Len() = %d, want %d", h.Len(), len(header)+len(rec))
+		}
+		got, _ := h.Finalize()
+		want, _ := Hash(append(header, rec...), WithSeed(3), WithOutputSize(Size128))
+		if !bytes.Equal(got, want) {
+			t.Errorf("Clone + %q should equal Hash of header + record", rec)
+		}
+	}
+
+	got, _ := base.Finalize()
+	want, _ := Hash(header, WithSeed(3), WithOutputSize(Size128))
+	if !bytes.Equal(got, want) {
+		t.Error("Cloning should not disturb the original")
+	}
+	if base.Clone() != nil {
+		t.Error("Clone of a finalized hasher should return nil")
+	}
+}
+
+// interleave writes pieces to h, taking a Sum after each, and returns the
+// sums: the digests of every prefix, if Sum does not consume the state.
+func interleave(h hash.Hash, pieces []string) [][]byte {
+	var sums [][]byte
+	for _, p := range pieces {
+		io.WriteString(h, p)
+		sums = append(sums, h.Sum(nil))
+	}
+	return sums
+}
+
+func TestHasherHashInterface(t *testing.T) {
+	pieces := []string{"", "a", "bc", string(bytes.Repeat([]byte("x"), 300*1024)), "tail"}
+
+	// Sums are prefix digests, as with crypto/sha256
+	var prefix string
+	for i, sum := range interleave(NewHasher(), pieces) {
+		prefix += pieces[i]
+		want, _ := Hash([]byte(prefix))
+		if !bytes.Equal(sum, want) {
+			t.Errorf("Sum after piece %d should equal Hash of the prefix", i)
+		}
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+	h, err := New(WithKey(key), WithSeed(9), WithOutputSize(Size512))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if h.Size() != Size512 || h.BlockSize() != BlockSize {
+		t.Errorf("Size() = %d, BlockSize() = %d", h.Size(), h.BlockSize())
+	}
+	h.Write([]byte("first"))
+	out := h.Sum([]byte("prefix:"))
+	want, _ := Hash([]byte("first"), WithKey(key), WithSeed(9), WithOutputSize(Size512))
+	if !bytes.Equal(out, append([]byte("prefix:"), want...)) {
+		t.Error("Sum should append the digest to b")
+	}
+
+	// Reset keeps the configuration, also after Finalize
+	h.Finalize()
+	h.Reset()
+	h.Write([]byte("second"))
+	want, _ = Hash([]byte("second"), WithKey(key), WithSeed(9), WithOutputSize(Size512))
+	if got := h.Sum(nil); !bytes.Equal(got, want) || h.Len() != 6 {
+		t.Error("Reset should restart with the same key, seed and size")
+	}
+	key[0] = 0 // The hasher keeps its own copy
+	h.Reset()
+	h.Write([]byte("second"))
+	if got, _ := h.Finalize(); !bytes.Equal(got, want) {
+		t.Error("Reset should not see later changes to the key")
+	}
+
+	if n, err := h.Write([]byte("late")); err == nil || n != 0 {
+		t.Error("Write after Finalize should return error")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Sum after Finalize should panic")
+		}
+	}()
+	h.Sum(nil)
+}
+
+func TestHasherResetKeepsNoKey(t *testing.T) {
+	key := bytes.Repeat([]byte{5}, 32)
+	h, _ := NewHasherKeyed(key)
+	if h.template == nil {
+		t.Fatal("Keyed hasher should keep a template state")
+	}
+	if u := NewHasher(); u.template != nil {
+		t.Error("Unkeyed hasher should not keep a template state")
+	}
+
+	h.Write([]byte("abc"))
+	h.Finalize()
+	h.Reset()
+	h.Write([]byte("abc"))
+	want, _ := HashKeyed([]byte("abc"), key)
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Error("Reset from the template should keep the key")
+	}
+	if c := h.Clone(); c == nil || c.template == nil || c.template == h.template {
+		t.Error("Clone should copy the template")
+	}
+
+	h.Close()
+	if h.template != nil || h.state != nil {
+		t.Error("Close should free the state and the template")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Reset after Close should panic")
+		}
+	}()
+	h.Reset()
+}
+
+// BenchmarkHashParallel runs one-shot hashes from at least 64 goroutines. The
+// one-shot path shares no locks or buffers, so ns/op should fall with every
+// added core; a flat or rising curve under -cpu 1,4,16,64 means contention.
+func BenchmarkHashParallel(b *testing.B) {
+	parallelism := (64 + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0)
+	for _, size := range []int{64, 4 * 1024, nativeChunkSize + 1, 4 * nativeChunkSize} {
+		data := make([]byte, size)
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			b.SetParallelism(parallelism)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					Sum(data)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package tokens provides compact MAC-authenticated tokens (session tickets,
+// signed cookies, short-lived capabilities).
+//
+// A token is the unpadded base64url encoding of
+//
+//	version (1) | expiry (8, LE unix seconds, 0 = none) | payload | tag (32)
+//
+// where tag = HashKeyed(version | expiry | payload, DeriveKey(context, key)).
+// Payloads are authenticated, not encrypted.
+//
+// Verify accepts several keys so keys can be rotated: sign with the newest
+// key and keep verifying with the previous ones until their tokens expire.
+//
+// Example:
+//
+//	token, _ := tokens.SignExpiring(sessionID, key, time.Now().Add(time.Hour))
+//	payload, err := tokens.Verify(token, key, previousKey)
+package tokens
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrMalformed is returned for tokens that do not decode.
+	ErrMalformed = errors.New("tokens: malformed token")
+
+	// ErrInvalid is returned when no accepted key verifies the token.
+	ErrInvalid = errors.New("tokens: invalid signature")
+
+	// ErrExpired is returned for an authentic token past its expiry.
+	ErrExpired = errors.New("tokens: token expired")
+
+	errKeySize = errors.New("tokens: key must be 32 bytes")
+	errNoKeys  = errors.New("tokens: no verification keys")
+)
+
+// ============================================================================
+// FORMAT
+// ============================================================================
+
+const (
+	version    = 1
+	headerSize = 1 + 8
+	tagSize    = 32
+
+	// context separates token MAC keys from other uses of the same key.
+	context = "tachyon-tokens v1 signing key"
+)
+
+var encoding = base64.RawURLEncoding
+
+// now is replaced in tests.
+var now = time.Now
+
+func macKey(key []byte) ([]byte, error) {
+	if len(key) != 32 {
+		return nil, errKeySize
+	}
+	return tachyon.DeriveKey(context, key)
+}
+
+// ============================================================================
+// SIGN / VERIFY
+// ============================================================================
+
+// Sign returns a token carrying payload that never expires.
+func Sign(payload, key []byte) (string, error) {
+	return SignExpiring(payload, key, time.Time{})
+}
+
+// SignExpiring returns a token carrying payload that Verify rejects after
+// expiresAt. A zero expiresAt means no expiry.
+func SignExpiring(payload, key []byte, expiresAt time.Time) (string, error) {
+	mk, err := macKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	var expiry uint64
+	if !expiresAt.IsZero() {
+		expiry = uint64(expiresAt.Unix())
+	}
+
+	body := make([]byte, headerSize, headerSize+len(payload)+tagSize)
+	body[0] = version
+	binary.LittleEndian.PutUint64(body[1:], expiry)
+	body = append(body, payload...)
+
+	tag, err := tachyon.HashKeyed(body, mk)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(append(body, tag...)), nil
+}
+
+// Verify checks token against each of keys in turn and returns its payload.
+//
+// The tag is compared in constant time. Expiry is checked only once the tag
+// verifies, so ErrExpired is never returned for a forged token.
+func Verify(token string, keys ...[]byte) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, errNoKeys
+	}
+
+	raw, err := encoding.DecodeString(token)
+	if err != nil || len(raw) < headerSize+tagSize || raw[0] != version {
+		return nil, ErrMalformed
+	}
+	body, tag := raw[:len(raw)-tagSize], raw[len(raw)-tagSize:]
+
+	verified := false
+	for _, key := range keys {
+		mk, err := macKey(key)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := tachyon.VerifyMAC(body, mk, tag)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalid
+	}
+
+	expiry := binary.LittleEndian.Uint64(body[1:headerSize])
+	if expiry != 0 && now().Unix() >= int64(expiry) {
+		return nil, ErrExpired
+	}
+	return body[headerSize:], nil
+}
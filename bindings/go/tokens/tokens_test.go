@@ -0,0 +1,89 @@
+package tokens
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	keyOld = bytes.Repeat([]byte{0x01}, 32)
+	keyNew = bytes.Repeat([]byte{0x02}, 32)
+)
+
+func TestSignVerify(t *testing.T) {
+	token, err := Sign([]byte("session:1234"), keyNew)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	payload, err := Verify(token, keyNew)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if string(payload) != "session:1234" {
+		t.Errorf("payload = %q, want %q", payload, "session:1234")
+	}
+
+	// Empty payloads are allowed
+	token, _ = Sign(nil, keyNew)
+	if payload, err := Verify(token, keyNew); err != nil || len(payload) != 0 {
+		t.Errorf("Empty payload round trip = %q, %v", payload, err)
+	}
+}
+
+func TestTamperAndWrongKey(t *testing.T) {
+	token, _ := Sign([]byte("admin=false"), keyNew)
+
+	if _, err := Verify(token, keyOld); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify with wrong key = %v, want ErrInvalid", err)
+	}
+
+	raw, _ := encoding.DecodeString(token)
+	raw[headerSize] ^= 1
+	if _, err := Verify(encoding.EncodeToString(raw), keyNew); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify of tampered token = %v, want ErrInvalid", err)
+	}
+
+	if _, err := Verify("not a token!", keyNew); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Verify of garbage = %v, want ErrMalformed", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	legacy, _ := Sign([]byte("old ticket"), keyOld)
+	current, _ := Sign([]byte("new ticket"), keyNew)
+
+	for _, token := range []string{legacy, current} {
+		if _, err := Verify(token, keyNew, keyOld); err != nil {
+			t.Errorf("Verify during rotation failed: %v", err)
+		}
+	}
+	if _, err := Verify(legacy, keyNew); !errors.Is(err, ErrInvalid) {
+		t.Error("Retired key should no longer verify")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	defer func() { now = time.Now }()
+	base := time.Unix(1_700_000_000, 0)
+	now = func() time.Time { return base }
+
+	token, _ := SignExpiring([]byte("short-lived"), keyNew, base.Add(time.Minute))
+	if _, err := Verify(token, keyNew); err != nil {
+		t.Fatalf("Verify before expiry failed: %v", err)
+	}
+
+	now = func() time.Time { return base.Add(2 * time.Minute) }
+	if _, err := Verify(token, keyNew); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify after expiry = %v, want ErrExpired", err)
+	}
+
+	// Extending the expiry invalidates the tag
+	raw, _ := encoding.DecodeString(token)
+	raw[1] = 0xff
+	if _, err := Verify(encoding.EncodeToString(raw), keyNew); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify with edited expiry = %v, want ErrInvalid", err)
+	}
+}
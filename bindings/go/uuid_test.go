@@ -0,0 +1,71 @@
+package tachyon
+
+import "testing"
+
+func TestNewUUIDv8(t *testing.T) {
+	u, err := NewUUIDv8([]byte("row content"))
+	if err != nil {
+		t.Fatalf("NewUUIDv8 failed: %v", err)
+	}
+
+	if u.Version() != 8 {
+		t.Errorf("Version() = %d, want 8", u.Version())
+	}
+	if u[8]&0xc0 != 0x80 {
+		t.Error("Variant bits should be 10")
+	}
+
+	again, _ := NewUUIDv8([]byte("row content"))
+	if again != u {
+		t.Error("Same content should produce same UUID")
+	}
+	other, _ := NewUUIDv8([]byte("other content"))
+	if other == u {
+		t.Error("Different content should produce different UUIDs")
+	}
+
+	parsed, err := ParseUUID(u.String())
+	if err != nil {
+		t.Fatalf("ParseUUID failed: %v", err)
+	}
+	if parsed != u {
+		t.Error("ParseUUID(u.String()) should round-trip")
+	}
+}
+
+func TestDeriveUUID(t *testing.T) {
+	nsUsers, _ := NewUUIDv8([]byte("namespace:users"))
+	nsOrders, _ := NewUUIDv8([]byte("namespace:orders"))
+
+	a, err := DeriveUUID(nsUsers, []byte("42"))
+	if err != nil {
+		t.Fatalf("DeriveUUID failed: %v", err)
+	}
+	b, _ := DeriveUUID(nsOrders, []byte("42"))
+	if a == b {
+		t.Error("Different namespaces should produce different UUIDs")
+	}
+	if a.Version() != 8 {
+		t.Errorf("Version() = %d, want 8", a.Version())
+	}
+
+	// Name-based and content UUIDs live in separate spaces
+	content, _ := NewUUIDv8(append(nsUsers[:], "42"...))
+	if content == a {
+		t.Error("DeriveUUID should not collide with NewUUIDv8 of the concatenation")
+	}
+}
+
+func TestParseUUIDErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"00000000-0000-0000-0000-00000000000",
+		"00000000x0000-0000-0000-000000000000",
+		"zzzzzzzz-0000-0000-0000-000000000000",
+	}
+	for _, s := range bad {
+		if _, err := ParseUUID(s); err == nil {
+			t.Errorf("ParseUUID(%q) should return error", s)
+		}
+	}
+}
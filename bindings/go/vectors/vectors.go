@@ -0,0 +1,324 @@
+// Package vectors generates, exports and checks Tachyon test vectors.
+//
+// Beyond the plain one-shot vectors of the reference suite
+// (algorithms/tachyon/tests/test_vectors.json), the set covers every mode of
+// the hash: keyed, seeded, each standard domain, each output size, and
+// streaming with fixed Update split points across the chunk and tree
+// boundaries. Inputs are stored as real bytes, not placeholders.
+//
+// A generated copy is embedded in the package, so any implementation can be
+// checked without regenerating:
+//
+//	vs, err := vectors.Embedded()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, f := range vectors.Verify(vs, myImplementation) {
+//	    log.Printf("%s: got %x, want %x (%v)", f.Vector.Name, f.Got, f.Vector.Hash, f.Err)
+//	}
+package vectors
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"tachyon"
+)
+
+// ============================================================================
+// VECTOR FORMAT
+// ============================================================================
+
+// FormatVersion is the version of the JSON file format written by Write.
+const FormatVersion = 1
+
+// Vector is one test case. The expected Hash is
+//
+//	Hash(Input, WithDomain(Domain), WithSeed(Seed), WithOutputSize(Size))
+//
+// plus WithKey(Key) when Key is set. With Splits, the input is fed to a
+// streaming hasher in pieces of those lengths, then the remainder; an
+// implementation must produce the same Hash either way.
+type Vector struct {
+	Name   string
+	Input  []byte
+	Key    []byte // nil or 32 bytes
+	Seed   uint64
+	Domain uint64
+	Size   int   // Output size in bytes; 0 means 32
+	Splits []int // Streaming piece lengths; nil means one-shot
+	Hash   []byte
+}
+
+// jsonVector is the JSON form of a Vector: byte strings are hex.
+type jsonVector struct {
+	Name   string `json:"name"`
+	Input  string `json:"input"`
+	Key    string `json:"key,omitempty"`
+	Seed   uint64 `json:"seed,omitempty"`
+	Domain uint64 `json:"domain,omitempty"`
+	Size   int    `json:"size,omitempty"`
+	Splits []int  `json:"splits,omitempty"`
+	Hash   string `json:"hash"`
+}
+
+type jsonFile struct {
+	Version int          `json:"version"`
+	Vectors []jsonVector `json:"vectors"`
+}
+
+// Write writes vs as indented JSON.
+func Write(w io.Writer, vs []Vector) error {
+	file := jsonFile{Version: FormatVersion, Vectors: make([]jsonVector, len(vs))}
+	for i, v := range vs {
+		file.Vectors[i] = jsonVector{
+			Name:   v.Name,
+			Input:  hex.EncodeToString(v.Input),
+			Key:    hex.EncodeToString(v.Key),
+			Seed:   v.Seed,
+			Domain: v.Domain,
+			Size:   v.Size,
+			Splits: v.Splits,
+			Hash:   hex.EncodeToString(v.Hash),
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+// Read reads vectors written by Write.
+func Read(r io.Reader) ([]Vector, error) {
+	var file jsonFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Version != FormatVersion {
+		return nil, fmt.Errorf("vectors: unsupported format version %d", file.Version)
+	}
+	vs := make([]Vector, len(file.Vectors))
+	for i, jv := range file.Vectors {
+		v := Vector{Name: jv.Name, Seed: jv.Seed, Domain: jv.Domain, Size: jv.Size, Splits: jv.Splits}
+		var err error
+		if v.Input, err = hex.DecodeString(jv.Input); err != nil {
+			return nil, fmt.Errorf("vectors: %s: input: %w", jv.Name, err)
+		}
+		if jv.Key != "" {
+			if v.Key, err = hex.DecodeString(jv.Key); err != nil {
+				return nil, fmt.Errorf("vectors: %s: key: %w", jv.Name, err)
+			}
+		}
+		if v.Hash, err = hex.DecodeString(jv.Hash); err != nil {
+			return nil, fmt.Errorf("vectors: %s: hash: %w", jv.Name, err)
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// vectorsGz is the output of Generate, written with Write and gzipped. Large
+// inputs are periodic and compress to almost nothing.
+//
+//go:generate go test -run TestEmbedded -update
+//go:embed vectors.json.gz
+var vectorsGz []byte
+
+// Embedded returns the vectors embedded in the package.
+func Embedded() ([]Vector, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(vectorsGz))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return Read(zr)
+}
+
+// ParseLegacy reads the reference suite format, whose large inputs are named
+// by placeholders, and returns the vectors with the placeholders expanded.
+func ParseLegacy(data []byte) ([]Vector, error) {
+	var file struct {
+		Vectors []struct{ Name, Input, Hash string }
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	vs := make([]Vector, len(file.Vectors))
+	for i, lv := range file.Vectors {
+		hash, err := hex.DecodeString(lv.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("vectors: %s: hash: %w", lv.Name, err)
+		}
+		vs[i] = Vector{Name: lv.Name, Input: legacyInput(lv.Input), Hash: hash}
+	}
+	return vs, nil
+}
+
+// legacyInput expands the placeholders of the reference suite.
+func legacyInput(input string) []byte {
+	switch input {
+	case "MEDIUM_256_A":
+		return bytes.Repeat([]byte{0x41}, 256)
+	case "LARGE_1KB":
+		return bytes.Repeat([]byte{0x41}, 1024)
+	case "HUGE_1MB":
+		return bytes.Repeat([]byte{0x41}, 1024*1024)
+	case "EXACT_64_ZERO":
+		return make([]byte, 64)
+	case "EXACT_512_ONE":
+		return bytes.Repeat([]byte{0x01}, 512)
+	case "UNALIGNED_63_TWO":
+		return bytes.Repeat([]byte{0x02}, 63)
+	default:
+		return []byte(input)
+	}
+}
+
+// ============================================================================
+// GENERATION
+// ============================================================================
+
+// chunkSize is the native chunk size; inputs around its multiples exercise
+// the tree.
+const chunkSize = 256 * 1024
+
+// Pattern returns n bytes of the input pattern: byte i is i mod 251, so no
+// chunk or block repeats at a power-of-two period.
+func Pattern(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+// testKey is the key of keyed vectors: bytes 0x00 to 0x1f.
+func testKey() []byte {
+	k := make([]byte, 32)
+	for i := range k {
+		k[i] = byte(i)
+	}
+	return k
+}
+
+// cases returns the vectors of the canonical set, without hashes.
+func cases() []Vector {
+	vs := []Vector{
+		// The reference suite, with real inputs
+		{Name: "basic", Input: []byte("abc")},
+		{Name: "empty", Input: []byte{}},
+		{Name: "large", Input: legacyInput("LARGE_1KB")},
+		{Name: "medium_256", Input: legacyInput("MEDIUM_256_A")},
+		{Name: "small", Input: []byte("Tachyon")},
+		{Name: "exact_block_64", Input: legacyInput("EXACT_64_ZERO")},
+		{Name: "exact_block_512", Input: legacyInput("EXACT_512_ONE")},
+		{Name: "unaligned_63", Input: legacyInput("UNALIGNED_63_TWO")},
+		{Name: "huge", Input: legacyInput("HUGE_1MB")},
+	}
+
+	for _, n := range []int{1, 65, 1025, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17} {
+		vs = append(vs, Vector{Name: fmt.Sprintf("pattern_%d", n), Input: Pattern(n)})
+	}
+	for d := uint64(tachyon.DomainGeneric); d <= tachyon.DomainContentAddressed; d++ {
+		vs = append(vs, Vector{Name: fmt.Sprintf("domain_%d", d), Input: Pattern(100), Domain: d})
+	}
+	vs = append(vs, Vector{Name: "domain_custom", Input: Pattern(100), Domain: 0x0123456789})
+	for _, seed := range []uint64{1, 0xdeadbeef, ^uint64(0)} {
+		vs = append(vs, Vector{Name: fmt.Sprintf("seeded_%x", seed), Input: Pattern(1000), Seed: seed})
+	}
+	for _, n := range []int{0, 64, chunkSize + 1} {
+		vs = append(vs, Vector{Name: fmt.Sprintf("keyed_%d", n), Input: Pattern(n), Key: testKey(), Domain: tachyon.DomainMessageAuth})
+	}
+	vs = append(vs, Vector{Name: "keyed_content_addressed", Input: Pattern(64), Key: testKey(), Domain: tachyon.DomainContentAddressed})
+	for _, size := range []int{tachyon.Size128, tachyon.Size192, tachyon.Size512} {
+		vs = append(vs, Vector{Name: fmt.Sprintf("size_%d", size), Input: Pattern(200), Size: size})
+	}
+	vs = append(vs, Vector{Name: "size_512_keyed_seeded", Input: Pattern(2*chunkSize + 3), Key: testKey(), Seed: 42, Domain: tachyon.DomainKeyDerivation, Size: tachyon.Size512})
+
+	vs = append(vs,
+		Vector{Name: "split_bytes", Input: Pattern(100), Splits: []int{1, 1, 1, 0, 63}},
+		Vector{Name: "split_chunk_boundary", Input: Pattern(2*chunkSize + 100), Splits: []int{chunkSize - 1, 1, chunkSize}},
+		Vector{Name: "split_uneven_tree", Input: Pattern(5*chunkSize + 17), Splits: []int{7, 3 * chunkSize, 1000, chunkSize - 1}},
+		Vector{Name: "split_keyed_seeded", Input: Pattern(chunkSize + 500), Key: testKey(), Seed: 7, Domain: tachyon.DomainMessageAuth, Splits: []int{500, chunkSize}},
+	)
+	return vs
+}
+
+// Generate computes the canonical vector set with this binding.
+func Generate() ([]Vector, error) {
+	vs := cases()
+	for i := range vs {
+		hash, err := Tachyon(vs[i])
+		if err != nil {
+			return nil, fmt.Errorf("vectors: %s: %w", vs[i].Name, err)
+		}
+		vs[i].Hash = hash
+	}
+	return vs, nil
+}
+
+// ============================================================================
+// VERIFICATION
+// ============================================================================
+
+// Func computes the hash a vector describes with some implementation.
+type Func func(v Vector) ([]byte, error)
+
+// Failure is a vector an implementation got wrong.
+type Failure struct {
+	Vector Vector
+	Got    []byte
+	Err    error
+}
+
+// Verify runs every vector through impl and returns the failures.
+func Verify(vs []Vector, impl Func) []Failure {
+	var failures []Failure
+	for _, v := range vs {
+		got, err := impl(v)
+		if err != nil || !bytes.Equal(got, v.Hash) {
+			failures = append(failures, Failure{Vector: v, Got: got, Err: err})
+		}
+	}
+	return failures
+}
+
+// Tachyon computes v with this binding: Hash for one-shot vectors, a
+// streaming hasher fed v.Splits for split vectors.
+func Tachyon(v Vector) ([]byte, error) {
+	opts := []tachyon.Option{tachyon.WithDomain(v.Domain), tachyon.WithSeed(v.Seed)}
+	if v.Key != nil {
+		opts = append(opts, tachyon.WithKey(v.Key))
+	}
+	if v.Size != 0 {
+		opts = append(opts, tachyon.WithOutputSize(v.Size))
+	}
+	if v.Splits == nil {
+		return tachyon.Hash(v.Input, opts...)
+	}
+
+	h, err := tachyon.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+	rest := v.Input
+	for _, n := range v.Splits {
+		if n < 0 || n > len(rest) {
+			return nil, errors.New("vectors: splits exceed the input")
+		}
+		if err := h.Update(rest[:n]); err != nil {
+			return nil, err
+		}
+		rest = rest[n:]
+	}
+	if err := h.Update(rest); err != nil {
+		return nil, err
+	}
+	return h.Finalize()
+}
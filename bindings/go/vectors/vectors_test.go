@@ -0,0 +1,136 @@
+package vectors
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors.json.gz")
+
+// referenceFile is the suite written by the reference implementation.
+const referenceFile = "../../../algorithms/tachyon/tests/test_vectors.json"
+
+func TestEmbedded(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if *update {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err := Write(zw, generated); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if err := os.WriteFile("vectors.json.gz", buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		vectorsGz = buf.Bytes()
+	}
+
+	embedded, err := Embedded()
+	if err != nil {
+		t.Fatalf("Embedded failed: %v", err)
+	}
+	if !reflect.DeepEqual(embedded, generated) {
+		t.Fatal("vectors.json.gz is stale; run go generate")
+	}
+	if failures := Verify(embedded, Tachyon); len(failures) != 0 {
+		t.Errorf("%d vectors failed, first %s", len(failures), failures[0].Vector.Name)
+	}
+}
+
+func TestReferenceSuite(t *testing.T) {
+	raw, err := os.ReadFile(referenceFile)
+	if err != nil {
+		t.Skipf("reference suite not available: %v", err)
+	}
+	legacy, err := ParseLegacy(raw)
+	if err != nil {
+		t.Fatalf("ParseLegacy failed: %v", err)
+	}
+	if len(legacy) == 0 {
+		t.Fatal("Reference suite should not be empty")
+	}
+	if failures := Verify(legacy, Tachyon); len(failures) != 0 {
+		t.Fatalf("%d reference vectors failed, first %s", len(failures), failures[0].Vector.Name)
+	}
+
+	// Every reference vector is part of the embedded set, with real input
+	embedded, _ := Embedded()
+	byName := make(map[string]Vector)
+	for _, v := range embedded {
+		byName[v.Name] = v
+	}
+	for _, v := range legacy {
+		e, ok := byName[v.Name]
+		if !ok || !bytes.Equal(e.Input, v.Input) || !bytes.Equal(e.Hash, v.Hash) {
+			t.Errorf("Reference vector %s should be embedded unchanged", v.Name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	vs, _ := Embedded()
+
+	if failures := Verify(vs, func(v Vector) ([]byte, error) { return v.Hash, nil }); failures != nil {
+		t.Error("A correct implementation should have no failures")
+	}
+
+	wrong := func(v Vector) ([]byte, error) {
+		if v.Splits != nil {
+			return nil, errors.New("streaming unsupported")
+		}
+		if v.Key != nil {
+			return make([]byte, len(v.Hash)), nil
+		}
+		return v.Hash, nil
+	}
+	for _, f := range Verify(vs, wrong) {
+		if f.Vector.Splits == nil && f.Vector.Key == nil {
+			t.Errorf("%s should not fail", f.Vector.Name)
+		}
+		if f.Vector.Splits != nil && f.Err == nil {
+			t.Errorf("%s should report the error", f.Vector.Name)
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	vs := []Vector{
+		{Name: "plain", Input: []byte("abc"), Hash: []byte{1, 2}},
+		{Name: "full", Input: []byte{}, Key: Pattern(32), Seed: 9, Domain: 3, Size: 64, Splits: []int{0, 1}, Hash: []byte{3}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, vs); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, vs) {
+		t.Errorf("Round trip = %+v, want %+v", got, vs)
+	}
+
+	if _, err := Read(bytes.NewReader([]byte(`{"version":2,"vectors":[]}`))); err == nil {
+		t.Error("Unknown version should return error")
+	}
+	if _, err := Read(bytes.NewReader([]byte(`{"version":1,"vectors":[{"input":"zz"}]}`))); err == nil {
+		t.Error("Invalid hex should return error")
+	}
+}
+
+func TestSplitsValidated(t *testing.T) {
+	if _, err := Tachyon(Vector{Input: []byte("ab"), Splits: []int{3}}); err == nil {
+		t.Error("Splits longer than the input should return error")
+	}
+}
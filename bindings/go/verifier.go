@@ -0,0 +1,195 @@
+package tachyon
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ============================================================================
+// VERIFIER PIPELINE
+// ============================================================================
+
+var (
+	// ErrChunkMismatch reports a chunk whose digest does not match the index.
+	// The chunk may be resubmitted (e.g. fetched from another mirror).
+	ErrChunkMismatch = errors.New("tachyon: chunk digest mismatch")
+
+	// ErrChunkDuplicate reports a chunk that was already verified.
+	ErrChunkDuplicate = errors.New("tachyon: chunk already verified")
+
+	errPipelineClosed = errors.New("tachyon: verifier pipeline closed")
+)
+
+// ChunkResult is the outcome of verifying one submitted chunk.
+type ChunkResult struct {
+	Index int   // Chunk index as submitted
+	Err   error // nil if verified, ErrChunkMismatch or ErrChunkDuplicate otherwise
+}
+
+// VerifierConfig configures a VerifierPipeline.
+type VerifierConfig struct {
+	// Workers is the number of verifying goroutines. Defaults to GOMAXPROCS.
+	Workers int
+
+	// Domain is the domain the index digests were computed under
+	// (DomainGeneric by default).
+	Domain uint8
+}
+
+// VerifierPipeline verifies chunks of a download against a digest index as
+// they arrive, in any order and from any number of goroutines.
+//
+// Chunks are hashed concurrently by a pool of workers and every submission
+// produces one ChunkResult on Results. A chunk that fails verification can
+// be submitted again; Done is closed once every chunk has verified.
+//
+//	p, _ := tachyon.NewVerifierPipeline(index, tachyon.VerifierConfig{})
+//	go func() {
+//	    for r := range p.Results() {
+//	        if r.Err != nil {
+//	            refetch(r.Index)
+//	        }
+//	    }
+//	}()
+//	p.Submit(i, data) // From each downloader goroutine
+//	<-p.Done()
+//	p.Close()
+type VerifierPipeline struct {
+	index   []Digest
+	domain  uint8
+	jobs    chan verifyJob
+	results chan ChunkResult
+	wg      sync.WaitGroup
+
+	closeMu sync.RWMutex // Held for reading while submitting
+	closed  bool
+
+	mu        sync.Mutex
+	verified  []bool
+	remaining int
+	done      chan struct{}
+}
+
+type verifyJob struct {
+	index int
+	data  []byte
+}
+
+// NewVerifierPipeline starts a pipeline verifying chunk i against index[i].
+func NewVerifierPipeline(index []Digest, cfg VerifierConfig) (*VerifierPipeline, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	p := &VerifierPipeline{
+		index:     index,
+		domain:    cfg.Domain,
+		jobs:      make(chan verifyJob, workers),
+		results:   make(chan ChunkResult, len(index)),
+		verified:  make([]bool, len(index)),
+		remaining: len(index),
+		done:      make(chan struct{}),
+	}
+	if p.remaining == 0 {
+		close(p.done)
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p, nil
+}
+
+func (p *VerifierPipeline) worker() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.results <- ChunkResult{Index: job.index, Err: p.verify(job)}
+	}
+}
+
+func (p *VerifierPipeline) verify(job verifyJob) error {
+	if p.isVerified(job.index) {
+		return ErrChunkDuplicate
+	}
+
+	hash, err := HashWithDomain(job.data, p.domain)
+	if err != nil {
+		return err
+	}
+	if Digest(hash) != p.index[job.index] {
+		return ErrChunkMismatch
+	}
+
+	chunkBoundary("verify")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.verified[job.index] {
+		return ErrChunkDuplicate // Verified concurrently by another submission
+	}
+	p.verified[job.index] = true
+	p.remaining--
+	if p.remaining == 0 {
+		close(p.done)
+	}
+	return nil
+}
+
+// Submit queues chunk index for verification. It blocks while all workers
+// are busy; data must not be modified until its result is reported.
+func (p *VerifierPipeline) Submit(index int, data []byte) error {
+	if index < 0 || index >= len(p.index) {
+		return fmt.Errorf("tachyon: chunk index %d out of range [0, %d)", index, len(p.index))
+	}
+
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return errPipelineClosed
+	}
+	p.jobs <- verifyJob{index: index, data: data}
+	return nil
+}
+
+// Results returns the channel of per-chunk results. It must be drained,
+// and is closed by Close once all submitted chunks are reported.
+func (p *VerifierPipeline) Results() <-chan ChunkResult {
+	return p.results
+}
+
+// Done is closed once every chunk in the index has verified.
+func (p *VerifierPipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Remaining returns the number of chunks not yet verified.
+func (p *VerifierPipeline) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.remaining
+}
+
+// Close stops accepting chunks, waits for queued chunks to be verified and
+// closes Results.
+func (p *VerifierPipeline) Close() {
+	p.closeMu.Lock()
+	if p.closed {
+		p.closeMu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.closeMu.Unlock()
+
+	p.wg.Wait()
+	close(p.results)
+}
+
+func (p *VerifierPipeline) isVerified(index int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.verified[index]
+}
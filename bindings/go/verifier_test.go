@@ -0,0 +1,75 @@
+package tachyon
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestVerifierPipeline(t *testing.T) {
+	chunks := make([][]byte, 16)
+	index := make([]Digest, len(chunks))
+	for i := range chunks {
+		chunks[i] = bytes.Repeat([]byte{byte(i)}, 1000+i)
+		h, _ := Hash(chunks[i])
+		index[i] = Digest(h)
+	}
+
+	p, err := NewVerifierPipeline(index, VerifierConfig{Workers: 4})
+	if err != nil {
+		t.Fatalf("NewVerifierPipeline failed: %v", err)
+	}
+
+	// Chunk 3 first arrives corrupted from a bad mirror
+	corrupt := append([]byte(nil), chunks[3]...)
+	corrupt[0] ^= 1
+
+	var results []ChunkResult
+	collected := make(chan struct{})
+	go func() {
+		for r := range p.Results() {
+			results = append(results, r)
+		}
+		close(collected)
+	}()
+
+	if err := p.Submit(3, corrupt); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := len(chunks) - 1; i >= 0; i-- { // Out of order, concurrently
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.Submit(i, chunks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	<-p.Done()
+	if p.Remaining() != 0 {
+		t.Errorf("Remaining() = %d after Done, want 0", p.Remaining())
+	}
+	p.Submit(5, chunks[5]) // Duplicate
+	p.Close()
+	<-collected
+
+	counts := map[error]int{}
+	for _, r := range results {
+		counts[r.Err]++
+		if r.Err == ErrChunkMismatch && r.Index != 3 {
+			t.Errorf("Chunk %d reported as mismatch", r.Index)
+		}
+	}
+	if counts[nil] != len(chunks) || counts[ErrChunkMismatch] != 1 || counts[ErrChunkDuplicate] != 1 {
+		t.Errorf("Result counts = %v, want %d verified, 1 mismatch, 1 duplicate", counts, len(chunks))
+	}
+
+	if err := p.Submit(0, chunks[0]); !errors.Is(err, errPipelineClosed) {
+		t.Errorf("Submit after Close = %v, want error", err)
+	}
+	if err := p.Submit(len(chunks), nil); err == nil {
+		t.Error("Out-of-range index should be rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package tachyon
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"runtime"
+	"sync"
+)
+
+// ============================================================================
+// FILESYSTEM WALK
+// ============================================================================
+
+// walkItem is one file of a WalkHash tree, in walk order.
+type walkItem struct {
+	path   string
+	digest Digest
+	err    error
+	done   chan struct{}
+}
+
+// WalkHash hashes every regular file in fsys concurrently and calls fn with
+// each file's digest, in the lexical order of fs.WalkDir.
+//
+// Digests equal HashWithDomain(content, DomainFileChecksum). Files are hashed
+// by a pool of GOMAXPROCS workers, streaming each file, while fn runs on the
+// calling goroutine one file at a time, so it needs no locking and its output
+// is reproducible. Directories, symlinks and other non-regular files are not
+// reported.
+//
+// If a file or directory cannot be read, fn is called with its path, a zero
+// digest and the error; returning nil continues the walk. Any error fn
+// returns stops the walk and is returned by WalkHash, except fs.SkipAll,
+// which stops it and returns nil.
+func WalkHash(fsys fs.FS, fn func(path string, d Digest, err error) error) error {
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan *walkItem)
+	pending := make(chan *walkItem, 2*workers) // Bounds files in flight
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, readerBufferSize)
+			for it := range jobs {
+				it.digest, it.err = hashFile(fsys, it.path, buf)
+				close(it.done)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			select {
+			case <-stop:
+				return fs.SkipAll
+			default:
+			}
+			it := &walkItem{path: path, err: err, done: make(chan struct{})}
+			switch {
+			case err != nil:
+				close(it.done)
+			case d.Type().IsRegular():
+			default:
+				return nil
+			}
+			select {
+			case pending <- it:
+			case <-stop:
+				return fs.SkipAll
+			}
+			if err == nil {
+				jobs <- it
+			}
+			return nil
+		})
+	}()
+
+	var err error
+	for it := range pending {
+		<-it.done
+		if err != nil {
+			continue // Drain so the walker and workers exit
+		}
+		if err = fn(it.path, it.digest, it.err); err != nil {
+			close(stop)
+		}
+	}
+	wg.Wait()
+
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// hashFile streams the file at path through a file-checksum hasher.
+func hashFile(fsys fs.FS, path string, buf []byte) (Digest, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return Digest{}, err
+	}
+	defer f.Close()
+
+	h := NewHasherWithDomain(DomainFileChecksum)
+	defer h.Close()
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			if uerr := h.Update(buf[:n]); uerr != nil {
+				return Digest{}, uerr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return Digest{}, err
+		}
+	}
+	var d Digest
+	return d, h.SumInto(&d)
+}
@@ -0,0 +1,114 @@
+package tachyon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func walkTree() fstest.MapFS {
+	tree := fstest.MapFS{
+		"b.txt":        {Data: []byte("bravo")},
+		"a/z.bin":      {Data: make([]byte, 3*readerBufferSize+17)},
+		"a/empty":      {Data: nil},
+		"c/d/e.txt":    {Data: []byte("echo")},
+		"link":         {Data: []byte("b.txt"), Mode: fs.ModeSymlink},
+		"c/d/emptydir": {Mode: fs.ModeDir},
+	}
+	for i := 0; i < 40; i++ {
+		tree[fmt.Sprintf("many/%02d", i)] = &fstest.MapFile{Data: []byte(fmt.Sprint(i))}
+	}
+	return tree
+}
+
+func TestWalkHash(t *testing.T) {
+	tree := walkTree()
+	var paths []string
+	err := WalkHash(tree, func(path string, d Digest, err error) error {
+		if err != nil {
+			return err
+		}
+		want, _ := HashWithDomain(tree[path].Data, DomainFileChecksum)
+		if d != Digest(want) {
+			t.Errorf("%s: digest differs from HashWithDomain", path)
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkHash failed: %v", err)
+	}
+
+	var want []string
+	fs.WalkDir(tree, ".", func(path string, d fs.DirEntry, err error) error {
+		if d.Type().IsRegular() {
+			want = append(want, path)
+		}
+		return nil
+	})
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("paths = %v, want walk order %v", paths, want)
+	}
+}
+
+func TestWalkHashStop(t *testing.T) {
+	errStop := errors.New("stop")
+	calls := 0
+	err := WalkHash(walkTree(), func(string, Digest, error) error {
+		calls++
+		if calls == 5 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || calls != 5 {
+		t.Errorf("err = %v after %d calls, want errStop after 5", err, calls)
+	}
+
+	calls = 0
+	err = WalkHash(walkTree(), func(string, Digest, error) error {
+		calls++
+		return fs.SkipAll
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("err = %v after %d calls, want nil after 1", err, calls)
+	}
+}
+
+// failingFS fails to open one file.
+type failingFS struct {
+	fstest.MapFS
+	fail string
+}
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	if name == f.fail {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.Open(name)
+}
+
+func TestWalkHashErrors(t *testing.T) {
+	fsys := failingFS{walkTree(), "c/d/e.txt"}
+
+	var failed []string
+	calls := 0
+	err := WalkHash(fsys, func(path string, d Digest, err error) error {
+		calls++
+		if err != nil {
+			if !d.IsZero() {
+				t.Errorf("%s: failed file should have a zero digest", path)
+			}
+			failed = append(failed, path)
+		}
+		return nil
+	})
+	if err != nil || len(failed) != 1 || failed[0] != "c/d/e.txt" {
+		t.Errorf("err = %v, failed = %v, want only c/d/e.txt reported", err, failed)
+	}
+	if calls != 44 {
+		t.Errorf("got %d calls, want the walk to continue past the error", calls)
+	}
+}
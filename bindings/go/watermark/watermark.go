@@ -0,0 +1,206 @@
+// Package watermark stamps generated files with a truncated self-digest so
+// manual edits can be detected later.
+//
+// Stamp inserts a single comment line into the file header:
+//
+//	// tachyon-watermark v1 gen=protoc-gen-foo ver=1.4.0 digest=<32 hex digits>
+//
+// The digest covers the whole file, including the watermark line and its
+// metadata, except for the 32 hex digits of the digest itself (the excluded
+// region). It is a 128-bit DomainFileChecksum hash. The comment prefix (and
+// optional suffix) is configurable, so any language with line or block
+// comments can carry a watermark; Verify finds the line regardless of
+// comment syntax.
+//
+// A watermark detects accidental edits, not tampering: anyone can restamp a
+// file.
+//
+// Example:
+//
+//	out, err := watermark.Stamp(src, watermark.Options{
+//	    Meta: watermark.Meta{Generator: "schemagen", Version: "2.1.0"},
+//	})
+//	...
+//	meta, err := watermark.Verify(out) // ErrModified after a manual edit
+package watermark
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"tachyon"
+)
+
+// ============================================================================
+// ERRORS
+// ============================================================================
+
+var (
+	// ErrNoWatermark is returned by Verify for files without a watermark.
+	ErrNoWatermark = errors.New("watermark: no watermark found")
+
+	// ErrMalformed is returned for a watermark line that does not parse.
+	ErrMalformed = errors.New("watermark: malformed watermark")
+
+	// ErrModified is returned when the file no longer matches its watermark.
+	ErrModified = errors.New("watermark: file modified since generation")
+
+	errInvalidMeta = errors.New("watermark: metadata must be non-empty and contain no whitespace or '='")
+)
+
+// ============================================================================
+// FORMAT
+// ============================================================================
+
+const (
+	marker     = "tachyon-watermark v1"
+	digestKey  = "digest="
+	digestSize = tachyon.Size128
+	digestHex  = 2 * digestSize
+)
+
+// Meta describes the generator that produced a file.
+type Meta struct {
+	Generator string
+	Version   string
+}
+
+// Options configures Stamp.
+type Options struct {
+	Meta
+
+	// CommentPrefix starts the watermark comment. Defaults to "//".
+	CommentPrefix string
+
+	// CommentSuffix ends it, for block comments such as "*/" or "-->".
+	CommentSuffix string
+}
+
+// ============================================================================
+// STAMP & VERIFY
+// ============================================================================
+
+// Stamp returns content with a watermark line inserted at the top, after a
+// leading "#!" line if there is one. An existing watermark is replaced, so
+// restamping regenerated output is safe.
+func Stamp(content []byte, opts Options) ([]byte, error) {
+	if !validMeta(opts.Generator) || !validMeta(opts.Version) {
+		return nil, errInvalidMeta
+	}
+	prefix := opts.CommentPrefix
+	if prefix == "" {
+		prefix = "//"
+	}
+	suffix := ""
+	if opts.CommentSuffix != "" {
+		suffix = " " + opts.CommentSuffix
+	}
+
+	if start, end, ok := findLine(content); ok {
+		content = append(append([]byte(nil), content[:start]...), content[end:]...)
+	}
+
+	at := 0
+	if bytes.HasPrefix(content, []byte("#!")) {
+		if nl := bytes.IndexByte(content, '\n'); nl >= 0 {
+			at = nl + 1
+		} else {
+			content = append(content[:len(content):len(content)], '\n')
+			at = len(content)
+		}
+	}
+
+	head := prefix + " " + marker + " gen=" + opts.Generator + " ver=" + opts.Version + " " + digestKey
+	tail := suffix + "\n"
+
+	out := make([]byte, 0, len(content)+len(head)+digestHex+len(tail))
+	out = append(out, content[:at]...)
+	out = append(out, head...)
+	digestAt := len(out)
+	out = append(out, tail...)
+	out = append(out, content[at:]...)
+
+	sum, err := digest(out, digestAt, digestAt)
+	if err != nil {
+		return nil, err
+	}
+	hexSum := make([]byte, digestHex)
+	hex.Encode(hexSum, sum)
+
+	stamped := make([]byte, 0, len(out)+digestHex)
+	stamped = append(stamped, out[:digestAt]...)
+	stamped = append(stamped, hexSum...)
+	stamped = append(stamped, out[digestAt:]...)
+	return stamped, nil
+}
+
+// Verify checks the watermark of content and returns its metadata.
+//
+// It returns ErrNoWatermark if there is none, ErrMalformed if the watermark
+// line is damaged and ErrModified if the file changed after stamping. The
+// metadata is returned along with ErrModified.
+func Verify(content []byte) (Meta, error) {
+	start, end, ok := findLine(content)
+	if !ok {
+		return Meta{}, ErrNoWatermark
+	}
+	line := string(content[start:end])
+
+	fields := strings.Fields(line[strings.Index(line, marker)+len(marker):])
+	if len(fields) < 3 {
+		return Meta{}, ErrMalformed
+	}
+	gen, okGen := strings.CutPrefix(fields[0], "gen=")
+	ver, okVer := strings.CutPrefix(fields[1], "ver=")
+	want, okSum := strings.CutPrefix(fields[2], digestKey)
+	if !okGen || !okVer || !okSum || len(want) != digestHex {
+		return Meta{}, ErrMalformed
+	}
+	meta := Meta{Generator: gen, Version: ver}
+
+	expected, err := hex.DecodeString(want)
+	if err != nil {
+		return Meta{}, ErrMalformed
+	}
+
+	digestAt := start + strings.Index(line, " "+digestKey) + 1 + len(digestKey)
+	sum, err := digest(content, digestAt, digestAt+digestHex)
+	if err != nil {
+		return Meta{}, err
+	}
+	if !bytes.Equal(sum, expected) {
+		return meta, ErrModified
+	}
+	return meta, nil
+}
+
+// digest hashes content without the excluded region [from, to).
+func digest(content []byte, from, to int) ([]byte, error) {
+	body := make([]byte, 0, len(content)-(to-from))
+	body = append(body, content[:from]...)
+	body = append(body, content[to:]...)
+	return tachyon.Hash(body,
+		tachyon.WithDomain(tachyon.DomainFileChecksum),
+		tachyon.WithOutputSize(digestSize))
+}
+
+// findLine locates the first line containing the marker, including its
+// newline.
+func findLine(content []byte) (start, end int, ok bool) {
+	i := bytes.Index(content, []byte(" "+marker+" "))
+	if i < 0 {
+		return 0, 0, false
+	}
+	start = bytes.LastIndexByte(content[:i], '\n') + 1
+	end = len(content)
+	if nl := bytes.IndexByte(content[i:], '\n'); nl >= 0 {
+		end = i + nl + 1
+	}
+	return start, end, true
+}
+
+func validMeta(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n\v\f=")
+}
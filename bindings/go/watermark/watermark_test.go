@@ -0,0 +1,96 @@
+package watermark
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var meta = Meta{Generator: "schemagen", Version: "2.1.0"}
+
+const source = "// Code generated by schemagen. DO NOT EDIT.\n\npackage schema\n\nconst Version = 3\n"
+
+func TestStampVerify(t *testing.T) {
+	out, err := Stamp([]byte(source), Options{Meta: meta})
+	if err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	first, _, _ := strings.Cut(string(out), "\n")
+	sum, ok := strings.CutPrefix(first, "// tachyon-watermark v1 gen=schemagen ver=2.1.0 digest=")
+	if !ok || len(sum) != digestHex {
+		t.Errorf("Watermark line = %q", first)
+	}
+	if !strings.HasSuffix(string(out), source) {
+		t.Error("Stamp should keep the original content")
+	}
+
+	got, err := Verify(out)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if got != meta {
+		t.Errorf("Verify = %+v, want %+v", got, meta)
+	}
+
+	// Restamping is idempotent
+	again, err := Stamp(out, Options{Meta: meta})
+	if err != nil || !bytes.Equal(again, out) {
+		t.Error("Restamping should replace the existing watermark")
+	}
+}
+
+func TestVerifyDetectsEdits(t *testing.T) {
+	out, _ := Stamp([]byte(source), Options{Meta: meta})
+
+	edited := bytes.Replace(out, []byte("Version = 3"), []byte("Version = 4"), 1)
+	if got, err := Verify(edited); !errors.Is(err, ErrModified) || got != meta {
+		t.Errorf("Verify of edited body = %+v, %v, want ErrModified", got, err)
+	}
+
+	// The metadata is covered by the digest too
+	bumped := bytes.Replace(out, []byte("ver=2.1.0"), []byte("ver=2.1.1"), 1)
+	if _, err := Verify(bumped); !errors.Is(err, ErrModified) {
+		t.Errorf("Verify of edited metadata = %v, want ErrModified", err)
+	}
+
+	if _, err := Verify([]byte(source)); !errors.Is(err, ErrNoWatermark) {
+		t.Errorf("Verify of unstamped file = %v, want ErrNoWatermark", err)
+	}
+	truncated := bytes.Replace(out, []byte("digest="), []byte("digest=ab"), 1)
+	if _, err := Verify(truncated); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Verify of damaged watermark = %v, want ErrMalformed", err)
+	}
+}
+
+func TestCommentStyles(t *testing.T) {
+	script := "#!/bin/sh\necho generated\n"
+	out, err := Stamp([]byte(script), Options{Meta: meta, CommentPrefix: "#"})
+	if err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	lines := strings.Split(string(out), "\n")
+	if lines[0] != "#!/bin/sh" || !strings.HasPrefix(lines[1], "# tachyon-watermark v1 ") {
+		t.Errorf("Watermark should follow the shebang:\n%s", out)
+	}
+	if _, err := Verify(out); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	html := "<p>generated</p>\n"
+	out, _ = Stamp([]byte(html), Options{Meta: meta, CommentPrefix: "<!--", CommentSuffix: "-->"})
+	if first, _, _ := strings.Cut(string(out), "\n"); !strings.HasSuffix(first, " -->") {
+		t.Errorf("Block comment not closed: %q", first)
+	}
+	if _, err := Verify(out); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
+
+func TestInvalidMeta(t *testing.T) {
+	for _, m := range []Meta{{}, {Generator: "gen", Version: "1 0"}, {Generator: "a=b", Version: "1"}} {
+		if _, err := Stamp([]byte(source), Options{Meta: m}); err == nil {
+			t.Errorf("Stamp with %+v should fail", m)
+		}
+	}
+}